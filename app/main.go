@@ -1,6 +1,8 @@
 package main
 
 import (
+	"context"
+	"log"
 	"os"
 	"strconv"
 	"time"
@@ -8,51 +10,212 @@ import (
 	"github.com/gofiber/fiber/v2"
 
 	"github.com/fahmiaz411/devcode/config/database"
+	"github.com/fahmiaz411/devcode/config/migration"
 	"github.com/fahmiaz411/devcode/helper/constant"
+	"github.com/fahmiaz411/devcode/helper/message"
+	"github.com/fahmiaz411/devcode/helper/slice"
+	"github.com/fahmiaz411/devcode/helper/web"
 	_activityHandler "github.com/fahmiaz411/devcode/modules/activity/delivery"
+	_activityDomain "github.com/fahmiaz411/devcode/modules/activity/domain"
 	_activityRepo "github.com/fahmiaz411/devcode/modules/activity/repository"
 	_activityUsecase "github.com/fahmiaz411/devcode/modules/activity/usecase"
+	_activityWorker "github.com/fahmiaz411/devcode/modules/activity/worker"
 
 	_todoHandler "github.com/fahmiaz411/devcode/modules/todo/delivery"
+	_todoInterfaces "github.com/fahmiaz411/devcode/modules/todo/interfaces"
+	_todoNotifier "github.com/fahmiaz411/devcode/modules/todo/notifier"
 	_todoRepo "github.com/fahmiaz411/devcode/modules/todo/repository"
 	_todoUsecase "github.com/fahmiaz411/devcode/modules/todo/usecase"
+	_todoWorker "github.com/fahmiaz411/devcode/modules/todo/worker"
+
+	_searchHandler "github.com/fahmiaz411/devcode/modules/search/delivery"
+	_searchRepo "github.com/fahmiaz411/devcode/modules/search/repository"
+	_searchUsecase "github.com/fahmiaz411/devcode/modules/search/usecase"
 )
 
 func main() {
-	app := fiber.New()
+	app := fiber.New(fiber.Config{
+		// Usecase errors become BaseResponse bodies here
+		ErrorHandler: web.ErrorHandler,
+	})
 
 	mysqlPort, _ := strconv.Atoi(os.Getenv("MYSQL_PORT"))
 	if mysqlPort == constant.ZeroValue {
 		mysqlPort = 3306
 	}
 	
-	db := database.NewMysqlDB(database.MysqlConfig{
-		DatabaseName: os.Getenv("MYSQL_DBNAME"),
-		Username: os.Getenv("MYSQL_USER"),
-		Password: os.Getenv("MYSQL_PASSWORD"),
-		Host: os.Getenv("MYSQL_HOST"),
-		Port: mysqlPort,
+	postgresPort, _ := strconv.Atoi(os.Getenv("POSTGRES_PORT"))
+	if postgresPort == constant.ZeroValue {
+		postgresPort = 5432
+	}
+
+	postgresSSLMode := os.Getenv("POSTGRES_SSLMODE")
+	if postgresSSLMode == constant.EmptyString {
+		postgresSSLMode = "disable"
+	}
+
+	sqlitePath := os.Getenv("SQLITE_PATH")
+	if sqlitePath == constant.EmptyString {
+		sqlitePath = "devcode.db"
+	}
+
+	driver := os.Getenv("DB_DRIVER")
+	if driver == constant.EmptyString {
+		driver = database.DriverDefault
+	}
+
+	db := database.NewDatabase(database.Config{
+		Driver: driver,
+		Mysql: database.MysqlConfig{
+			DatabaseName: os.Getenv("MYSQL_DBNAME"),
+			Username: os.Getenv("MYSQL_USER"),
+			Password: os.Getenv("MYSQL_PASSWORD"),
+			Host: os.Getenv("MYSQL_HOST"),
+			Port: mysqlPort,
+		},
+		Postgres: database.PostgresConfig{
+			DatabaseName: os.Getenv("POSTGRES_DBNAME"),
+			Username: os.Getenv("POSTGRES_USER"),
+			Password: os.Getenv("POSTGRES_PASSWORD"),
+			Host: os.Getenv("POSTGRES_HOST"),
+			Port: postgresPort,
+			SSLMode: postgresSSLMode,
+		},
+		Sqlite: database.SqliteConfig{
+			Path: sqlitePath,
+		},
 	})
 
 	// Dev
 
-	// db := database.NewMysqlDB(database.MysqlConfig{
-	// 	DatabaseName: "devcode",
-	// 	Username: "root",
-	// 	Password: "1234",
-	// 	Host: "localhost",
-	// 	Port: 3306,
+	// db := database.NewDatabase(database.Config{
+	// 	Driver: database.DriverMysql,
+	// 	Mysql: database.MysqlConfig{
+	// 		DatabaseName: "devcode",
+	// 		Username: "root",
+	// 		Password: "1234",
+	// 		Host: "localhost",
+	// 		Port: 3306,
+	// 	},
 	// })
 
+	// No database server: DB_DRIVER=memory, or DB_DRIVER=sqlite for a single file database
+
+	// On demand: ./server migrate up | down [steps] | status
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := migrate(db, os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	// On boot, the memory driver has no schema
+	if os.Getenv("MIGRATE_ON_BOOT") == "true" && db.Conn != nil {
+		if err := migrate(db, []string{migration.DirectionUp}); err != nil {
+			log.Fatal(err)
+		}
+	}
+
 	timeout := time.Duration(1 * time.Minute)
 
+	// What deleting an activity group does to its todos
+	activityCascade := os.Getenv("ACTIVITY_DELETE_CASCADE")
+	if activityCascade == constant.EmptyString {
+		activityCascade = _activityDomain.CascadeDefault
+	} else if !slice.Includes(_activityDomain.CascadeAllList, activityCascade) {
+		log.Fatal(message.ShoudMatchEnum("ACTIVITY_DELETE_CASCADE", _activityDomain.CascadeAllList))
+	}
+
+	// Deleting an activity group takes its todos along, through the todo store
+	todoRepo := _todoRepo.NewRepository(db)
+
 	activityRepo := _activityRepo.NewRepository(db)
-	activityUsecase := _activityUsecase.NewUsecase(activityRepo, timeout)
+	activityUsecase := _activityUsecase.NewUsecase(activityRepo, timeout, activityCascade, todoRepo.Store)
 	_activityHandler.NewRESTHandler(app, activityUsecase)
 
-	todoRepo := _todoRepo.NewRepository(db)
-	todoUsecase := _todoUsecase.NewUsecase(todoRepo, timeout)
+	// Deleted activity groups are kept ACTIVITY_RETENTION (e.g. 720h), forever when unset
+	if retention := os.Getenv("ACTIVITY_RETENTION"); retention != constant.EmptyString {
+		activityRetention, err := time.ParseDuration(retention)
+		if err != nil || activityRetention <= 0 {
+			log.Fatal(message.InvalidDuration("ACTIVITY_RETENTION"))
+		}
+
+		retentionInterval := time.Duration(1 * time.Hour)
+		if interval := os.Getenv("ACTIVITY_RETENTION_INTERVAL"); interval != constant.EmptyString {
+			retentionInterval, err = time.ParseDuration(interval)
+			if err != nil || retentionInterval <= 0 {
+				log.Fatal(message.InvalidDuration("ACTIVITY_RETENTION_INTERVAL"))
+			}
+		}
+
+		// Each unit of work purges ACTIVITY_RETENTION_BATCH groups at most
+		retentionBatch, _ := strconv.Atoi(os.Getenv("ACTIVITY_RETENTION_BATCH"))
+		if retentionBatch <= constant.ZeroValue {
+			retentionBatch = 100
+		}
+
+		retentionWorker := _activityWorker.NewRetentionWorker(activityUsecase, activityRetention, retentionInterval,
+			retentionBatch, os.Getenv("ACTIVITY_RETENTION_DRY_RUN") == "true")
+		go retentionWorker.Start(context.Background())
+	}
+
+	// Todos check their activity group through the activity usecase
+	todoUsecase := _todoUsecase.NewUsecase(todoRepo, timeout, activityUsecase)
 	_todoHandler.NewRESTHandler(app, todoUsecase)
 
+	// Reminders go out through SMTP (SMTP_HOST) and a webhook (REMINDER_WEBHOOK_URL), not at all when neither is set
+	notifiers := []_todoInterfaces.Notifier{}
+
+	if smtpHost := os.Getenv("SMTP_HOST"); smtpHost != constant.EmptyString {
+		smtpPort, _ := strconv.Atoi(os.Getenv("SMTP_PORT"))
+		if smtpPort == constant.ZeroValue {
+			smtpPort = 587
+		}
+
+		notifiers = append(notifiers, _todoNotifier.NewSMTPNotifier(smtpHost, smtpPort,
+			os.Getenv("SMTP_USERNAME"), os.Getenv("SMTP_PASSWORD"), os.Getenv("SMTP_FROM"), os.Getenv("REMINDER_EMAIL_TO")))
+	}
+
+	if webhookURL := os.Getenv("REMINDER_WEBHOOK_URL"); webhookURL != constant.EmptyString {
+		notifiers = append(notifiers, _todoNotifier.NewWebhookNotifier(webhookURL, os.Getenv("REMINDER_WEBHOOK_SECRET")))
+	}
+
+	if len(notifiers) != constant.ZeroValue {
+		durations := []struct {
+			key   string
+			value time.Duration
+		}{
+			{"REMINDER_INTERVAL", time.Duration(30 * time.Second)},
+			{"REMINDER_LEASE", time.Duration(5 * time.Minute)},
+		}
+		for i, duration := range durations {
+			if value := os.Getenv(duration.key); value != constant.EmptyString {
+				parsed, err := time.ParseDuration(value)
+				if err != nil || parsed <= 0 {
+					log.Fatal(message.InvalidDuration(duration.key))
+				}
+				durations[i].value = parsed
+			}
+		}
+
+		reminderBatch, _ := strconv.Atoi(os.Getenv("REMINDER_BATCH"))
+		if reminderBatch <= constant.ZeroValue {
+			reminderBatch = 50
+		}
+
+		var notifier _todoInterfaces.Notifier = _todoNotifier.NewNotifiers(notifiers...)
+		if len(notifiers) == 1 {
+			notifier = notifiers[0]
+		}
+
+		reminderWorker := _todoWorker.NewReminderWorker(todoUsecase, notifier, durations[0].value, durations[1].value, reminderBatch)
+		go reminderWorker.Start(context.Background())
+	}
+
+	// Search reads the titles of both modules
+	searchRepo := _searchRepo.NewRepository(db)
+	searchUsecase := _searchUsecase.NewUsecase(searchRepo, timeout)
+	_searchHandler.NewRESTHandler(app, searchUsecase)
+
 	app.Listen(":3030")
 }
\ No newline at end of file
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/fahmiaz411/devcode/config/database"
+	"github.com/fahmiaz411/devcode/config/migration"
+)
+
+func migrate(db *database.Database, args []string) (err error) {
+	ctx := context.Background()
+
+	if len(args) == 0 {
+		return fmt.Errorf("usage: migrate up | down [steps] | status")
+	}
+
+	if db.Conn == nil {
+		return fmt.Errorf("migrate: the %s driver has no schema to migrate", db.Driver)
+	}
+
+	var migrator *migration.Migrator
+	migrator, err = migration.NewMigrator(db.Conn, db.Driver)
+	if err != nil {
+		return
+	}
+
+	switch args[0] {
+	case migration.DirectionUp:
+		var res []migration.Migration
+		res, err = migrator.Up(ctx)
+		for _, m := range res {
+			log.Printf("migrate: applied %d_%s", m.Version, m.Name)
+		}
+		if err == nil && len(res) == 0 {
+			log.Print("migrate: schema is up to date")
+		}
+
+	case migration.DirectionDown:
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps < 1 {
+				return fmt.Errorf("migrate: invalid steps %q", args[1])
+			}
+		}
+
+		var res []migration.Migration
+		res, err = migrator.Down(ctx, steps)
+		for _, m := range res {
+			log.Printf("migrate: reverted %d_%s", m.Version, m.Name)
+		}
+
+	case "status":
+		var res []migration.Status
+		res, err = migrator.Status(ctx)
+		for _, s := range res {
+			appliedAt := "pending"
+			if s.Applied {
+				appliedAt = s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			switch s.Partial {
+			case migration.DirectionUp:
+				appliedAt = "partly applied, run up to finish"
+			case migration.DirectionDown:
+				appliedAt = "partly reverted, run down to finish"
+			}
+			log.Printf("migrate: %d_%s %s", s.Version, s.Name, appliedAt)
+		}
+
+	default:
+		return fmt.Errorf("migrate: unknown command %q", args[0])
+	}
+
+	return
+}
@@ -0,0 +1,57 @@
+package database
+
+import (
+	"database/sql"
+	"log"
+	"strings"
+)
+
+const (
+	DriverMemory = "memory"
+
+	DriverDefault = DriverMysql
+)
+
+var (
+	DriverAllList = []string{
+		DriverMysql,
+		DriverMemory,
+		DriverSqlite,
+		DriverPostgres,
+	}
+)
+
+type Config struct {
+	Driver   string
+	Mysql    MysqlConfig
+	Sqlite   SqliteConfig
+	Postgres PostgresConfig
+}
+
+// Database is the storage the repositories are built on, Conn for SQL drivers and Memory for the memory driver
+type Database struct {
+	Driver string
+	Conn   *sql.DB
+	Memory *MemoryDB
+}
+
+func NewDatabase(config Config) *Database {
+	db := &Database{
+		Driver: config.Driver,
+	}
+
+	switch config.Driver {
+	case DriverMysql:
+		db.Conn = NewMysqlDB(config.Mysql)
+	case DriverSqlite:
+		db.Conn = NewSqliteDB(config.Sqlite)
+	case DriverPostgres:
+		db.Conn = NewPostgresDB(config.Postgres)
+	case DriverMemory:
+		db.Memory = NewMemoryDB()
+	default:
+		log.Fatalf("database: unknown driver %q, use one of: %s", config.Driver, strings.Join(DriverAllList, ", "))
+	}
+
+	return db
+}
@@ -0,0 +1,194 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+	"github.com/mattn/go-sqlite3"
+)
+
+// Dialect is the SQL driver a repository talks to. The SQL repositories are written once, with ? placeholders
+// and portable SQL, and ask the dialect for the few pieces the drivers disagree on.
+type Dialect string
+
+// Conn returns the transaction ctx runs in, or conn outside of a unit of work, taking ? placeholders on every driver
+func (d Dialect) Conn(ctx context.Context, conn *sql.DB) Executor {
+	executor := Conn(ctx, conn)
+
+	if d == DriverPostgres {
+		return rebinder{executor}
+	}
+
+	return executor
+}
+
+// Insert runs an INSERT of a row and returns its ID, key is the column of the ID.
+// Postgres returns it, MySQL and SQLite report it as the last insert ID of the connection.
+func (d Dialect) Insert(ctx context.Context, conn Executor, query string, key string, args ...any) (id int64, err error) {
+	if d == DriverPostgres {
+		err = conn.QueryRowContext(ctx, query+" RETURNING "+key, args...).Scan(&id)
+		return
+	}
+
+	var result sql.Result
+	result, err = conn.ExecContext(ctx, query, args...)
+	if err != nil {
+		return
+	}
+
+	return result.LastInsertId()
+}
+
+// Day is the UTC date of a time column, YYYY-MM-DD
+func (d Dialect) Day(column string) string {
+	switch d {
+	case DriverSqlite:
+		return fmt.Sprintf("DATE(%s)", column)
+	case DriverPostgres:
+		return fmt.Sprintf("TO_CHAR(%s, 'YYYY-MM-DD')", column)
+	}
+
+	return fmt.Sprintf("DATE_FORMAT(%s, '%%Y-%%m-%%d')", column)
+}
+
+// Seconds is the time from one time column to another, in seconds
+func (d Dialect) Seconds(from, to string) string {
+	switch d {
+	case DriverSqlite:
+		return fmt.Sprintf("(JULIANDAY(%s) - JULIANDAY(%s)) * 86400", to, from)
+	case DriverPostgres:
+		return fmt.Sprintf("EXTRACT(EPOCH FROM (%s - %s))", to, from)
+	}
+
+	return fmt.Sprintf("TIMESTAMPDIFF(SECOND, %s, %s)", from, to)
+}
+
+// Like is the case insensitive match of column against a pattern made by Contains
+func (d Dialect) Like(column string) string {
+	switch d {
+	case DriverSqlite:
+		return column + ` LIKE ? ESCAPE '\'`
+	case DriverPostgres:
+		return column + ` ILIKE ? ESCAPE '\'`
+	}
+
+	// The backslash is the escape of MySQL already, and would escape the quote of a literal
+	return column + " LIKE ?"
+}
+
+// Binary is a text column compared byte by byte, the order of UTF-8 text every driver and Go agree on,
+// where the default collations differ in case and accents
+func (d Dialect) Binary(column string) string {
+	switch d {
+	case DriverSqlite:
+		return column
+	case DriverPostgres:
+		return column + ` COLLATE "C"`
+	}
+
+	return column + " COLLATE utf8mb4_bin"
+}
+
+// Never is a time after any other, for the rows without one to sort last
+func (d Dialect) Never() string {
+	switch d {
+	case DriverSqlite:
+		return "'9999-12-31 00:00:00+00:00'"
+	case DriverPostgres:
+		return "TIMESTAMP '9999-12-31 00:00:00'"
+	}
+
+	return "CAST('9999-12-31 00:00:00' AS DATETIME)"
+}
+
+// Lock is the clause a SELECT ends with to lock its rows until the transaction ends, exclusive or shared.
+// SQLite has no row locks, its transactions take turns writing.
+func (d Dialect) Lock(exclusive bool) string {
+	switch {
+	case d == DriverSqlite:
+		return ""
+	case exclusive:
+		return " FOR UPDATE"
+	case d == DriverPostgres:
+		return " FOR SHARE"
+	}
+
+	// FOR SHARE is MySQL 8 only
+	return " LOCK IN SHARE MODE"
+}
+
+// Duplicate tells whether err is a write refused by a unique key, a row another transaction wrote first
+func (d Dialect) Duplicate(err error) bool {
+	switch d {
+	case DriverSqlite:
+		var e sqlite3.Error
+		return errors.As(err, &e) && (e.ExtendedCode == sqlite3.ErrConstraintUnique || e.ExtendedCode == sqlite3.ErrConstraintPrimaryKey)
+	case DriverPostgres:
+		var e *pq.Error
+		return errors.As(err, &e) && e.Code == "23505"
+	}
+
+	var e *mysql.MySQLError
+	return errors.As(err, &e) && e.Number == 1062
+}
+
+// Contains is the LIKE pattern matching value anywhere, with wildcards in value escaped
+func Contains(value string) string {
+	return "%" + strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value) + "%"
+}
+
+// NullTime scans a nullable time from any driver, SQLite returns computed times such as MAX(updated_at) as text
+type NullTime struct {
+	sql.NullTime
+}
+
+func (t *NullTime) Scan(value any) (err error) {
+	switch text := value.(type) {
+	case []byte:
+		return t.parse(string(text))
+	case string:
+		return t.parse(text)
+	}
+
+	return t.NullTime.Scan(value)
+}
+
+// parse reads the text the way the SQLite driver does for DATETIME columns
+func (t *NullTime) parse(text string) (err error) {
+	text = strings.TrimSuffix(text, "Z")
+	for _, format := range sqlite3.SQLiteTimestampFormats {
+		if t.Time, err = time.ParseInLocation(format, text, time.UTC); err == nil {
+			t.Valid = true
+			return
+		}
+	}
+
+	return
+}
+
+// rebinder runs statements written with ? placeholders on Postgres
+type rebinder struct {
+	Executor
+}
+
+func (r rebinder) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return r.Executor.PrepareContext(ctx, Rebind(query))
+}
+
+func (r rebinder) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return r.Executor.ExecContext(ctx, Rebind(query), args...)
+}
+
+func (r rebinder) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return r.Executor.QueryContext(ctx, Rebind(query), args...)
+}
+
+func (r rebinder) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	return r.Executor.QueryRowContext(ctx, Rebind(query), args...)
+}
@@ -0,0 +1,57 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+)
+
+func TestDuplicate(t *testing.T) {
+	ctx := context.Background()
+	db := NewSqliteDB(SqliteConfig{Path: ":memory:"})
+	defer db.Close()
+
+	if _, err := db.ExecContext(ctx, "CREATE TABLE tags (tag_id INTEGER PRIMARY KEY, name TEXT NOT NULL UNIQUE)"); err != nil {
+		t.Fatal(err)
+	}
+
+	// sqlite inserts a row under a name, the error of a taken name or ID is the one SQLite returns
+	sqlite := func(id int, name string) error {
+		_, err := db.ExecContext(ctx, "INSERT INTO tags (tag_id, name) VALUES (?, ?)", id, name)
+		return err
+	}
+
+	if err := sqlite(1, "work"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		dialect Dialect
+		err     error
+		want    bool
+	}{
+		{name: "sqlite, unique", dialect: DriverSqlite, err: sqlite(2, "work"), want: true},
+		{name: "sqlite, primary key", dialect: DriverSqlite, err: sqlite(1, "home"), want: true},
+		{name: "sqlite, not null", dialect: DriverSqlite, err: sqlite(3, "")},
+		{name: "mysql, wrapped", dialect: DriverMysql, err: fmt.Errorf("tag: %w", &mysql.MySQLError{Number: 1062}), want: true},
+		{name: "mysql, other", dialect: DriverMysql, err: &mysql.MySQLError{Number: 1452}},
+		{name: "postgres", dialect: DriverPostgres, err: &pq.Error{Code: "23505"}, want: true},
+		{name: "postgres, other", dialect: DriverPostgres, err: &pq.Error{Code: "23503"}},
+		{name: "another driver", dialect: DriverMysql, err: &pq.Error{Code: "23505"}},
+		{name: "no error", dialect: DriverSqlite},
+		{name: "plain error", dialect: DriverMysql, err: errors.New("duplicate")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.dialect.Duplicate(tt.err); got != tt.want {
+				t.Errorf("Duplicate(%v) = %t, want %t", tt.err, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package database
+
+import (
+	"context"
+	"sync"
+)
+
+// MemoryDB is the process local counterpart of *sql.DB.
+// Every table lives behind the same lock, so repositories of different modules can change related rows together.
+type MemoryDB struct {
+	sync.RWMutex
+
+	tablesMu sync.Mutex
+	tables   map[string]*MemoryTable
+
+	// journal undoes the writes of the running unit of work, nil outside of one
+	journal *journal
+}
+
+// MemoryTable holds rows by ID. Rows are read from Rows and written through Set and Delete,
+// so a unit of work can undo them.
+type MemoryTable struct {
+	LastID int64
+	Rows   map[int64]any
+
+	db *MemoryDB
+}
+
+// journal records the row a write replaced, and the LastID of a table before its first new ID
+type journal struct {
+	undo    []undo
+	lastIDs map[*MemoryTable]int64
+}
+
+type undo struct {
+	table   *MemoryTable
+	id      int64
+	row     any
+	existed bool
+}
+
+func NewMemoryDB() *MemoryDB {
+	return &MemoryDB{
+		tables: map[string]*MemoryTable{},
+	}
+}
+
+// Table returns the named table, creating it on first use.
+// The caller must hold the read lock to read its rows and the write lock to change them, see Read and Write.
+func (db *MemoryDB) Table(name string) *MemoryTable {
+	db.tablesMu.Lock()
+	defer db.tablesMu.Unlock()
+
+	table, ok := db.tables[name]
+	if !ok {
+		table = &MemoryTable{
+			Rows: map[int64]any{},
+			db:   db,
+		}
+		db.tables[name] = table
+	}
+
+	return table
+}
+
+// NextID works like AUTO_INCREMENT, a rolled back unit of work hands its IDs out again
+func (t *MemoryTable) NextID() int64 {
+	if j := t.db.journal; j != nil {
+		if _, ok := j.lastIDs[t]; !ok {
+			j.lastIDs[t] = t.LastID
+		}
+	}
+
+	t.LastID++
+	return t.LastID
+}
+
+// Set writes the row with the given ID
+func (t *MemoryTable) Set(id int64, row any) {
+	t.record(id)
+	t.Rows[id] = row
+}
+
+// Delete removes the row with the given ID, if any
+func (t *MemoryTable) Delete(id int64) {
+	if _, ok := t.Rows[id]; !ok {
+		return
+	}
+
+	t.record(id)
+	delete(t.Rows, id)
+}
+
+// record keeps the row a write is about to replace within a unit of work
+func (t *MemoryTable) record(id int64) {
+	if j := t.db.journal; j != nil {
+		row, existed := t.Rows[id]
+		j.undo = append(j.undo, undo{table: t, id: id, row: row, existed: existed})
+	}
+}
+
+// Read read locks the database and returns the unlock,
+// within a unit of work the transaction holds the lock already
+func (db *MemoryDB) Read(ctx context.Context) (unlock func()) {
+	if db.inTransaction(ctx) {
+		return func() {}
+	}
+
+	db.RLock()
+	return db.RUnlock
+}
+
+// Write locks the database and returns the unlock,
+// within a unit of work the transaction holds the lock already
+func (db *MemoryDB) Write(ctx context.Context) (unlock func()) {
+	if db.inTransaction(ctx) {
+		return func() {}
+	}
+
+	db.Lock()
+	return db.Unlock
+}
+
+func (db *MemoryDB) inTransaction(ctx context.Context) bool {
+	tx, ok := ctx.Value(txKey{}).(*MemoryDB)
+	return ok && tx == db
+}
+
+// rollback undoes the writes of the journal, the latest first
+func (j *journal) rollback() {
+	for i := len(j.undo) - 1; i >= 0; i-- {
+		u := j.undo[i]
+		if u.existed {
+			u.table.Rows[u.id] = u.row
+		} else {
+			delete(u.table.Rows, u.id)
+		}
+	}
+
+	for table, lastID := range j.lastIDs {
+		table.LastID = lastID
+	}
+}
@@ -0,0 +1,74 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestMemoryTransactorRollback(t *testing.T) {
+	db := NewMemoryDB()
+	transactor := NewTransactor(&Database{Driver: DriverMemory, Memory: db})
+
+	rows := db.Table("rows")
+	rows.Set(rows.NextID(), "a")
+	rows.Set(rows.NextID(), "b")
+
+	failed := errors.New("failed")
+	err := transactor.WithinTransaction(context.Background(), func(ctx context.Context) error {
+		rows := db.Table("rows")
+		rows.Set(1, "a2")
+		rows.Set(1, "a3")
+		rows.Delete(2)
+		rows.Set(rows.NextID(), "c")
+
+		db.Table("other").Set(1, "x")
+
+		return failed
+	})
+	if !errors.Is(err, failed) {
+		t.Fatalf("WithinTransaction() = %v, want %v", err, failed)
+	}
+
+	if want := map[int64]any{1: "a", 2: "b"}; !reflect.DeepEqual(rows.Rows, want) {
+		t.Errorf("rows = %v, want %v", rows.Rows, want)
+	}
+
+	if len(db.Table("other").Rows) != 0 {
+		t.Errorf("other = %v, want none", db.Table("other").Rows)
+	}
+
+	if id := rows.NextID(); id != 3 {
+		t.Errorf("NextID() = %d, want 3", id)
+	}
+}
+
+func TestMemoryTransactorCommit(t *testing.T) {
+	db := NewMemoryDB()
+	transactor := NewTransactor(&Database{Driver: DriverMemory, Memory: db})
+
+	err := transactor.WithinTransaction(context.Background(), func(ctx context.Context) error {
+		rows := db.Table("rows")
+		rows.Set(rows.NextID(), "a")
+
+		// A nested unit of work is part of the outer one
+		return transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+			rows.Set(rows.NextID(), "b")
+			return nil
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := map[int64]any{1: "a", 2: "b"}; !reflect.DeepEqual(db.Table("rows").Rows, want) {
+		t.Errorf("rows = %v, want %v", db.Table("rows").Rows, want)
+	}
+
+	// Outside of a unit of work nothing is journaled
+	db.Table("rows").Set(3, "c")
+	if db.journal != nil {
+		t.Error("journal left behind")
+	}
+}
@@ -8,6 +8,10 @@ import (
 	_ "github.com/go-sql-driver/mysql"
 )
 
+const (
+	DriverMysql = "mysql"
+)
+
 type MysqlConfig struct {
 	DatabaseName string
 	Username string
@@ -18,7 +22,7 @@ type MysqlConfig struct {
 
 func NewMysqlDB(config MysqlConfig) *sql.DB {
 	connection := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?parseTime=true", config.Username, config.Password, config.Host, config.Port, config.DatabaseName)
-	db, err := sql.Open("mysql", connection)
+	db, err := sql.Open(DriverMysql, connection)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -0,0 +1,79 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	_ "github.com/lib/pq"
+)
+
+const (
+	DriverPostgres = "postgres"
+)
+
+type PostgresConfig struct {
+	DatabaseName string
+	Username     string
+	Password     string
+	Host         string
+	Port         int
+	SSLMode      string
+}
+
+func NewPostgresDB(config PostgresConfig) *sql.DB {
+	connection := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s", config.Host, config.Port, config.Username, config.Password, config.DatabaseName, config.SSLMode)
+	db, err := sql.Open(DriverPostgres, connection)
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = db.Ping()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return db
+}
+
+// Rebind turns the ? placeholders of query into the $n placeholders of Postgres. A ? in a quoted literal,
+// a quoted identifier or a comment is text and stays as it is.
+func Rebind(query string) string {
+	var b strings.Builder
+
+	n := 0
+	// end closes the quoted section or comment the query is in, none outside
+	end := ""
+	for i := 0; i < len(query); i++ {
+		rest := query[i:]
+
+		switch {
+		case end != "":
+			if strings.HasPrefix(rest, end) {
+				b.WriteString(end)
+				i += len(end) - 1
+				end = ""
+				continue
+			}
+		case rest[0] == '\'' || rest[0] == '"':
+			// A doubled quote inside is closed and opened again, the text in between stays as it is
+			end = rest[:1]
+		case strings.HasPrefix(rest, "--"):
+			end = "\n"
+		case strings.HasPrefix(rest, "/*"):
+			b.WriteString("/*")
+			i++
+			end = "*/"
+			continue
+		case rest[0] == '?':
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+
+		b.WriteByte(query[i])
+	}
+
+	return b.String()
+}
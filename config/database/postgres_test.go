@@ -0,0 +1,64 @@
+package database
+
+import (
+	"context"
+	"testing"
+)
+
+func TestRebind(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{name: "placeholders", query: "UPDATE todos SET title = ? WHERE todo_id = ? AND version = ?", want: "UPDATE todos SET title = $1 WHERE todo_id = $2 AND version = $3"},
+		{name: "literal", query: "SELECT ? WHERE title = 'why?' AND id = ?", want: "SELECT $1 WHERE title = 'why?' AND id = $2"},
+		{name: "doubled quote", query: "SELECT 'it''s ?', ?", want: "SELECT 'it''s ?', $1"},
+		{name: "escape of a like", query: `WHERE title ILIKE ? ESCAPE '\' AND id = ?`, want: `WHERE title ILIKE $1 ESCAPE '\' AND id = $2`},
+		{name: "identifier", query: `SELECT "what?" FROM t WHERE id = ?`, want: `SELECT "what?" FROM t WHERE id = $1`},
+		{name: "line comment", query: "SELECT ? -- any?\nFROM t WHERE id = ?", want: "SELECT $1 -- any?\nFROM t WHERE id = $2"},
+		{name: "block comment", query: "SELECT /* any? */ ? /*/ ? */ FROM t", want: "SELECT /* any? */ $1 /*/ ? */ FROM t"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Rebind(tt.query); got != tt.want {
+				t.Errorf("Rebind() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// SQLite takes the $n placeholders and the RETURNING clause of Postgres, the statements of the Postgres dialect run on it
+func TestPostgresDialect(t *testing.T) {
+	ctx := context.Background()
+	db := NewSqliteDB(SqliteConfig{Path: ":memory:"})
+	defer db.Close()
+
+	if _, err := db.ExecContext(ctx, "CREATE TABLE todos (todo_id INTEGER PRIMARY KEY AUTOINCREMENT, title TEXT NOT NULL)"); err != nil {
+		t.Fatal(err)
+	}
+
+	dialect := Dialect(DriverPostgres)
+	conn := dialect.Conn(ctx, db)
+	for i, title := range []string{"why?", "50%_off", "a"} {
+		id, err := dialect.Insert(ctx, conn, "INSERT INTO todos (title) VALUES (?)", "todo_id", title)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if id != int64(i+1) {
+			t.Errorf("Insert() = %d, want %d", id, i+1)
+		}
+	}
+
+	var title string
+	err := conn.QueryRowContext(ctx, `SELECT title FROM todos WHERE title LIKE ? ESCAPE '\' AND title <> 'not?' AND todo_id > ?`, Contains("%_"), 0).Scan(&title)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if title != "50%_off" {
+		t.Errorf("title = %q, want 50%%_off", title)
+	}
+}
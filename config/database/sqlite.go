@@ -0,0 +1,35 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+const (
+	DriverSqlite = "sqlite"
+)
+
+type SqliteConfig struct {
+	Path string
+}
+
+func NewSqliteDB(config SqliteConfig) *sql.DB {
+	connection := fmt.Sprintf("file:%s?_foreign_keys=on&_busy_timeout=5000&_journal_mode=WAL", config.Path)
+	db, err := sql.Open("sqlite3", connection)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// SQLite allows one writer at a time, a single connection also keeps ":memory:" databases shared
+	db.SetMaxOpenConns(1)
+
+	err = db.Ping()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return db
+}
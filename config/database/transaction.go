@@ -0,0 +1,109 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+)
+
+type txKey struct{}
+
+// Transactor runs a unit of work. The repositories of every module join it through the ctx given to fn,
+// it commits when fn returns nil and rolls back otherwise.
+type Transactor interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// Executor is what SQL repositories run statements on, the *sql.Tx of the unit of work or the *sql.DB
+type Executor interface {
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// NewTransactor constructor, the unit of work follows the database driver
+func NewTransactor(db *Database) Transactor {
+	if db.Driver == DriverMemory {
+		return &memoryTransactor{DB: db.Memory}
+	}
+
+	return &sqlTransactor{Conn: db.Conn}
+}
+
+// Conn returns the transaction ctx runs in, or conn outside of a unit of work
+func Conn(ctx context.Context, conn *sql.DB) Executor {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return tx
+	}
+
+	return conn
+}
+
+type sqlTransactor struct {
+	Conn *sql.DB
+}
+
+func (t *sqlTransactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	// A nested unit of work is part of the outer one
+	if _, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return fn(ctx)
+	}
+
+	var tx *sql.Tx
+	tx, err = t.Conn.BeginTx(ctx, nil)
+	if err != nil {
+		return
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+
+		err = tx.Commit()
+	}()
+
+	err = fn(context.WithValue(ctx, txKey{}, tx))
+
+	return
+}
+
+// memoryTransactor holds the write lock for the whole unit of work and undoes its writes on rollback
+type memoryTransactor struct {
+	DB *MemoryDB
+}
+
+func (t *memoryTransactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	if t.DB.inTransaction(ctx) {
+		return fn(ctx)
+	}
+
+	t.DB.Lock()
+	defer t.DB.Unlock()
+
+	j := &journal{lastIDs: map[*MemoryTable]int64{}}
+	t.DB.journal = j
+
+	defer func() {
+		t.DB.journal = nil
+
+		if p := recover(); p != nil {
+			j.rollback()
+			panic(p)
+		}
+
+		if err != nil {
+			j.rollback()
+		}
+	}()
+
+	err = fn(context.WithValue(ctx, txKey{}, t.DB))
+
+	return
+}
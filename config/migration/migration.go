@@ -0,0 +1,401 @@
+package migration
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fahmiaz411/devcode/config/database"
+)
+
+const (
+	DirectionUp   = "up"
+	DirectionDown = "down"
+)
+
+//go:embed sql
+var files embed.FS
+
+// fileName matches "<version>_<name>.<direction>.sql", e.g. 000001_create_activities_table.up.sql
+var fileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+const createTable = `
+	CREATE TABLE IF NOT EXISTS schema_migrations (
+		version BIGINT NOT NULL,
+		name VARCHAR(255) NOT NULL,
+		checksum CHAR(64) NOT NULL,
+		applied_at DATETIME NOT NULL,
+		direction VARCHAR(4) NOT NULL,
+		statements INT NOT NULL,
+		PRIMARY KEY (version)
+	)
+`
+
+var createTableQuery = map[string]string{
+	database.DriverMysql:    createTable,
+	database.DriverSqlite:   createTable,
+	database.DriverPostgres: strings.Replace(createTable, "DATETIME", "TIMESTAMP", 1),
+}
+
+type Migration struct {
+	Version  int64
+	Name     string
+	Up       string
+	Down     string
+	Checksum string
+}
+
+type Status struct {
+	Migration
+	Applied   bool
+	AppliedAt *time.Time
+
+	// Partial is the direction the migration stopped halfway in, empty when it did not
+	Partial string
+}
+
+// applied is a row of schema_migrations. Direction and Statements tell how far the last run of the migration got,
+// a migration is applied once every statement of its up script ran and is removed once every statement of its down script did.
+type applied struct {
+	Version    int64
+	Name       string
+	Checksum   string
+	AppliedAt  time.Time
+	Direction  string
+	Statements int
+}
+
+type Migrator struct {
+	Conn       *sql.DB
+	Dialect    string
+	Migrations []Migration
+}
+
+// NewMigrator loads the embedded migrations written for dialect
+func NewMigrator(Conn *sql.DB, dialect string) (*Migrator, error) {
+	if _, ok := createTableQuery[dialect]; !ok {
+		return nil, fmt.Errorf("migration: unsupported dialect %q", dialect)
+	}
+
+	migrations, err := load(dialect)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{
+		Conn:       Conn,
+		Dialect:    dialect,
+		Migrations: migrations,
+	}, nil
+}
+
+func load(dialect string) (migrations []Migration, err error) {
+	dir := path.Join("sql", dialect)
+
+	var entries []fs.DirEntry
+	entries, err = files.ReadDir(dir)
+	if err != nil {
+		return
+	}
+
+	byVersion := map[int64]*Migration{}
+	for _, entry := range entries {
+		match := fileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("migration: invalid file name %s", entry.Name())
+		}
+
+		var version int64
+		version, err = strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return
+		}
+
+		var content []byte
+		content, err = files.ReadFile(path.Join(dir, entry.Name()))
+		if err != nil {
+			return
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		} else if m.Name != match[2] {
+			return nil, fmt.Errorf("migration: version %d used by %s and %s", version, m.Name, match[2])
+		}
+
+		if match[3] == DirectionUp {
+			sum := sha256.Sum256(content)
+			m.Up = string(content)
+			m.Checksum = hex.EncodeToString(sum[:])
+		} else {
+			m.Down = string(content)
+		}
+	}
+
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migration: version %d has no up script", m.Version)
+		}
+		migrations = append(migrations, *m)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return
+}
+
+// Up applies every pending migration in version order and returns the ones it applied
+func (m *Migrator) Up(ctx context.Context) (res []Migration, err error) {
+	var done map[int64]applied
+	done, err = m.verify(ctx)
+	if err != nil {
+		return
+	}
+
+	for _, migration := range m.Migrations {
+		a, ok := done[migration.Version]
+		if ok && m.complete(a, migration) {
+			continue
+		}
+
+		if ok && a.Direction == DirectionDown {
+			return res, fmt.Errorf("migration: version %d was partly reverted, run down to finish reverting it", migration.Version)
+		}
+
+		// A migration that stopped halfway resumes after its last statement that ran
+		if err = m.run(ctx, migration, DirectionUp, a.Statements, ok); err != nil {
+			return
+		}
+		res = append(res, migration)
+	}
+
+	return
+}
+
+// Down reverts the latest steps applied migrations and returns the ones it reverted
+func (m *Migrator) Down(ctx context.Context, steps int) (res []Migration, err error) {
+	var done map[int64]applied
+	done, err = m.verify(ctx)
+	if err != nil {
+		return
+	}
+
+	for i := len(m.Migrations) - 1; i >= 0 && len(res) < steps; i-- {
+		migration := m.Migrations[i]
+		a, ok := done[migration.Version]
+		if !ok {
+			continue
+		}
+
+		if migration.Down == "" {
+			return res, fmt.Errorf("migration: version %d has no down script", migration.Version)
+		}
+
+		if a.Direction == DirectionUp && !m.complete(a, migration) {
+			return res, fmt.Errorf("migration: version %d was partly applied, run up to finish applying it", migration.Version)
+		}
+
+		from := 0
+		if a.Direction == DirectionDown {
+			from = a.Statements
+		}
+
+		if err = m.run(ctx, migration, DirectionDown, from, true); err != nil {
+			return
+		}
+		res = append(res, migration)
+	}
+
+	return
+}
+
+// Status lists every known migration and whether it has been applied
+func (m *Migrator) Status(ctx context.Context) (res []Status, err error) {
+	var done map[int64]applied
+	done, err = m.applied(ctx)
+	if err != nil {
+		return
+	}
+
+	for _, migration := range m.Migrations {
+		status := Status{Migration: migration}
+		if a, ok := done[migration.Version]; ok && m.complete(a, migration) {
+			status.Applied = true
+			status.AppliedAt = &a.AppliedAt
+		} else if ok {
+			status.Partial = a.Direction
+		}
+		res = append(res, status)
+	}
+
+	return
+}
+
+// complete tells whether every statement of the up script of the migration ran
+func (m *Migrator) complete(a applied, migration Migration) bool {
+	return a.Direction == DirectionUp && a.Statements >= len(statements(m.Dialect, migration.Up))
+}
+
+// verify makes sure every applied migration still exists and was not edited afterwards
+func (m *Migrator) verify(ctx context.Context) (done map[int64]applied, err error) {
+	done, err = m.applied(ctx)
+	if err != nil {
+		return
+	}
+
+	known := map[int64]Migration{}
+	for _, migration := range m.Migrations {
+		known[migration.Version] = migration
+	}
+
+	for version, a := range done {
+		migration, ok := known[version]
+		if !ok {
+			return nil, fmt.Errorf("migration: version %d (%s) is applied but missing from this build", version, a.Name)
+		}
+		if migration.Checksum != a.Checksum {
+			return nil, fmt.Errorf("migration: checksum mismatch for version %d (%s), applied scripts must not be edited", version, a.Name)
+		}
+	}
+
+	return
+}
+
+func (m *Migrator) applied(ctx context.Context) (res map[int64]applied, err error) {
+	if _, err = m.Conn.ExecContext(ctx, createTableQuery[m.Dialect]); err != nil {
+		return
+	}
+
+	var rows *sql.Rows
+	rows, err = m.Conn.QueryContext(ctx, `
+		SELECT
+			version,
+			name,
+			checksum,
+			applied_at,
+			direction,
+			statements
+		FROM schema_migrations
+	`)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	res = map[int64]applied{}
+	for rows.Next() {
+		var a applied
+		if err = rows.Scan(&a.Version, &a.Name, &a.Checksum, &a.AppliedAt, &a.Direction, &a.Statements); err != nil {
+			return
+		}
+		res[a.Version] = a
+	}
+	err = rows.Err()
+
+	return
+}
+
+// run runs the statements of a script from the given one on, exists tells whether schema_migrations has a row for the migration.
+// MySQL commits every DDL statement on its own, so each statement runs by itself and the progress is recorded after it,
+// a migration failing halfway resumes where it stopped. The other dialects run the whole script in one transaction.
+// Only a crash between a MySQL statement and its record runs the statement twice.
+func (m *Migrator) run(ctx context.Context, migration Migration, direction string, from int, exists bool) (err error) {
+	script := migration.Up
+	if direction == DirectionDown {
+		script = migration.Down
+	}
+
+	list := statements(m.Dialect, script)
+
+	var conn database.Executor = m.Conn
+	if m.Dialect != database.DriverMysql {
+		var tx *sql.Tx
+		tx, err = m.Conn.BeginTx(ctx, nil)
+		if err != nil {
+			return
+		}
+		defer func() {
+			if err != nil {
+				tx.Rollback()
+				return
+			}
+			err = tx.Commit()
+		}()
+
+		conn = tx
+	}
+
+	for i := from; i < len(list); i++ {
+		if _, err = conn.ExecContext(ctx, list[i]); err != nil {
+			return fmt.Errorf("migration: %d_%s.%s statement %d: %w", migration.Version, migration.Name, direction, i+1, err)
+		}
+
+		if err = m.record(ctx, conn, migration, direction, i+1, len(list), exists); err != nil {
+			return
+		}
+		exists = true
+	}
+
+	if from >= len(list) {
+		err = m.record(ctx, conn, migration, direction, len(list), len(list), exists)
+	}
+
+	return
+}
+
+// record keeps how many statements of the script of direction ran, the row goes once the down script ran in full
+func (m *Migrator) record(ctx context.Context, conn database.Executor, migration Migration, direction string, done, total int, exists bool) (err error) {
+	switch {
+	case direction == DirectionDown && done == total:
+		_, err = conn.ExecContext(ctx, m.bind(`
+			DELETE FROM schema_migrations WHERE version = ?
+		`), migration.Version)
+	case exists:
+		_, err = conn.ExecContext(ctx, m.bind(`
+			UPDATE schema_migrations SET applied_at = ?, direction = ?, statements = ? WHERE version = ?
+		`), time.Now().UTC(), direction, done, migration.Version)
+	default:
+		_, err = conn.ExecContext(ctx, m.bind(`
+			INSERT INTO schema_migrations (
+				version,
+				name,
+				checksum,
+				applied_at,
+				direction,
+				statements
+			) VALUES (
+				?,
+				?,
+				?,
+				?,
+				?,
+				?
+			)
+		`), migration.Version, migration.Name, migration.Checksum, time.Now().UTC(), direction, done)
+	}
+
+	return
+}
+
+// bind adapts the placeholders of query to the dialect
+func (m *Migrator) bind(query string) string {
+	if m.Dialect == database.DriverPostgres {
+		return database.Rebind(query)
+	}
+
+	return query
+}
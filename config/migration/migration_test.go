@@ -0,0 +1,268 @@
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/fahmiaz411/devcode/config/database"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func open(t *testing.T) *sql.DB {
+	t.Helper()
+
+	db, err := sql.Open("sqlite3", "file::memory:?_foreign_keys=on")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Every connection to ":memory:" is a database of its own
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	return db
+}
+
+func versions(migrations []Migration) (res []int64) {
+	for _, m := range migrations {
+		res = append(res, m.Version)
+	}
+
+	return
+}
+
+func TestLoad(t *testing.T) {
+	for _, dialect := range []string{database.DriverMysql, database.DriverSqlite, database.DriverPostgres} {
+		t.Run(dialect, func(t *testing.T) {
+			migrations, err := load(dialect)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(migrations) == 0 {
+				t.Fatal("no migrations loaded")
+			}
+
+			for i, m := range migrations {
+				if i > 0 && m.Version <= migrations[i-1].Version {
+					t.Errorf("version %d follows %d", m.Version, migrations[i-1].Version)
+				}
+
+				if m.Name == "" || m.Up == "" || m.Down == "" {
+					t.Errorf("version %d is incomplete: %+v", m.Version, m)
+				}
+
+				if len(m.Checksum) != 64 {
+					t.Errorf("version %d has checksum %q", m.Version, m.Checksum)
+				}
+			}
+		})
+	}
+}
+
+func TestNewMigratorUnknownDialect(t *testing.T) {
+	if _, err := NewMigrator(nil, "oracle"); err == nil {
+		t.Error("expected an error for an unknown dialect")
+	}
+}
+
+func TestUpDown(t *testing.T) {
+	ctx := context.Background()
+
+	migrator, err := NewMigrator(open(t), database.DriverSqlite)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	all := versions(migrator.Migrations)
+
+	res, err := migrator.Up(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := versions(res); !reflect.DeepEqual(got, all) {
+		t.Fatalf("Up applied %v, want %v", got, all)
+	}
+
+	// Up to date
+	if res, err = migrator.Up(ctx); err != nil || len(res) != 0 {
+		t.Fatalf("second Up applied %v, %v", versions(res), err)
+	}
+
+	// The latest first
+	if res, err = migrator.Down(ctx, 2); err != nil {
+		t.Fatal(err)
+	}
+	want := []int64{all[len(all)-1], all[len(all)-2]}
+	if got := versions(res); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Down reverted %v, want %v", got, want)
+	}
+
+	status, err := migrator.Status(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, s := range status {
+		if applied := i < len(all)-2; s.Applied != applied || s.Partial != "" {
+			t.Errorf("version %d applied %v partial %q, want applied %v", s.Version, s.Applied, s.Partial, applied)
+		}
+	}
+
+	// The reverted ones again, in order
+	if res, err = migrator.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+	want = []int64{all[len(all)-2], all[len(all)-1]}
+	if got := versions(res); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Up applied %v, want %v", got, want)
+	}
+
+	if res, err = migrator.Down(ctx, len(all)+1); err != nil || len(res) != len(all) {
+		t.Fatalf("Down reverted %v, %v", versions(res), err)
+	}
+}
+
+func TestChecksum(t *testing.T) {
+	ctx := context.Background()
+
+	migrator := &Migrator{
+		Conn:    open(t),
+		Dialect: database.DriverSqlite,
+		Migrations: []Migration{
+			{Version: 1, Name: "a", Up: "CREATE TABLE a (id INT)", Down: "DROP TABLE a", Checksum: "1"},
+		},
+	}
+
+	if _, err := migrator.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// Edited after it was applied
+	migrator.Migrations[0].Checksum = "2"
+
+	if _, err := migrator.Up(ctx); err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Errorf("Up returned %v, want a checksum mismatch", err)
+	}
+
+	if _, err := migrator.Down(ctx, 1); err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Errorf("Down returned %v, want a checksum mismatch", err)
+	}
+
+	// Gone from the build
+	migrator.Migrations = nil
+
+	if _, err := migrator.Up(ctx); err == nil || !strings.Contains(err.Error(), "missing from this build") {
+		t.Errorf("Up returned %v, want a missing migration", err)
+	}
+}
+
+func TestTransactionalRollback(t *testing.T) {
+	ctx := context.Background()
+
+	db := open(t)
+	migrator := &Migrator{
+		Conn:    db,
+		Dialect: database.DriverSqlite,
+		Migrations: []Migration{
+			{Version: 1, Name: "a", Up: "CREATE TABLE a (id INT); INSERT INTO missing VALUES (1)", Down: "DROP TABLE a", Checksum: "1"},
+		},
+	}
+
+	if _, err := migrator.Up(ctx); err == nil {
+		t.Fatal("expected the second statement to fail")
+	}
+
+	// Nothing of the script is left
+	if _, err := db.Exec("CREATE TABLE a (id INT)"); err != nil {
+		t.Errorf("table a survived the rollback: %v", err)
+	}
+
+	status, err := migrator.Status(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status[0].Applied || status[0].Partial != "" {
+		t.Errorf("status %+v, want pending", status[0])
+	}
+}
+
+// MySQL commits each DDL statement, a failed migration resumes after the last statement that ran
+func TestResume(t *testing.T) {
+	ctx := context.Background()
+
+	db := open(t)
+	migrator := &Migrator{
+		Conn:    db,
+		Dialect: database.DriverMysql,
+		Migrations: []Migration{
+			{
+				Version:  1,
+				Name:     "ab",
+				Up:       "CREATE TABLE a (id INT);\nCREATE TABLE b (id INT);\nINSERT INTO c VALUES (1);",
+				Down:     "DROP TABLE b;\nDROP TABLE c;\nDROP TABLE a;",
+				Checksum: "1",
+			},
+		},
+	}
+
+	if _, err := migrator.Up(ctx); err == nil || !strings.Contains(err.Error(), "statement 3") {
+		t.Fatalf("Up returned %v, want statement 3 to fail", err)
+	}
+
+	status, err := migrator.Status(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status[0].Applied || status[0].Partial != DirectionUp {
+		t.Fatalf("status %+v, want partly applied", status[0])
+	}
+
+	if _, err = migrator.Down(ctx, 1); err == nil {
+		t.Error("Down of a partly applied migration should fail")
+	}
+
+	// Running a and b again would fail, they exist already
+	if _, err = db.Exec("CREATE TABLE c (id INT)"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = migrator.Up(ctx); err != nil {
+		t.Fatalf("Up did not resume: %v", err)
+	}
+
+	if status, err = migrator.Status(ctx); err != nil || !status[0].Applied {
+		t.Fatalf("status %+v, %v, want applied", status, err)
+	}
+
+	// Reverting stops at c, dropped meanwhile
+	if _, err = db.Exec("DROP TABLE c"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = migrator.Down(ctx, 1); err == nil || !strings.Contains(err.Error(), "statement 2") {
+		t.Fatalf("Down returned %v, want statement 2 to fail", err)
+	}
+
+	if status, err = migrator.Status(ctx); err != nil || status[0].Partial != DirectionDown {
+		t.Fatalf("status %+v, %v, want partly reverted", status, err)
+	}
+
+	if _, err = migrator.Up(ctx); err == nil {
+		t.Error("Up of a partly reverted migration should fail")
+	}
+
+	if _, err = db.Exec("CREATE TABLE c (id INT)"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = migrator.Down(ctx, 1); err != nil {
+		t.Fatalf("Down did not resume: %v", err)
+	}
+
+	if status, err = migrator.Status(ctx); err != nil || status[0].Applied || status[0].Partial != "" {
+		t.Fatalf("status %+v, %v, want pending", status, err)
+	}
+}
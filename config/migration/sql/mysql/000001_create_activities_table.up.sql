@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS activities (
+	activity_id BIGINT NOT NULL AUTO_INCREMENT,
+	title VARCHAR(255) NOT NULL,
+	email VARCHAR(255) NULL,
+	created_at DATETIME NOT NULL,
+	updated_at DATETIME NOT NULL,
+	deleted_at DATETIME NULL,
+	PRIMARY KEY (activity_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS todos (
+	todo_id BIGINT NOT NULL AUTO_INCREMENT,
+	activity_group_id BIGINT NOT NULL,
+	title VARCHAR(255) NOT NULL,
+	is_active TINYINT(1) NULL DEFAULT 1,
+	priority VARCHAR(16) NOT NULL DEFAULT 'very-high',
+	created_at DATETIME NOT NULL,
+	updated_at DATETIME NOT NULL,
+	PRIMARY KEY (todo_id),
+	KEY idx_todos_activity_group_id (activity_group_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
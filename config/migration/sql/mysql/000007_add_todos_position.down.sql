@@ -0,0 +1,2 @@
+ALTER TABLE todos DROP INDEX idx_todos_activity_group_id_position;
+ALTER TABLE todos DROP COLUMN position;
@@ -0,0 +1,6 @@
+ALTER TABLE todos ADD COLUMN position BIGINT NOT NULL DEFAULT 0;
+
+-- The existing todos keep the order of their IDs, a gap apart
+UPDATE todos SET position = todo_id * 1024;
+
+CREATE INDEX idx_todos_activity_group_id_position ON todos (activity_group_id, position);
@@ -0,0 +1,3 @@
+ALTER TABLE todos DROP INDEX idx_todos_due_at;
+ALTER TABLE todos DROP COLUMN remind_at;
+ALTER TABLE todos DROP COLUMN due_at;
@@ -0,0 +1,4 @@
+ALTER TABLE todos DROP INDEX idx_todos_remind_at;
+ALTER TABLE todos DROP COLUMN remind_claimed_until;
+ALTER TABLE todos DROP COLUMN remind_claim;
+ALTER TABLE todos DROP COLUMN reminded_at;
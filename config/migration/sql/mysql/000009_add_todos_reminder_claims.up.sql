@@ -0,0 +1,7 @@
+ALTER TABLE todos ADD COLUMN reminded_at DATETIME NULL;
+
+-- The dispatcher holding the reminder, until when
+ALTER TABLE todos ADD COLUMN remind_claim VARCHAR(64) NULL;
+ALTER TABLE todos ADD COLUMN remind_claimed_until DATETIME NULL;
+
+CREATE INDEX idx_todos_remind_at ON todos (remind_at);
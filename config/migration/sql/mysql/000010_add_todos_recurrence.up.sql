@@ -0,0 +1,4 @@
+-- The RRULE of a recurring todo, its number in the series and the occurrence created on completion
+ALTER TABLE todos ADD COLUMN recurrence VARCHAR(255) NULL;
+ALTER TABLE todos ADD COLUMN occurrence BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE todos ADD COLUMN next_id BIGINT NULL;
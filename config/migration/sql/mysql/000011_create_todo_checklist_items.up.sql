@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS todo_checklist_items (
+	item_id BIGINT NOT NULL AUTO_INCREMENT,
+	todo_id BIGINT NOT NULL,
+	title VARCHAR(255) NOT NULL,
+	is_done TINYINT(1) NOT NULL DEFAULT 0,
+	position BIGINT NOT NULL DEFAULT 0,
+	created_at DATETIME NOT NULL,
+	updated_at DATETIME NOT NULL,
+	PRIMARY KEY (item_id),
+	KEY idx_todo_checklist_items_todo_id_position (todo_id, position)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS tags (
+	tag_id BIGINT NOT NULL AUTO_INCREMENT,
+	name VARCHAR(64) NOT NULL,
+	created_at DATETIME NOT NULL,
+	updated_at DATETIME NOT NULL,
+	PRIMARY KEY (tag_id),
+	UNIQUE KEY idx_tags_name (name)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- The tags of each todo, and the todos of each tag
+CREATE TABLE IF NOT EXISTS todo_tags (
+	todo_id BIGINT NOT NULL,
+	tag_id BIGINT NOT NULL,
+	PRIMARY KEY (todo_id, tag_id),
+	KEY idx_todo_tags_tag_id (tag_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,2 @@
+-- The sends of the reminder so far, it is given up after domain.RemindAttemptsMax
+ALTER TABLE todos ADD COLUMN remind_attempts INT NOT NULL DEFAULT 0;
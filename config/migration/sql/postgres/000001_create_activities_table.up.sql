@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS activities (
+	activity_id BIGSERIAL NOT NULL,
+	title VARCHAR(255) NOT NULL,
+	email VARCHAR(255) NULL,
+	created_at TIMESTAMP NOT NULL,
+	updated_at TIMESTAMP NOT NULL,
+	deleted_at TIMESTAMP NULL,
+	PRIMARY KEY (activity_id)
+);
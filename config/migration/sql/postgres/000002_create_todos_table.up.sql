@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS todos (
+	todo_id BIGSERIAL NOT NULL,
+	activity_group_id BIGINT NOT NULL,
+	title VARCHAR(255) NOT NULL,
+	is_active BOOLEAN NULL DEFAULT TRUE,
+	priority VARCHAR(16) NOT NULL DEFAULT 'very-high',
+	created_at TIMESTAMP NOT NULL,
+	updated_at TIMESTAMP NOT NULL,
+	PRIMARY KEY (todo_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_todos_activity_group_id ON todos (activity_group_id);
@@ -0,0 +1,4 @@
+ALTER TABLE todos ADD COLUMN completed_at TIMESTAMP NULL;
+
+-- Completed before the column existed, their last write is the best guess
+UPDATE todos SET completed_at = updated_at WHERE is_active = FALSE;
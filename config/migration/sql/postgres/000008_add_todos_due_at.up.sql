@@ -0,0 +1,4 @@
+ALTER TABLE todos ADD COLUMN due_at TIMESTAMP NULL;
+ALTER TABLE todos ADD COLUMN remind_at TIMESTAMP NULL;
+
+CREATE INDEX idx_todos_due_at ON todos (due_at);
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS todo_checklist_items (
+	item_id BIGSERIAL NOT NULL,
+	todo_id BIGINT NOT NULL,
+	title VARCHAR(255) NOT NULL,
+	is_done BOOLEAN NOT NULL DEFAULT FALSE,
+	position BIGINT NOT NULL DEFAULT 0,
+	created_at TIMESTAMP NOT NULL,
+	updated_at TIMESTAMP NOT NULL,
+	PRIMARY KEY (item_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_todo_checklist_items_todo_id_position ON todo_checklist_items (todo_id, position);
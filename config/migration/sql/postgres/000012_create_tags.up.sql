@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS tags (
+	tag_id BIGSERIAL NOT NULL,
+	name VARCHAR(64) NOT NULL,
+	created_at TIMESTAMP NOT NULL,
+	updated_at TIMESTAMP NOT NULL,
+	PRIMARY KEY (tag_id)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tags_name ON tags (name);
+
+-- The tags of each todo, and the todos of each tag
+CREATE TABLE IF NOT EXISTS todo_tags (
+	todo_id BIGINT NOT NULL,
+	tag_id BIGINT NOT NULL,
+	PRIMARY KEY (todo_id, tag_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_todo_tags_tag_id ON todo_tags (tag_id);
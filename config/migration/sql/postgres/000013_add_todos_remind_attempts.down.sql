@@ -0,0 +1 @@
+ALTER TABLE todos DROP COLUMN remind_attempts;
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS activities (
+	activity_id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+	title VARCHAR(255) NOT NULL,
+	email VARCHAR(255) NULL,
+	created_at DATETIME NOT NULL,
+	updated_at DATETIME NOT NULL,
+	deleted_at DATETIME NULL
+);
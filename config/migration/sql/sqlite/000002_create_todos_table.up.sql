@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS todos (
+	todo_id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+	activity_group_id BIGINT NOT NULL,
+	title VARCHAR(255) NOT NULL,
+	is_active BOOLEAN NULL DEFAULT 1,
+	priority VARCHAR(16) NOT NULL DEFAULT 'very-high',
+	created_at DATETIME NOT NULL,
+	updated_at DATETIME NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_todos_activity_group_id ON todos (activity_group_id);
@@ -0,0 +1 @@
+ALTER TABLE todos ADD COLUMN deleted_at DATETIME NULL;
@@ -0,0 +1,3 @@
+ALTER TABLE todos DROP COLUMN next_id;
+ALTER TABLE todos DROP COLUMN occurrence;
+ALTER TABLE todos DROP COLUMN recurrence;
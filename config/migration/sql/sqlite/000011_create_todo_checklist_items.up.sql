@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS todo_checklist_items (
+	item_id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+	todo_id BIGINT NOT NULL,
+	title VARCHAR(255) NOT NULL,
+	is_done BOOLEAN NOT NULL DEFAULT 0,
+	position BIGINT NOT NULL DEFAULT 0,
+	created_at DATETIME NOT NULL,
+	updated_at DATETIME NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_todo_checklist_items_todo_id_position ON todo_checklist_items (todo_id, position);
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS tags (
+	tag_id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+	name VARCHAR(64) NOT NULL,
+	created_at DATETIME NOT NULL,
+	updated_at DATETIME NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tags_name ON tags (name);
+
+-- The tags of each todo, and the todos of each tag
+CREATE TABLE IF NOT EXISTS todo_tags (
+	todo_id BIGINT NOT NULL,
+	tag_id BIGINT NOT NULL,
+	PRIMARY KEY (todo_id, tag_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_todo_tags_tag_id ON todo_tags (tag_id);
@@ -0,0 +1,123 @@
+package migration
+
+import (
+	"strings"
+
+	"github.com/fahmiaz411/devcode/config/database"
+)
+
+// statements splits a script into its statements on the semicolons outside of literals, quoted identifiers and comments.
+// Comments stay with the statement they precede, a part holding nothing but comments is left out.
+func statements(dialect, script string) (res []string) {
+	var (
+		current strings.Builder
+		code    bool
+	)
+
+	flush := func() {
+		if statement := strings.TrimSpace(current.String()); code && statement != "" {
+			res = append(res, statement)
+		}
+		current.Reset()
+		code = false
+	}
+
+	for i := 0; i < len(script); {
+		c := script[i]
+
+		switch {
+		case c == ';':
+			flush()
+			i++
+			continue
+
+		case c == '\'' || c == '"' || c == '`':
+			end := quoted(dialect, script, i)
+			current.WriteString(script[i:end])
+			code = true
+			i = end
+			continue
+
+		case strings.HasPrefix(script[i:], "--") || (c == '#' && dialect == database.DriverMysql):
+			end := strings.IndexByte(script[i:], '\n')
+			if end < 0 {
+				end = len(script) - i
+			}
+			current.WriteString(script[i : i+end])
+			i += end
+			continue
+
+		case strings.HasPrefix(script[i:], "/*"):
+			end := strings.Index(script[i+2:], "*/")
+			if end < 0 {
+				end = len(script) - i
+			} else {
+				end += 4
+			}
+			current.WriteString(script[i : i+end])
+			i += end
+			continue
+
+		case c == '$' && dialect == database.DriverPostgres:
+			if tag := dollarTag(script[i:]); tag != "" {
+				end := strings.Index(script[i+len(tag):], tag)
+				if end < 0 {
+					end = len(script) - i
+				} else {
+					end += 2 * len(tag)
+				}
+				current.WriteString(script[i : i+end])
+				code = true
+				i += end
+				continue
+			}
+		}
+
+		if c != ' ' && c != '\t' && c != '\n' && c != '\r' {
+			code = true
+		}
+		current.WriteByte(c)
+		i++
+	}
+
+	flush()
+
+	return
+}
+
+// quoted is the end of the literal or quoted identifier starting at start, a doubled quote stands for itself.
+// MySQL also escapes with a backslash, the other dialects read it as is.
+func quoted(dialect, script string, start int) int {
+	quote := script[start]
+
+	for i := start + 1; i < len(script); i++ {
+		switch {
+		case script[i] == '\\' && dialect == database.DriverMysql && quote != '`':
+			i++
+		case script[i] == quote:
+			if i+1 < len(script) && script[i+1] == quote {
+				i++
+				continue
+			}
+			return i + 1
+		}
+	}
+
+	return len(script)
+}
+
+// dollarTag is the $tag$ opening a Postgres dollar quoted string at the start of s, empty when there is none
+func dollarTag(s string) string {
+	for i := 1; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '$':
+			return s[:i+1]
+		case c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || (i > 1 && c >= '0' && c <= '9'):
+		default:
+			return ""
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,92 @@
+package migration
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/fahmiaz411/devcode/config/database"
+)
+
+func TestStatements(t *testing.T) {
+	tests := []struct {
+		name    string
+		dialect string
+		script  string
+		want    []string
+	}{
+		{
+			name:    "one per semicolon",
+			dialect: database.DriverMysql,
+			script:  "CREATE TABLE a (id INT);\nCREATE TABLE b (id INT);\n",
+			want:    []string{"CREATE TABLE a (id INT)", "CREATE TABLE b (id INT)"},
+		},
+		{
+			name:    "last without semicolon",
+			dialect: database.DriverSqlite,
+			script:  "DROP TABLE a;\nDROP TABLE b",
+			want:    []string{"DROP TABLE a", "DROP TABLE b"},
+		},
+		{
+			name:    "empty",
+			dialect: database.DriverMysql,
+			script:  " \n;;\n",
+			want:    nil,
+		},
+		{
+			name:    "semicolon in a literal",
+			dialect: database.DriverSqlite,
+			script:  "INSERT INTO a VALUES ('x;y');INSERT INTO a VALUES ('it''s;')",
+			want:    []string{"INSERT INTO a VALUES ('x;y')", "INSERT INTO a VALUES ('it''s;')"},
+		},
+		{
+			name:    "semicolon in quoted identifiers",
+			dialect: database.DriverMysql,
+			script:  "SELECT `a;b`, \"c;d\" FROM t;SELECT 1",
+			want:    []string{"SELECT `a;b`, \"c;d\" FROM t", "SELECT 1"},
+		},
+		{
+			name:    "backslash escape on mysql",
+			dialect: database.DriverMysql,
+			script:  `INSERT INTO a VALUES ('\';');SELECT 1`,
+			want:    []string{`INSERT INTO a VALUES ('\';')`, "SELECT 1"},
+		},
+		{
+			name:    "backslash as is elsewhere",
+			dialect: database.DriverPostgres,
+			script:  `SELECT * FROM a WHERE b LIKE '%\%' ESCAPE '\';SELECT 1`,
+			want:    []string{`SELECT * FROM a WHERE b LIKE '%\%' ESCAPE '\'`, "SELECT 1"},
+		},
+		{
+			name:    "semicolon in comments",
+			dialect: database.DriverMysql,
+			script:  "-- first; the table\nCREATE TABLE a (id INT); /* then; b */ CREATE TABLE b (id INT);\n# done; really",
+			want:    []string{"-- first; the table\nCREATE TABLE a (id INT)", "/* then; b */ CREATE TABLE b (id INT)"},
+		},
+		{
+			name:    "hash is code outside of mysql",
+			dialect: database.DriverPostgres,
+			script:  "SELECT 1 # 2;SELECT 3",
+			want:    []string{"SELECT 1 # 2", "SELECT 3"},
+		},
+		{
+			name:    "dollar quoted body on postgres",
+			dialect: database.DriverPostgres,
+			script:  "CREATE FUNCTION f() RETURNS INT AS $body$ SELECT 1; $body$ LANGUAGE SQL;SELECT $1",
+			want:    []string{"CREATE FUNCTION f() RETURNS INT AS $body$ SELECT 1; $body$ LANGUAGE SQL", "SELECT $1"},
+		},
+		{
+			name:    "comment only part",
+			dialect: database.DriverSqlite,
+			script:  "DROP TABLE a;\n-- nothing left\n",
+			want:    []string{"DROP TABLE a"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := statements(tt.dialect, tt.script); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("statements() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package failure
+
+import (
+	"errors"
+)
+
+type Kind string
+
+const (
+	KindNotFound     Kind = "NotFound"
+	KindValidation   Kind = "Validation"
+	KindConflict     Kind = "Conflict"
+	KindPrecondition Kind = "Precondition"
+	KindInternal     Kind = "Internal"
+)
+
+// Error is a business error, its kind tells the caller what went wrong regardless of the transport
+type Error struct {
+	Kind    Kind
+	Message string
+	Err     error
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func NotFound(message string) error {
+	return &Error{Kind: KindNotFound, Message: message}
+}
+
+func Validation(message string) error {
+	return &Error{Kind: KindValidation, Message: message}
+}
+
+func Conflict(message string) error {
+	return &Error{Kind: KindConflict, Message: message}
+}
+
+// Precondition is a failed If-Match, the resource changed since the client read it
+func Precondition(message string) error {
+	return &Error{Kind: KindPrecondition, Message: message}
+}
+
+// Internal wraps an unexpected error, e.g. from the database
+func Internal(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var e *Error
+	if errors.As(err, &e) {
+		return err
+	}
+
+	return &Error{Kind: KindInternal, Message: err.Error(), Err: err}
+}
+
+// KindOf returns the kind of err, anything that is not an *Error is internal
+func KindOf(err error) Kind {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Kind
+	}
+
+	return KindInternal
+}
+
+func Is(err error, kind Kind) bool {
+	return err != nil && KindOf(err) == kind
+}
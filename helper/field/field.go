@@ -5,4 +5,29 @@ const (
 	Email           = "email"
 	ActivityGroupID = "activity_group_id"
 	Priority        = "priority"
+	IsActive        = "is_active"
+	Limit           = "limit"
+	Offset          = "offset"
+	Sort            = "sort"
+	Order           = "order"
+	Include         = "include"
+	From            = "from"
+	To              = "to"
+	Query           = "q"
+	Types           = "types"
+	Before          = "before"
+	After           = "after"
+	DueAt           = "due_at"
+	RemindAt        = "remind_at"
+	Due             = "due"
+	Frequency       = "recurrence.frequency"
+	Interval        = "recurrence.interval"
+	ByWeekday       = "recurrence.by_weekday"
+	ByMonthDay      = "recurrence.by_month_day"
+	Until           = "recurrence.until"
+	Count           = "recurrence.count"
+	Checklist       = "checklist"
+	Name            = "name"
+	AttachTags      = "attach_tags"
+	DetachTags      = "detach_tags"
 )
\ No newline at end of file
@@ -8,6 +8,9 @@ import (
 const (
 	Success string = "Success"
 	InvalidRequestBody = "Invalid Request Body"
+	InvalidCursor = "Invalid cursor"
+	BulkSkipped = "Not written, another item of the batch was rejected"
+	BulkModified = "Items of the batch have been modified meanwhile, try again"
 )
 
 func NotFound(name, property, value string) string {
@@ -24,4 +27,83 @@ func InvalidId(name string) string {
 
 func ShoudMatchEnum(property string, enums []string) string {
 	return fmt.Sprintf("field %s should match one of: %s", property, strings.Join(enums, ", "))
-}
\ No newline at end of file
+}
+
+func CannotNegative(property string) string {
+	return fmt.Sprintf("%s cannot be negative", property)
+}
+
+func InvalidBoolean(property string) string {
+	return fmt.Sprintf("field %s should be true or false", property)
+}
+
+func InvalidDate(property string) string {
+	return fmt.Sprintf("field %s should be a date (YYYY-MM-DD) or an RFC 3339 time", property)
+}
+
+func NotEmpty(name, property, value, child string) string {
+	return fmt.Sprintf("%s with %s %s still has %s", name, property, value, child)
+}
+
+func MustPositive(property string) string {
+	return fmt.Sprintf("%s must be positive", property)
+}
+
+func InvalidDuration(property string) string {
+	return fmt.Sprintf("%s should be a duration such as 720h", property)
+}
+
+func Modified(name, property, value string) string {
+	return fmt.Sprintf("%s with %s %s has been modified, fetch it again", name, property, value)
+}
+
+func CannotEmpty(property string) string {
+	return fmt.Sprintf("%s cannot be empty", property)
+}
+
+func TooMany(property string, max int) string {
+	return fmt.Sprintf("%s cannot have more than %d items", property, max)
+}
+
+func Duplicate(name, property, value string) string {
+	return fmt.Sprintf("%s with %s %s is given more than once", name, property, value)
+}
+
+func BulkRejected(rejected, total int) string {
+	return fmt.Sprintf("%d of %d items rejected, none were written", rejected, total)
+}
+
+func MustBefore(property, other string) string {
+	return fmt.Sprintf("%s must be before %s", property, other)
+}
+
+func RangeTooLong(from, to string, max int) string {
+	return fmt.Sprintf("%s to %s cannot span more than %d days", from, to, max)
+}
+func ExactlyOne(property, other string) string {
+	return fmt.Sprintf("exactly one of %s and %s must be given", property, other)
+}
+
+func OtherGroup(name, property, value string) string {
+	return fmt.Sprintf("%s with %s %s is in another activity group", name, property, value)
+}
+
+func MoveItself(name string) string {
+	return fmt.Sprintf("%s cannot be moved next to itself", name)
+}
+
+func CannotCombine(property, other string) string {
+	return fmt.Sprintf("%s cannot be combined with %s", property, other)
+}
+
+func Between(property string, min, max int) string {
+	return fmt.Sprintf("%s must be between %d and %d", property, min, max)
+}
+
+func TooLong(property string, max int) string {
+	return fmt.Sprintf("%s cannot be longer than %d characters", property, max)
+}
+
+func AlreadyExists(name, property, value string) string {
+	return fmt.Sprintf("%s with %s %s already exists", name, property, value)
+}
@@ -0,0 +1,103 @@
+package pagination
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fahmiaz411/devcode/helper/constant"
+)
+
+// Paginate sorts and pages rows that are already filtered, for stores listing in Go the way the SQL stores do.
+// id and value read the id and the cursor value of a row, parse converts a cursor value back to its type.
+func Paginate[T any](r Request, rows []T, id func(T) int64, value func(T) string, parse func(sort, value string) (any, error)) (res []T, paging Paging, err error) {
+	paging.Limit = r.Limit
+	paging.Offset = r.Offset
+	paging.Total = int64(len(rows))
+
+	type keyed struct {
+		row   T
+		id    int64
+		value any
+	}
+
+	items := make([]keyed, len(rows))
+	for i, row := range rows {
+		items[i] = keyed{row: row, id: id(row)}
+		if items[i].value, err = parse(r.Sort, value(row)); err != nil {
+			return
+		}
+	}
+
+	direction := 1
+	if r.Order == OrderDesc {
+		direction = -1
+	}
+
+	after := func(a keyed, value any, id int64) bool {
+		c := compare(a.value, value)
+		if c == 0 {
+			c = compare(a.id, id)
+		}
+		return c*direction > 0
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return after(items[j], items[i].value, items[i].id)
+	})
+
+	if r.After != nil {
+		var cursor any
+		if cursor, err = parse(r.Sort, r.After.Value); err != nil {
+			return
+		}
+
+		rest := items[:0]
+		for _, item := range items {
+			if after(item, cursor, r.After.ID) {
+				rest = append(rest, item)
+			}
+		}
+		items = rest
+	}
+
+	if r.Offset >= len(items) {
+		items = items[:0]
+	} else {
+		items = items[r.Offset:]
+	}
+
+	if r.Limit != constant.ZeroValue && len(items) > r.Limit {
+		items = items[:r.Limit]
+
+		last := items[r.Limit-1]
+		paging.NextCursor = r.Next(value(last.row), last.id)
+	}
+
+	res = make([]T, len(items))
+	for i, item := range items {
+		res[i] = item.row
+	}
+
+	return
+}
+
+// compare orders two sort values of the same type, strings byte by byte like database.Dialect.Binary
+func compare(a, b any) int {
+	switch a := a.(type) {
+	case int64:
+		b := b.(int64)
+		if a < b {
+			return -1
+		} else if a > b {
+			return 1
+		}
+		return 0
+	case string:
+		return strings.Compare(a, b.(string))
+	case time.Time:
+		return a.Compare(b.(time.Time))
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,155 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/fahmiaz411/devcode/helper/constant"
+	"github.com/fahmiaz411/devcode/helper/field"
+	"github.com/fahmiaz411/devcode/helper/message"
+	"github.com/fahmiaz411/devcode/helper/slice"
+)
+
+// Order
+const (
+	OrderAsc  = "asc"
+	OrderDesc = "desc"
+
+	OrderDefault = OrderAsc
+)
+
+var (
+	OrderAllList = []string{
+		OrderAsc,
+		OrderDesc,
+	}
+)
+
+// Limit
+const (
+	DefaultLimit = 20
+	MaxLimit     = 1000
+)
+
+// Request carries the paging query of a list endpoint.
+// A zero Limit is DefaultLimit, a list never returns more than MaxLimit rows.
+type Request struct {
+	Limit  int    `json:"limit"`
+	Offset int    `json:"offset"`
+	Cursor string `json:"cursor"`
+	Sort   string `json:"sort"`
+	Order  string `json:"order"`
+
+	// After is the decoded Cursor, set by Validate
+	After *Cursor `json:"-"`
+}
+
+// Paging is returned next to the listed data
+type Paging struct {
+	Limit      int    `json:"limit"`
+	Offset     int    `json:"offset"`
+	NextCursor string `json:"nextCursor"`
+	Total      int64  `json:"total"`
+}
+
+// Cursor points right after the last row of a page, in the sort it was listed with
+type Cursor struct {
+	Sort  string `json:"s"`
+	Order string `json:"o"`
+	Value string `json:"v"`
+	ID    int64  `json:"id"`
+}
+
+// Validate checks the request against the sortable fields of a model and fills defaults.
+// A cursor keeps the sort and order of the page it was issued for.
+func (r *Request) Validate(sorts []string) error {
+	if r.Limit < constant.ZeroValue {
+		return errors.New(message.CannotNegative(field.Limit))
+	}
+
+	if r.Offset < constant.ZeroValue {
+		return errors.New(message.CannotNegative(field.Offset))
+	}
+
+	if r.Cursor != constant.EmptyString {
+		cursor, err := DecodeCursor(r.Cursor)
+		if err != nil || !slice.Includes(sorts, cursor.Sort) || !slice.Includes(OrderAllList, cursor.Order) {
+			return errors.New(message.InvalidCursor)
+		}
+
+		r.After = &cursor
+		r.Sort = cursor.Sort
+		r.Order = cursor.Order
+		r.Offset = constant.ZeroValue
+	}
+
+	if r.Sort == constant.EmptyString {
+		r.Sort = sorts[0]
+	} else if !slice.Includes(sorts, r.Sort) {
+		return errors.New(message.ShoudMatchEnum(field.Sort, sorts))
+	}
+
+	if r.Order == constant.EmptyString {
+		r.Order = OrderDefault
+	} else if !slice.Includes(OrderAllList, r.Order) {
+		return errors.New(message.ShoudMatchEnum(field.Order, OrderAllList))
+	}
+
+	if r.Limit == constant.ZeroValue {
+		r.Limit = DefaultLimit
+	} else if r.Limit > MaxLimit {
+		r.Limit = MaxLimit
+	}
+
+	return nil
+}
+
+// Keyset returns the condition continuing after the cursor for the given sort and id columns,
+// it takes the cursor value twice followed by the cursor id
+func (r Request) Keyset(column, idColumn string) string {
+	operator := ">"
+	if r.Order == OrderDesc {
+		operator = "<"
+	}
+
+	return fmt.Sprintf("(%[1]s %[3]s ? OR (%[1]s = ? AND %[2]s %[3]s ?))", column, idColumn, operator)
+}
+
+// OrderBy returns the ORDER BY list for the given sort and id columns,
+// an order other than OrderAllList is never written into the query
+func (r Request) OrderBy(column, idColumn string) string {
+	order := OrderAsc
+	if r.Order == OrderDesc {
+		order = OrderDesc
+	}
+
+	return fmt.Sprintf("%[1]s %[3]s, %[2]s %[3]s", column, idColumn, order)
+}
+
+// Next returns the cursor following a row of the page
+func (r Request) Next(value string, id int64) string {
+	return EncodeCursor(Cursor{
+		Sort:  r.Sort,
+		Order: r.Order,
+		Value: value,
+		ID:    id,
+	})
+}
+
+func EncodeCursor(cursor Cursor) string {
+	b, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func DecodeCursor(s string) (cursor Cursor, err error) {
+	var b []byte
+	b, err = base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return
+	}
+
+	err = json.Unmarshal(b, &cursor)
+	return
+}
@@ -0,0 +1,246 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"reflect"
+	"testing"
+
+	"github.com/fahmiaz411/devcode/helper/field"
+	"github.com/fahmiaz411/devcode/helper/message"
+)
+
+var sorts = []string{"id", "title"}
+
+func TestValidate(t *testing.T) {
+	cursor := EncodeCursor(Cursor{Sort: "title", Order: OrderDesc, Value: "b", ID: 7})
+
+	tests := []struct {
+		name string
+		req  Request
+		want Request
+		err  string
+	}{
+		{
+			name: "defaults",
+			req:  Request{},
+			want: Request{Limit: DefaultLimit, Sort: "id", Order: OrderAsc},
+		},
+		{
+			name: "offset without limit pages by default",
+			req:  Request{Offset: 40},
+			want: Request{Limit: DefaultLimit, Offset: 40, Sort: "id", Order: OrderAsc},
+		},
+		{
+			name: "limit capped",
+			req:  Request{Limit: MaxLimit + 1, Sort: "title", Order: OrderDesc},
+			want: Request{Limit: MaxLimit, Sort: "title", Order: OrderDesc},
+		},
+		{
+			name: "negative limit",
+			req:  Request{Limit: -1},
+			err:  message.CannotNegative(field.Limit),
+		},
+		{
+			name: "negative offset",
+			req:  Request{Offset: -1},
+			err:  message.CannotNegative(field.Offset),
+		},
+		{
+			name: "unknown sort",
+			req:  Request{Sort: "title; DROP TABLE todos"},
+			err:  message.ShoudMatchEnum(field.Sort, sorts),
+		},
+		{
+			name: "unknown order",
+			req:  Request{Order: "asc, id"},
+			err:  message.ShoudMatchEnum(field.Order, OrderAllList),
+		},
+		{
+			name: "cursor keeps its sort and order",
+			req:  Request{Cursor: cursor, Sort: "id", Order: OrderAsc, Offset: 5},
+			want: Request{
+				Limit:  DefaultLimit,
+				Cursor: cursor,
+				Sort:   "title",
+				Order:  OrderDesc,
+				After:  &Cursor{Sort: "title", Order: OrderDesc, Value: "b", ID: 7},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := tt.req
+			err := req.Validate(sorts)
+
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Fatalf("Validate() error = %v, want %q", err, tt.err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+
+			if !reflect.DeepEqual(req, tt.want) {
+				t.Errorf("Validate() = %+v, want %+v", req, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateInvalidCursor(t *testing.T) {
+	tests := []struct {
+		name   string
+		cursor string
+	}{
+		{"not base64", "%%%"},
+		{"not json", base64.RawURLEncoding.EncodeToString([]byte("title:b"))},
+		{"padded base64", base64.URLEncoding.EncodeToString([]byte(`{"s":"id","o":"asc","v":"1","id":1}`))},
+		{"unknown sort", EncodeCursor(Cursor{Sort: "secret", Order: OrderAsc, Value: "1", ID: 1})},
+		{"unknown order", EncodeCursor(Cursor{Sort: "id", Order: "asc; --", Value: "1", ID: 1})},
+		{"empty sort", EncodeCursor(Cursor{Order: OrderAsc, Value: "1", ID: 1})},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := Request{Cursor: tt.cursor}
+			if err := req.Validate(sorts); err == nil || err.Error() != message.InvalidCursor {
+				t.Errorf("Validate() error = %v, want %q", err, message.InvalidCursor)
+			}
+		})
+	}
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	cursors := []Cursor{
+		{Sort: "id", Order: OrderAsc, Value: "42", ID: 42},
+		{Sort: "title", Order: OrderDesc, Value: `quotes " and unicode é`, ID: 1},
+		{Sort: "createdAt", Order: OrderAsc, Value: "2024-01-02T03:04:05.123456789Z", ID: 9},
+		{},
+	}
+
+	for _, cursor := range cursors {
+		encoded := EncodeCursor(cursor)
+
+		decoded, err := DecodeCursor(encoded)
+		if err != nil {
+			t.Fatalf("DecodeCursor(%q) error = %v", encoded, err)
+		}
+
+		if decoded != cursor {
+			t.Errorf("round trip of %+v = %+v", cursor, decoded)
+		}
+	}
+}
+
+func TestNext(t *testing.T) {
+	req := Request{Sort: "title", Order: OrderDesc}
+
+	cursor, err := DecodeCursor(req.Next("b", 7))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := (Cursor{Sort: "title", Order: OrderDesc, Value: "b", ID: 7}); cursor != want {
+		t.Errorf("Next() = %+v, want %+v", cursor, want)
+	}
+}
+
+func TestKeyset(t *testing.T) {
+	tests := []struct {
+		order string
+		want  string
+	}{
+		{OrderAsc, "(title > ? OR (title = ? AND todo_id > ?))"},
+		{OrderDesc, "(title < ? OR (title = ? AND todo_id < ?))"},
+	}
+
+	for _, tt := range tests {
+		if got := (Request{Order: tt.order}).Keyset("title", "todo_id"); got != tt.want {
+			t.Errorf("Keyset() with %s = %q, want %q", tt.order, got, tt.want)
+		}
+	}
+}
+
+func TestOrderBy(t *testing.T) {
+	tests := []struct {
+		order string
+		want  string
+	}{
+		{OrderAsc, "title asc, todo_id asc"},
+		{OrderDesc, "title desc, todo_id desc"},
+		{"", "title asc, todo_id asc"},
+		{"desc; DROP TABLE todos", "title asc, todo_id asc"},
+	}
+
+	for _, tt := range tests {
+		if got := (Request{Order: tt.order}).OrderBy("title", "todo_id"); got != tt.want {
+			t.Errorf("OrderBy() with %q = %q, want %q", tt.order, got, tt.want)
+		}
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	type row struct {
+		id    int64
+		title string
+	}
+
+	rows := []row{{1, "b"}, {2, "a"}, {3, "B"}, {4, "c"}}
+
+	id := func(r row) int64 { return r.id }
+	value := func(r row) string { return r.title }
+	parse := func(sort, value string) (any, error) { return value, nil }
+
+	ids := func(rows []row) (res []int64) {
+		for _, r := range rows {
+			res = append(res, r.id)
+		}
+		return
+	}
+
+	// Byte by byte, upper case first, ties by id
+	req := Request{Limit: 2, Sort: "title", Order: OrderAsc}
+	page, paging, err := Paginate(req, rows, id, value, parse)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(page); !reflect.DeepEqual(got, []int64{3, 2}) {
+		t.Fatalf("first page = %v", got)
+	}
+	if paging.Total != 4 || paging.NextCursor == "" {
+		t.Fatalf("paging = %+v", paging)
+	}
+
+	req.Cursor = paging.NextCursor
+	if err = req.Validate(sorts); err != nil {
+		t.Fatal(err)
+	}
+	if page, paging, err = Paginate(req, rows, id, value, parse); err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(page); !reflect.DeepEqual(got, []int64{1, 4}) {
+		t.Fatalf("second page = %v", got)
+	}
+	if paging.NextCursor != "" {
+		t.Errorf("last page has a next cursor %q", paging.NextCursor)
+	}
+
+	// Descending, by offset
+	page, _, err = Paginate(Request{Limit: 2, Offset: 1, Sort: "title", Order: OrderDesc}, rows, id, value, parse)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(page); !reflect.DeepEqual(got, []int64{1, 2}) {
+		t.Errorf("descending page = %v", got)
+	}
+
+	// Past the end
+	page, _, err = Paginate(Request{Offset: 10, Sort: "title", Order: OrderAsc}, rows, id, value, parse)
+	if err != nil || len(page) != 0 {
+		t.Errorf("page past the end = %v, %v", ids(page), err)
+	}
+}
@@ -0,0 +1,15 @@
+package pagination
+
+import (
+	"time"
+)
+
+// Stamp sums up the rows of a list cheaply, it changes whenever the list does
+type Stamp struct {
+	Count    int64
+	Versions int64
+	LastID   int64
+
+	// LastModified also covers the rows that left the list, e.g. deleted ones
+	LastModified time.Time
+}
@@ -3,4 +3,6 @@ package params
 const (
 	ActivityId string = "activityId"
 	TodoId     string = "todoId"
+	ItemId     string = "itemId"
+	TagId      string = "tagId"
 )
\ No newline at end of file
@@ -2,4 +2,26 @@ package query
 
 const (
 	ActivityGroupID = "activity_group_id"
+	IsActive        = "is_active"
+	Priority        = "priority"
+	Title           = "title"
+	CreatedFrom     = "created_from"
+	CreatedTo       = "created_to"
+	UpdatedFrom     = "updated_from"
+	UpdatedTo       = "updated_to"
+	DueFrom         = "due_from"
+	DueTo           = "due_to"
+	Due             = "due"
+	Limit           = "limit"
+	Offset          = "offset"
+	Cursor          = "cursor"
+	Sort            = "sort"
+	Order           = "order"
+	Include         = "include"
+	From            = "from"
+	To              = "to"
+	Query           = "q"
+	Types           = "types"
+	Tag             = "tag"
+	Name            = "name"
 )
\ No newline at end of file
@@ -0,0 +1,83 @@
+package web
+
+import (
+	"net/http"
+
+	"github.com/fahmiaz411/devcode/helper/message"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// BulkResult is the outcome of one item of a batch
+type BulkResult struct {
+	Data any
+	Err  error
+}
+
+// BulkItem is the BaseResponse of one item of a batch
+type BulkItem struct {
+	Status  string `json:"status"`
+	Message string `json:"message"`
+	Data    any    `json:"data"`
+}
+
+// Bulk writes the outcome of a batch written all or nothing, its items in the order of the request.
+// A rejected item gives the response its status, the valid items are then reported as not written.
+func Bulk(c *fiber.Ctx, code int, results []BulkResult) error {
+	items := make([]BulkItem, len(results))
+	rejected := 0
+
+	for i, result := range results {
+		if result.Err == nil {
+			continue
+		}
+
+		itemCode := StatusCode(result.Err)
+		if rejected == 0 {
+			code = itemCode
+		}
+		rejected++
+
+		items[i] = BulkItem{
+			Status:  http.StatusText(itemCode),
+			Message: result.Err.Error(),
+			Data:    struct{}{},
+		}
+	}
+
+	for i, result := range results {
+		if result.Err != nil {
+			continue
+		}
+
+		if rejected != 0 {
+			items[i] = BulkItem{
+				Status:  http.StatusText(http.StatusFailedDependency),
+				Message: message.BulkSkipped,
+				Data:    struct{}{},
+			}
+			continue
+		}
+
+		items[i] = BulkItem{
+			Status:  message.Success,
+			Message: message.Success,
+			Data:    result.Data,
+		}
+		if result.Data == nil {
+			items[i].Data = struct{}{}
+		}
+	}
+
+	res := BaseResponse{
+		Status:  message.Success,
+		Message: message.Success,
+		Data:    items,
+	}
+	if rejected != 0 {
+		res.Status = http.StatusText(code)
+		res.Message = message.BulkRejected(rejected, len(results))
+	}
+
+	return c.Status(code).JSON(res)
+}
@@ -0,0 +1,101 @@
+package web
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/fahmiaz411/devcode/helper/failure"
+	"github.com/fahmiaz411/devcode/helper/message"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestBulk(t *testing.T) {
+	skipped := BulkItem{Status: http.StatusText(http.StatusFailedDependency), Message: message.BulkSkipped, Data: map[string]any{}}
+	success := func(data any) BulkItem {
+		return BulkItem{Status: message.Success, Message: message.Success, Data: data}
+	}
+
+	tests := []struct {
+		name    string
+		results []BulkResult
+		code    int
+		message string
+		items   []BulkItem
+	}{
+		{
+			name:    "written",
+			results: []BulkResult{{Data: map[string]any{"id": 1.0}}, {}},
+			code:    http.StatusCreated,
+			message: message.Success,
+			items:   []BulkItem{success(map[string]any{"id": 1.0}), success(map[string]any{})},
+		},
+		{
+			name: "rejected",
+			results: []BulkResult{
+				{Data: map[string]any{"id": 1.0}},
+				{Err: failure.NotFound("gone")},
+				{Err: failure.Validation("bad")},
+			},
+			code:    http.StatusNotFound,
+			message: message.BulkRejected(2, 3),
+			items: []BulkItem{
+				skipped,
+				{Status: http.StatusText(http.StatusNotFound), Message: "gone", Data: map[string]any{}},
+				{Status: http.StatusText(http.StatusBadRequest), Message: "bad", Data: map[string]any{}},
+			},
+		},
+		{
+			name:    "internal",
+			results: []BulkResult{{Err: failure.Internal(errors.New("down"))}, {}},
+			code:    http.StatusInternalServerError,
+			message: message.BulkRejected(1, 2),
+			items: []BulkItem{
+				{Status: http.StatusText(http.StatusInternalServerError), Message: failure.Internal(errors.New("down")).Error(), Data: map[string]any{}},
+				skipped,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, body := serve(t, "/", nil, func(c *fiber.Ctx) error {
+				return Bulk(c, http.StatusCreated, tt.results)
+			})
+
+			if res.StatusCode != tt.code {
+				t.Errorf("status = %d, want %d", res.StatusCode, tt.code)
+			}
+
+			var got struct {
+				Status  string     `json:"status"`
+				Message string     `json:"message"`
+				Data    []BulkItem `json:"data"`
+			}
+			if err := json.Unmarshal([]byte(body), &got); err != nil {
+				t.Fatal(err)
+			}
+
+			// A written batch succeeds with the status of the request, a rejected one takes that of its first rejection
+			want := message.Success
+			if tt.message != message.Success {
+				want = http.StatusText(tt.code)
+			}
+
+			if got.Status != want {
+				t.Errorf("status = %q, want %q", got.Status, want)
+			}
+
+			if got.Message != tt.message {
+				t.Errorf("message = %q, want %q", got.Message, tt.message)
+			}
+
+			if !reflect.DeepEqual(got.Data, tt.items) {
+				t.Errorf("items = %+v, want %+v", got.Data, tt.items)
+			}
+		})
+	}
+}
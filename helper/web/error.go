@@ -0,0 +1,40 @@
+package web
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/fahmiaz411/devcode/helper/failure"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+var statusCodes = map[failure.Kind]int{
+	failure.KindNotFound:     http.StatusNotFound,
+	failure.KindValidation:   http.StatusBadRequest,
+	failure.KindConflict:     http.StatusConflict,
+	failure.KindPrecondition: http.StatusPreconditionFailed,
+	failure.KindInternal:     http.StatusInternalServerError,
+}
+
+// ErrorHandler writes the error returned by a handler as a BaseResponse
+func ErrorHandler(c *fiber.Ctx, err error) error {
+	code := StatusCode(err)
+
+	// Routing errors, e.g. 404 for an unknown path
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		code = fiberErr.Code
+	}
+
+	return c.Status(code).JSON(BaseResponse{
+		Status:  http.StatusText(code),
+		Message: err.Error(),
+		Data:    struct{}{},
+	})
+}
+
+// StatusCode is the HTTP status of a usecase error
+func StatusCode(err error) int {
+	return statusCodes[failure.KindOf(err)]
+}
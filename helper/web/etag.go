@@ -0,0 +1,83 @@
+package web
+
+import (
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fahmiaz411/devcode/helper/constant"
+	"github.com/fahmiaz411/devcode/helper/pagination"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// NoMatch is the version of an If-Match naming no version of ours, it never matches
+const NoMatch int64 = -1
+
+// ETag of a resource at version
+func ETag(version int64) string {
+	return fmt.Sprintf(`"%d"`, version)
+}
+
+// ListETag of a list, weak as it only tells the rows did not change.
+// The query is part of it, each page, filter or sort of the same rows is another representation.
+func ListETag(c *fiber.Ctx, stamp pagination.Stamp) string {
+	query := fnv.New32a()
+	query.Write(c.Request().URI().QueryString())
+
+	return fmt.Sprintf(`W/"%d-%d-%d-%x"`, stamp.Count, stamp.Versions, stamp.LastID, query.Sum32())
+}
+
+// IfMatch returns the version required by the If-Match header, 0 when any version will do
+func IfMatch(c *fiber.Ctx) int64 {
+	header := strings.TrimSpace(c.Get(fiber.HeaderIfMatch))
+	if header == constant.EmptyString || header == "*" {
+		return int64(constant.ZeroValue)
+	}
+
+	// Weak tags are accepted, a version is the same for every representation
+	tag := strings.TrimPrefix(header, "W/")
+
+	if len(tag) < 2 || !strings.HasPrefix(tag, `"`) || !strings.HasSuffix(tag, `"`) {
+		return NoMatch
+	}
+
+	version, err := strconv.ParseInt(tag[1:len(tag)-1], 10, 64)
+	if err != nil || version <= int64(constant.ZeroValue) {
+		return NoMatch
+	}
+
+	return version
+}
+
+// NotModified sets the ETag and Last-Modified of the response,
+// then tells whether the copy of the client is current per If-None-Match, or If-Modified-Since without it
+func NotModified(c *fiber.Ctx, etag string, lastModified time.Time) bool {
+	c.Set(fiber.HeaderETag, etag)
+	if !lastModified.IsZero() {
+		c.Set(fiber.HeaderLastModified, lastModified.UTC().Format(http.TimeFormat))
+	}
+
+	if header := c.Get(fiber.HeaderIfNoneMatch); header != constant.EmptyString {
+		for _, tag := range strings.Split(header, ",") {
+			tag = strings.TrimSpace(tag)
+			if tag == "*" || strings.TrimPrefix(tag, "W/") == strings.TrimPrefix(etag, "W/") {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	if header := c.Get(fiber.HeaderIfModifiedSince); header != constant.EmptyString && !lastModified.IsZero() {
+		since, err := http.ParseTime(header)
+		if err == nil && !lastModified.Truncate(time.Second).After(since) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,231 @@
+package web
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/fahmiaz411/devcode/helper/pagination"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// serve runs handler on a request to target with the given headers and returns the response
+func serve(t *testing.T, target string, headers map[string]string, handler fiber.Handler) (res *http.Response, body string) {
+	t.Helper()
+
+	app := fiber.New()
+	app.Get("/", handler)
+
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	res, err := app.Test(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	b, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return res, string(b)
+}
+
+func TestETag(t *testing.T) {
+	if got := ETag(42); got != `"42"` {
+		t.Errorf("ETag(42) = %s, want %s", got, `"42"`)
+	}
+}
+
+func TestIfMatch(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		want   int64
+	}{
+		{name: "absent", want: 0},
+		{name: "any", header: "*", want: 0},
+		{name: "strong", header: `"3"`, want: 3},
+		{name: "weak", header: `W/"3"`, want: 3},
+		{name: "padded", header: `  "3"  `, want: 3},
+		{name: "round trip", header: ETag(42), want: 42},
+		{name: "unquoted", header: "3", want: NoMatch},
+		{name: "half quoted", header: `"3`, want: NoMatch},
+		{name: "lone quote", header: `"`, want: NoMatch},
+		{name: "empty tag", header: `""`, want: NoMatch},
+		{name: "not a number", header: `"abc"`, want: NoMatch},
+		{name: "zero", header: `"0"`, want: NoMatch},
+		{name: "negative", header: `"-2"`, want: NoMatch},
+		{name: "overflow", header: `"99999999999999999999"`, want: NoMatch},
+		{name: "list tag", header: `W/"1-2-3-abc"`, want: NoMatch},
+		{name: "several", header: `"1", "2"`, want: NoMatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			headers := map[string]string{}
+			if tt.header != "" {
+				headers[fiber.HeaderIfMatch] = tt.header
+			}
+
+			_, body := serve(t, "/", headers, func(c *fiber.Ctx) error {
+				return c.SendString(strconv.FormatInt(IfMatch(c), 10))
+			})
+
+			if got, _ := strconv.ParseInt(body, 10, 64); got != tt.want {
+				t.Errorf("IfMatch(%q) = %s, want %d", tt.header, body, tt.want)
+			}
+		})
+	}
+}
+
+func TestListETag(t *testing.T) {
+	stamp := pagination.Stamp{Count: 2, Versions: 5, LastID: 9}
+
+	etag := func(target string, stamp pagination.Stamp) string {
+		_, body := serve(t, target, nil, func(c *fiber.Ctx) error {
+			return c.SendString(ListETag(c, stamp))
+		})
+
+		return body
+	}
+
+	first := etag("/?limit=10", stamp)
+	if first[:2] != "W/" {
+		t.Errorf("ListETag = %s, want a weak tag", first)
+	}
+
+	if again := etag("/?limit=10", stamp); again != first {
+		t.Errorf("ListETag of the same list = %s, want %s", again, first)
+	}
+
+	if other := etag("/?limit=20", stamp); other == first {
+		t.Errorf("ListETag of another query = %s, want another tag", other)
+	}
+
+	stamp.Versions++
+	if changed := etag("/?limit=10", stamp); changed == first {
+		t.Errorf("ListETag of changed rows = %s, want another tag", changed)
+	}
+}
+
+func TestNotModified(t *testing.T) {
+	lastModified := time.Date(2024, 5, 1, 10, 0, 0, 500, time.UTC)
+	at := func(d time.Duration) string {
+		return lastModified.Add(d).Format(http.TimeFormat)
+	}
+
+	tests := []struct {
+		name         string
+		etag         string
+		lastModified time.Time
+		headers      map[string]string
+		want         bool
+	}{
+		{
+			name: "unconditional",
+			etag: `"3"`,
+			want: false,
+		},
+		{
+			name:    "same tag",
+			etag:    `"3"`,
+			headers: map[string]string{fiber.HeaderIfNoneMatch: `"3"`},
+			want:    true,
+		},
+		{
+			name:    "weak against strong",
+			etag:    `"3"`,
+			headers: map[string]string{fiber.HeaderIfNoneMatch: `W/"3"`},
+			want:    true,
+		},
+		{
+			name:    "one of several",
+			etag:    `W/"1-2-3-abc"`,
+			headers: map[string]string{fiber.HeaderIfNoneMatch: `"1", W/"1-2-3-abc"`},
+			want:    true,
+		},
+		{
+			name:    "any",
+			etag:    `"3"`,
+			headers: map[string]string{fiber.HeaderIfNoneMatch: "*"},
+			want:    true,
+		},
+		{
+			name:    "other tag",
+			etag:    `"3"`,
+			headers: map[string]string{fiber.HeaderIfNoneMatch: `"2"`},
+			want:    false,
+		},
+		{
+			name:         "tag wins over date",
+			etag:         `"3"`,
+			lastModified: lastModified,
+			headers: map[string]string{
+				fiber.HeaderIfNoneMatch:     `"2"`,
+				fiber.HeaderIfModifiedSince: at(time.Hour),
+			},
+			want: false,
+		},
+		{
+			name:         "unchanged since, below a second",
+			etag:         `"3"`,
+			lastModified: lastModified,
+			headers:      map[string]string{fiber.HeaderIfModifiedSince: at(0)},
+			want:         true,
+		},
+		{
+			name:         "changed since",
+			etag:         `"3"`,
+			lastModified: lastModified,
+			headers:      map[string]string{fiber.HeaderIfModifiedSince: at(-time.Second)},
+			want:         false,
+		},
+		{
+			name:         "bad date",
+			etag:         `"3"`,
+			lastModified: lastModified,
+			headers:      map[string]string{fiber.HeaderIfModifiedSince: "yesterday"},
+			want:         false,
+		},
+		{
+			name:    "date without a last modified",
+			etag:    `"3"`,
+			headers: map[string]string{fiber.HeaderIfModifiedSince: at(time.Hour)},
+			want:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, body := serve(t, "/", tt.headers, func(c *fiber.Ctx) error {
+				return c.SendString(strconv.FormatBool(NotModified(c, tt.etag, tt.lastModified)))
+			})
+
+			if got, _ := strconv.ParseBool(body); got != tt.want {
+				t.Errorf("NotModified = %s, want %t", body, tt.want)
+			}
+
+			if got := res.Header.Get(fiber.HeaderETag); got != tt.etag {
+				t.Errorf("ETag = %s, want %s", got, tt.etag)
+			}
+
+			want := ""
+			if !tt.lastModified.IsZero() {
+				want = tt.lastModified.Format(http.TimeFormat)
+			}
+
+			if got := res.Header.Get(fiber.HeaderLastModified); got != want {
+				t.Errorf("Last-Modified = %q, want %q", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package web
+
+import (
+	"strings"
+
+	"github.com/fahmiaz411/devcode/helper/constant"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// QueryList reads a query key given several times and/or as a comma separated list
+func QueryList(c *fiber.Ctx, key string) (res []string) {
+	for _, value := range c.Context().QueryArgs().PeekMulti(key) {
+		for _, item := range strings.Split(string(value), ",") {
+			if item = strings.TrimSpace(item); item != constant.EmptyString {
+				res = append(res, item)
+			}
+		}
+	}
+
+	return
+}
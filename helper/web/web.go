@@ -1,7 +1,10 @@
 package web
 
+import "github.com/fahmiaz411/devcode/helper/pagination"
+
 type BaseResponse struct {
-	Status  string `json:"status"`
-	Message string `json:"message"`
-	Data    any    `json:"data"`
-}
\ No newline at end of file
+	Status  string             `json:"status"`
+	Message string             `json:"message"`
+	Data    any                `json:"data"`
+	Paging  *pagination.Paging `json:"paging,omitempty"`
+}
@@ -6,9 +6,11 @@ import (
 	"strconv"
 
 	"github.com/fahmiaz411/devcode/helper/constant"
-	"github.com/fahmiaz411/devcode/helper/field"
+	"github.com/fahmiaz411/devcode/helper/failure"
 	"github.com/fahmiaz411/devcode/helper/message"
+	"github.com/fahmiaz411/devcode/helper/pagination"
 	"github.com/fahmiaz411/devcode/helper/params"
+	"github.com/fahmiaz411/devcode/helper/query"
 	"github.com/fahmiaz411/devcode/helper/web"
 	"github.com/fahmiaz411/devcode/modules/activity/domain"
 	"github.com/fahmiaz411/devcode/modules/activity/interfaces"
@@ -33,6 +35,13 @@ func NewRESTHandler(f fiber.Router, usecase interfaces.ActivityUsecase) {
 
 	f.Get("/activity-groups", handler.GetAll)
 
+	// Trash, registered before the :activityId routes
+	f.Get("/activity-groups/trash", handler.GetTrash)
+
+	f.Post(fmt.Sprintf("/activity-groups/trash/:%s/restore", params.ActivityId), handler.Restore)
+
+	f.Delete(fmt.Sprintf("/activity-groups/trash/:%s", params.ActivityId), handler.Purge)
+
 	f.Get(fmt.Sprintf("/activity-groups/:%s", params.ActivityId), handler.GetOne)
 }
 
@@ -40,19 +49,13 @@ func (h *RESTHandler) Create(c *fiber.Ctx) error {
 	req := domain.ActivityCreateRequest{}
 	c.BodyParser(&req)
 
-	if req.Title == constant.EmptyString {
-		return c.Status(http.StatusBadRequest).JSON(web.BaseResponse{
-			Status: http.StatusText(http.StatusBadRequest),
-			Message: message.CanotNull(field.Title),
-			Data: struct{}{},
-		})
-	}
-
-	res, err := h.Usecase.Create(c, req)
+	res, err := h.Usecase.Create(c.UserContext(), req)
 	if err != nil {
-		return nil
+		return err
 	}
 
+	c.Set(fiber.HeaderETag, web.ETag(res.Version))
+
 	return c.Status(http.StatusCreated).JSON(web.BaseResponse{
 		Status: message.Success,
 		Message: message.Success,
@@ -63,31 +66,22 @@ func (h *RESTHandler) Create(c *fiber.Ctx) error {
 func (h *RESTHandler) Update(c *fiber.Ctx) error {
 	activityId, err := strconv.ParseInt(c.Params(params.ActivityId), 10, 64)
 	if err != nil {
-		return c.Status(http.StatusBadRequest).JSON(web.BaseResponse{
-			Status: http.StatusText(http.StatusBadRequest),
-			Message: message.InvalidId(domain.Model),
-			Data: struct{}{},
-		})
+		return failure.Validation(message.InvalidId(domain.Model))
 	}
 
 	req := domain.ActivityUpdateRequest{
 		ID: activityId,
+		Version: web.IfMatch(c),
 	}
 	c.BodyParser(&req)
 
-	if req.Title == constant.EmptyString {
-		return c.Status(http.StatusBadRequest).JSON(web.BaseResponse{
-			Status: http.StatusText(http.StatusBadRequest),
-			Message: message.CanotNull(field.Title),
-			Data: struct{}{},
-		})
-	}
-
-	res, err := h.Usecase.Update(c, req)
+	res, err := h.Usecase.Update(c.UserContext(), req)
 	if err != nil {
-		return nil
+		return err
 	}
 
+	c.Set(fiber.HeaderETag, web.ETag(res.Version))
+
 	return c.JSON(web.BaseResponse{
 		Status: message.Success,
 		Message: message.Success,
@@ -98,20 +92,63 @@ func (h *RESTHandler) Update(c *fiber.Ctx) error {
 func (h *RESTHandler) Delete(c *fiber.Ctx) error {
 	activityId, err := strconv.ParseInt(c.Params(params.ActivityId), 10, 64)
 	if err != nil {
-		return c.Status(http.StatusBadRequest).JSON(web.BaseResponse{
-			Status: http.StatusText(http.StatusBadRequest),
-			Message: message.InvalidId(domain.Model),
-			Data: struct{}{},
-		})
+		return failure.Validation(message.InvalidId(domain.Model))
 	}
 
 	req := domain.ActivityDeleteRequest{
 		ID: activityId,
+		Version: web.IfMatch(c),
+	}
+
+	res, err := h.Usecase.Delete(c.UserContext(), req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(web.BaseResponse{
+		Status: message.Success,
+		Message: message.Success,
+		Data: res,
+	})
+}
+
+func (h *RESTHandler) Restore(c *fiber.Ctx) error {
+	activityId, err := strconv.ParseInt(c.Params(params.ActivityId), 10, 64)
+	if err != nil {
+		return failure.Validation(message.InvalidId(domain.Model))
+	}
+
+	req := domain.ActivityRestoreRequest{
+		ID: activityId,
 	}
 
-	res, err := h.Usecase.Delete(c, req)
+	res, err := h.Usecase.Restore(c.UserContext(), req)
 	if err != nil {
-		return nil
+		return err
+	}
+
+	c.Set(fiber.HeaderETag, web.ETag(res.Version))
+
+	return c.JSON(web.BaseResponse{
+		Status: message.Success,
+		Message: message.Success,
+		Data: res,
+	})
+}
+
+func (h *RESTHandler) Purge(c *fiber.Ctx) error {
+	activityId, err := strconv.ParseInt(c.Params(params.ActivityId), 10, 64)
+	if err != nil {
+		return failure.Validation(message.InvalidId(domain.Model))
+	}
+
+	req := domain.ActivityPurgeRequest{
+		ID: activityId,
+	}
+
+	res, err := h.Usecase.Purge(c.UserContext(), req)
+	if err != nil {
+		return err
 	}
 
 	return c.JSON(web.BaseResponse{
@@ -122,38 +159,73 @@ func (h *RESTHandler) Delete(c *fiber.Ctx) error {
 }
 
 func (h *RESTHandler) GetAll(c *fiber.Ctx) error {
+	return h.list(c, false)
+}
+
+func (h *RESTHandler) GetTrash(c *fiber.Ctx) error {
+	return h.list(c, true)
+}
+
+// list serves GetAll and GetTrash, they only differ in the rows listed
+func (h *RESTHandler) list(c *fiber.Ctx, trashed bool) error {
 	req := domain.ActivityGetAllRequest{		
+		Trashed: trashed,
+		Request: pagination.Request{
+			Limit: c.QueryInt(query.Limit),
+			Offset: c.QueryInt(query.Offset),
+			Cursor: c.Query(query.Cursor),
+			Sort: c.Query(query.Sort),
+			Order: c.Query(query.Order),
+		},
+		Include: web.QueryList(c, query.Include),
 	}
 
-	res, err := h.Usecase.GetAll(c, req)
+	// Polling clients get a 304 without the list being read, included todos change without their group so they always get the list
+	if len(req.Include) == constant.ZeroValue {
+		stamp, err := h.Usecase.GetAllStamp(c.UserContext(), req)
+		if err != nil {
+			return err
+		}
+
+		if web.NotModified(c, web.ListETag(c, stamp.Stamp), stamp.LastModified) {
+			return c.SendStatus(http.StatusNotModified)
+		}
+	}
+
+	res, err := h.Usecase.GetAll(c.UserContext(), req)
 	if err != nil {
-		return nil
+		return err
 	}
 
 	return c.JSON(web.BaseResponse{
 		Status: message.Success,
 		Message: message.Success,
-		Data: res,
+		Data: res.Activities,
+		Paging: &res.Paging,
 	})
 }
 
 func (h *RESTHandler) GetOne(c *fiber.Ctx) error {
 	activityId, err := strconv.ParseInt(c.Params(params.ActivityId), 10, 64)
 	if err != nil {
-		return c.Status(http.StatusBadRequest).JSON(web.BaseResponse{
-			Status: http.StatusText(http.StatusBadRequest),
-			Message: message.InvalidId(domain.Model),
-			Data: struct{}{},
-		})
+		return failure.Validation(message.InvalidId(domain.Model))
 	}
 
 	req := domain.ActivityGetOneRequest{
 		ID: activityId,
+		Include: web.QueryList(c, query.Include),
 	}
 
-	res, err := h.Usecase.GetOne(c, req)
+	res, err := h.Usecase.GetOne(c.UserContext(), req)
 	if err != nil {
-		return nil
+		return err
+	}
+
+	// The ETag stays the version of the group for If-Match, a 304 would hide its todos changing
+	if len(req.Include) != constant.ZeroValue {
+		c.Set(fiber.HeaderETag, web.ETag(res.Version))
+	} else if web.NotModified(c, web.ETag(res.Version), res.UpdatedAt) {
+		return c.SendStatus(http.StatusNotModified)
 	}
 
 	return c.JSON(web.BaseResponse{
@@ -161,4 +233,4 @@ func (h *RESTHandler) GetOne(c *fiber.Ctx) error {
 		Message: message.Success,
 		Data: res,
 	})
-}
\ No newline at end of file
+}
@@ -1,11 +1,87 @@
 package domain
 
-import "time"
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/fahmiaz411/devcode/helper/pagination"
+	todoDomain "github.com/fahmiaz411/devcode/modules/todo/domain"
+)
 
 const (
 	Model = "Activity"
 )
 
+// Cascade, what deleting an activity group does to its todos
+const (
+	CascadeSoftDelete = "soft-delete"
+	CascadeHardDelete = "hard-delete"
+	CascadeRestrict = "restrict"
+
+	CascadeDefault = CascadeSoftDelete
+)
+
+var (
+	CascadeAllList = []string{
+		CascadeSoftDelete,
+		CascadeHardDelete,
+		CascadeRestrict,
+	}
+)
+
+// Lock, what a Get One inside a unit of work holds on the activity group until it ends
+const (
+	// LockShare keeps the group from being deleted, while todos are put in it
+	LockShare = "share"
+	// LockUpdate keeps todos from being put in the group, while it is deleted
+	LockUpdate = "update"
+)
+
+// Include, what an activity group response embeds of its todos
+const (
+	IncludeTodoItems = "todo_items"
+	IncludeCounts = "counts"
+)
+
+var (
+	IncludeAllList = []string{
+		IncludeTodoItems,
+		IncludeCounts,
+	}
+)
+
+// Version, bumped on every change of an activity
+const (
+	VersionDefault = 1
+)
+
+var (
+	// ErrVersionConflict is returned by a write when the activity is no longer at the expected version
+	ErrVersionConflict = errors.New("activity version conflict")
+
+	// ErrNotPurged is returned by a purge when the activity is no longer in the trash as deleted at the given time
+	ErrNotPurged = errors.New("activity not purged")
+)
+
+// Sort
+const (
+	SortID = "id"
+	SortTitle = "title"
+	SortCreatedAt = "createdAt"
+	SortUpdatedAt = "updatedAt"
+)
+
+var (
+	// SortAllList starts with the default sort
+	SortAllList = []string{
+		SortID,
+		SortTitle,
+		SortCreatedAt,
+		SortUpdatedAt,
+	}
+)
+
 type Activity struct {
 	ID        int64     `json:"id"`
 	Title     string    `json:"title"`
@@ -13,6 +89,37 @@ type Activity struct {
 	CreatedAt time.Time `json:"createdAt"`
 	UpdatedAt time.Time `json:"updatedAt"`
 	DeletedAt *time.Time `json:"deletedAt"`
+	Version   int64     `json:"version"`
+
+	// Left out unless included, they are the live todos of the group
+	TodoItems *[]todoDomain.Todo `json:"todo_items,omitempty"`
+	Counts    *todoDomain.TodoCounts `json:"counts,omitempty"`
+}
+
+// SortValue is the cursor value of the activity for sort
+func (a Activity) SortValue(sort string) string {
+	switch sort {
+	case SortTitle:
+		return a.Title
+	case SortCreatedAt:
+		return a.CreatedAt.Format(time.RFC3339Nano)
+	case SortUpdatedAt:
+		return a.UpdatedAt.Format(time.RFC3339Nano)
+	default:
+		return strconv.FormatInt(a.ID, 10)
+	}
+}
+
+// ParseSortValue converts a cursor value back to the type of the sort field
+func ParseSortValue(sort string, value string) (any, error) {
+	switch sort {
+	case SortTitle:
+		return value, nil
+	case SortCreatedAt, SortUpdatedAt:
+		return time.Parse(time.RFC3339Nano, value)
+	default:
+		return strconv.ParseInt(value, 10, 64)
+	}
 }
 
 // Create
@@ -32,6 +139,9 @@ type ActivityUpdateRequest struct {
 	ID int64 `json:"-"`
 	Title string `json:"title"`
 	UpdatedAt time.Time `json:"-"`
+
+	// Version expected by the write, from If-Match or as read by the usecase
+	Version int64 `json:"-"`
 }
 
 type ActivityUpdateResponse struct {
@@ -42,22 +152,99 @@ type ActivityUpdateResponse struct {
 
 type ActivityDeleteRequest struct {
 	ID int64
+	Version int64
+	DeletedAt time.Time
 }
 
 type ActivityDeleteResponse struct {
 }
 
+// Restore
+
+type ActivityRestoreRequest struct {
+	ID int64
+	UpdatedAt time.Time
+
+	// Version expected by the write, as read from the trash by the usecase
+	Version int64
+}
+
+type ActivityRestoreResponse struct {
+	Activity
+}
+
+// Purge
+
+type ActivityPurgeRequest struct {
+	ID int64
+	// DeletedAt guards the purge, an activity restored or deleted again since it was read stays
+	DeletedAt time.Time
+}
+
+type ActivityPurgeResponse struct {
+}
+
+// Purge Expired
+
+type ActivityPurgeExpiredRequest struct {
+	// Retention is how long a deleted activity group stays in the trash
+	Retention time.Duration
+	// Batch is how many activity groups each unit of work purges
+	Batch int
+	DryRun bool
+}
+
+type ActivityPurgeExpiredResponse struct {
+	Activities int64 `json:"activities"`
+	Todos int64 `json:"todos"`
+}
+
+// Get Expired, the activity groups deleted before a time, a page of them by ID
+
+type ActivityGetExpiredRequest struct {
+	DeletedBefore time.Time
+	AfterID int64
+	Limit int
+	// Lock is LockUpdate for a purge, the groups cannot be restored before they are gone, or empty for a dry run
+	Lock string
+}
+
+type ActivityGetExpiredResponse struct {
+	// Activities have their ID and DeletedAt only
+	Activities []Activity
+}
+
 // Get All
 
 type ActivityGetAllRequest struct {
+	// Trashed lists the deleted activities instead of the live ones
+	Trashed bool `json:"-"`
+
+	// Include is some of IncludeAllList
+	Include []string `json:"-"`
+
+	pagination.Request
 }
 
-type ActivityGetAllResponse []Activity
+type ActivityGetAllResponse struct {
+	Activities []Activity
+	Paging     pagination.Paging
+}
+
+// Get All Stamp, whether a Get All changed without running it
+
+type ActivityGetAllStampResponse struct {
+	pagination.Stamp
+}
 
 // Get One
 
 type ActivityGetOneRequest struct {
 	ID int64
+	Trashed bool
+	Include []string
+	// Lock is LockShare, LockUpdate or empty for a plain read
+	Lock string
 }
 
 type ActivityGetOneResponse struct {
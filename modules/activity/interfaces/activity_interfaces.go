@@ -2,24 +2,44 @@ package interfaces
 
 import (
 	"github.com/fahmiaz411/devcode/modules/activity/domain"
+	todoDomain "github.com/fahmiaz411/devcode/modules/todo/domain"
 
 	"context"
-
-	"github.com/gofiber/fiber/v2"
 )
 
 type ActivityUsecase interface {
-	Create(c *fiber.Ctx, req domain.ActivityCreateRequest) (res domain.ActivityCreateResponse, err error)
-	Update(c *fiber.Ctx, req domain.ActivityUpdateRequest) (res domain.ActivityUpdateResponse, err error)
-	Delete(c *fiber.Ctx, req domain.ActivityDeleteRequest) (res domain.ActivityDeleteResponse, err error)
-	GetAll(c *fiber.Ctx, req domain.ActivityGetAllRequest) (res domain.ActivityGetAllResponse, err error)
-	GetOne(c *fiber.Ctx, req domain.ActivityGetOneRequest) (res domain.ActivityGetOneResponse, err error)
+	Create(ctx context.Context, req domain.ActivityCreateRequest) (res domain.ActivityCreateResponse, err error)
+	Update(ctx context.Context, req domain.ActivityUpdateRequest) (res domain.ActivityUpdateResponse, err error)
+	Delete(ctx context.Context, req domain.ActivityDeleteRequest) (res domain.ActivityDeleteResponse, err error)
+	Restore(ctx context.Context, req domain.ActivityRestoreRequest) (res domain.ActivityRestoreResponse, err error)
+	Purge(ctx context.Context, req domain.ActivityPurgeRequest) (res domain.ActivityPurgeResponse, err error)
+	PurgeExpired(ctx context.Context, req domain.ActivityPurgeExpiredRequest) (res domain.ActivityPurgeExpiredResponse, err error)
+	GetAll(ctx context.Context, req domain.ActivityGetAllRequest) (res domain.ActivityGetAllResponse, err error)
+	GetAllStamp(ctx context.Context, req domain.ActivityGetAllRequest) (res domain.ActivityGetAllStampResponse, err error)
+	GetOne(ctx context.Context, req domain.ActivityGetOneRequest) (res domain.ActivityGetOneResponse, err error)
+	Exists(ctx context.Context, id int64) (exists bool, err error)
+	Email(ctx context.Context, id int64) (email string, err error)
 }
 
-type ActivityRepoMysql interface {
+type ActivityRepository interface {
 	Create(ctx context.Context, req domain.ActivityCreateRequest) (res domain.ActivityCreateResponse, err error)
 	Update(ctx context.Context, req domain.ActivityUpdateRequest) (res domain.ActivityUpdateResponse, err error)
 	Delete(ctx context.Context, req domain.ActivityDeleteRequest) (res domain.ActivityDeleteResponse, err error)
+	Restore(ctx context.Context, req domain.ActivityRestoreRequest) (res domain.ActivityRestoreResponse, err error)
+	Purge(ctx context.Context, req domain.ActivityPurgeRequest) (res domain.ActivityPurgeResponse, err error)
+	GetExpired(ctx context.Context, req domain.ActivityGetExpiredRequest) (res domain.ActivityGetExpiredResponse, err error)
 	GetAll(ctx context.Context, req domain.ActivityGetAllRequest) (res domain.ActivityGetAllResponse, err error)
+	GetAllStamp(ctx context.Context, req domain.ActivityGetAllRequest) (res domain.ActivityGetAllStampResponse, err error)
 	GetOne(ctx context.Context, req domain.ActivityGetOneRequest) (res domain.ActivityGetOneResponse, err error)
+}
+
+// TodoStore is the part of the todo repository the activity module works with,
+// to take the todos of a group along with it and to embed them in its responses
+type TodoStore interface {
+	GetAll(ctx context.Context, req todoDomain.TodoGetAllRequest) (res todoDomain.TodoGetAllResponse, err error)
+	GroupCount(ctx context.Context, req todoDomain.TodoGroupCountRequest) (res todoDomain.TodoGroupCountResponse, err error)
+	CountByActivity(ctx context.Context, req todoDomain.TodoCountByActivityRequest) (res todoDomain.TodoCountByActivityResponse, err error)
+	DeleteByActivity(ctx context.Context, req todoDomain.TodoDeleteByActivityRequest) (res todoDomain.TodoDeleteByActivityResponse, err error)
+	RestoreByActivity(ctx context.Context, req todoDomain.TodoRestoreByActivityRequest) (res todoDomain.TodoRestoreByActivityResponse, err error)
+	PurgeByActivity(ctx context.Context, req todoDomain.TodoPurgeByActivityRequest) (res todoDomain.TodoPurgeByActivityResponse, err error)
 }
\ No newline at end of file
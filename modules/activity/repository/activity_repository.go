@@ -1,19 +1,32 @@
 package repository
 
 import (
-	"database/sql"
-
+	"github.com/fahmiaz411/devcode/config/database"
 	"github.com/fahmiaz411/devcode/modules/activity/interfaces"
-	"github.com/fahmiaz411/devcode/modules/activity/repository/mysql"
+	"github.com/fahmiaz411/devcode/modules/activity/repository/memory"
+	"github.com/fahmiaz411/devcode/modules/activity/repository/sqlstore"
 )
 
 type Repository struct {
-	MySQL interfaces.ActivityRepoMysql
+	Store interfaces.ActivityRepository
+
+	// Transactor runs a unit of work across the stores of every module
+	Transactor database.Transactor
 }
 
-// NewRepository constructor
-func NewRepository(mysqlConn *sql.DB) *Repository {
+// NewRepository constructor, the SQL drivers share a store speaking their dialect
+func NewRepository(db *database.Database) *Repository {
+	var store interfaces.ActivityRepository
+
+	switch db.Driver {
+	case database.DriverMemory:
+		store = memory.NewMemoryRepository(db.Memory)
+	default:
+		store = sqlstore.NewSqlRepository(db.Conn, database.Dialect(db.Driver))
+	}
+
 	return &Repository{
-		MySQL: mysql.NewMysqlRepository(mysqlConn),
+		Store:      store,
+		Transactor: database.NewTransactor(db),
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,194 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/fahmiaz411/devcode/config/database"
+	"github.com/fahmiaz411/devcode/helper/pagination"
+	"github.com/fahmiaz411/devcode/modules/activity/domain"
+	"github.com/fahmiaz411/devcode/modules/activity/interfaces"
+)
+
+const (
+	table = "activities"
+)
+
+type MemoryRepository struct {
+	DB *database.MemoryDB
+}
+
+func NewMemoryRepository(DB *database.MemoryDB) interfaces.ActivityRepository {
+	return &MemoryRepository{
+		DB: DB,
+	}
+}
+
+func (m *MemoryRepository) Create(ctx context.Context, req domain.ActivityCreateRequest) (res domain.ActivityCreateResponse, err error) {
+	now := time.Now().UTC()
+
+	defer m.DB.Write(ctx)()
+
+	activities := m.DB.Table(table)
+
+	res.ID = activities.NextID()
+	res.Title = req.Title
+	res.Email = req.Email
+	res.CreatedAt = now
+	res.UpdatedAt = now
+	res.Version = domain.VersionDefault
+
+	activities.Set(res.ID, res.Activity)
+
+	return
+}
+
+func (m *MemoryRepository) Update(ctx context.Context, req domain.ActivityUpdateRequest) (res domain.ActivityUpdateResponse, err error) {
+	defer m.DB.Write(ctx)()
+
+	activities := m.DB.Table(table)
+
+	row, ok := activities.Rows[req.ID]
+	if !ok || row.(domain.Activity).Version != req.Version {
+		err = domain.ErrVersionConflict
+		return
+	}
+
+	act := row.(domain.Activity)
+	act.Title = req.Title
+	act.UpdatedAt = req.UpdatedAt
+	act.Version++
+	activities.Set(req.ID, act)
+
+	return
+}
+
+func (m *MemoryRepository) Delete(ctx context.Context, req domain.ActivityDeleteRequest) (res domain.ActivityDeleteResponse, err error) {
+	defer m.DB.Write(ctx)()
+
+	activities := m.DB.Table(table)
+
+	row, ok := activities.Rows[req.ID]
+	if !ok || row.(domain.Activity).Version != req.Version {
+		err = domain.ErrVersionConflict
+		return
+	}
+
+	act := row.(domain.Activity)
+	act.DeletedAt = &req.DeletedAt
+	act.UpdatedAt = req.DeletedAt
+	act.Version++
+	activities.Set(req.ID, act)
+
+	return
+}
+
+func (m *MemoryRepository) Restore(ctx context.Context, req domain.ActivityRestoreRequest) (res domain.ActivityRestoreResponse, err error) {
+	defer m.DB.Write(ctx)()
+
+	activities := m.DB.Table(table)
+
+	row, ok := activities.Rows[req.ID]
+	if !ok || row.(domain.Activity).Version != req.Version || row.(domain.Activity).DeletedAt == nil {
+		err = domain.ErrVersionConflict
+		return
+	}
+
+	act := row.(domain.Activity)
+	act.DeletedAt = nil
+	act.UpdatedAt = req.UpdatedAt
+	act.Version++
+	activities.Set(req.ID, act)
+
+	return
+}
+
+func (m *MemoryRepository) Purge(ctx context.Context, req domain.ActivityPurgeRequest) (res domain.ActivityPurgeResponse, err error) {
+	defer m.DB.Write(ctx)()
+
+	activities := m.DB.Table(table)
+
+	row, ok := activities.Rows[req.ID]
+	if !ok || row.(domain.Activity).DeletedAt == nil || !row.(domain.Activity).DeletedAt.Equal(req.DeletedAt) {
+		err = domain.ErrNotPurged
+		return
+	}
+
+	activities.Delete(req.ID)
+
+	return
+}
+
+func (m *MemoryRepository) GetExpired(ctx context.Context, req domain.ActivityGetExpiredRequest) (res domain.ActivityGetExpiredResponse, err error) {
+	defer m.DB.Read(ctx)()
+
+	res.Activities = []domain.Activity{}
+	for id, row := range m.DB.Table(table).Rows {
+		if act := row.(domain.Activity); id > req.AfterID && act.DeletedAt != nil && act.DeletedAt.Before(req.DeletedBefore) {
+			res.Activities = append(res.Activities, domain.Activity{ID: id, DeletedAt: act.DeletedAt})
+		}
+	}
+
+	sort.Slice(res.Activities, func(i, j int) bool {
+		return res.Activities[i].ID < res.Activities[j].ID
+	})
+
+	if len(res.Activities) > req.Limit {
+		res.Activities = res.Activities[:req.Limit]
+	}
+
+	return
+}
+
+func (m *MemoryRepository) GetAll(ctx context.Context, req domain.ActivityGetAllRequest) (res domain.ActivityGetAllResponse, err error) {
+	defer m.DB.Read(ctx)()
+
+	activities := []domain.Activity{}
+	for _, row := range m.DB.Table(table).Rows {
+		if act := row.(domain.Activity); (act.DeletedAt != nil) == req.Trashed {
+			activities = append(activities, act)
+		}
+	}
+
+	res.Activities, res.Paging, err = pagination.Paginate(req.Request, activities,
+		func(act domain.Activity) int64 { return act.ID },
+		func(act domain.Activity) string { return act.SortValue(req.Sort) },
+		domain.ParseSortValue,
+	)
+
+	return
+}
+
+func (m *MemoryRepository) GetAllStamp(ctx context.Context, req domain.ActivityGetAllRequest) (res domain.ActivityGetAllStampResponse, err error) {
+	defer m.DB.Read(ctx)()
+
+	// Listed rows are counted, the ones that left the list still date it
+	for _, row := range m.DB.Table(table).Rows {
+		act := row.(domain.Activity)
+
+		if act.UpdatedAt.After(res.LastModified) {
+			res.LastModified = act.UpdatedAt
+		}
+
+		if (act.DeletedAt != nil) == req.Trashed {
+			res.Count++
+			res.Versions += act.Version
+			if act.ID > res.LastID {
+				res.LastID = act.ID
+			}
+		}
+	}
+
+	return
+}
+
+func (m *MemoryRepository) GetOne(ctx context.Context, req domain.ActivityGetOneRequest) (res domain.ActivityGetOneResponse, err error) {
+	defer m.DB.Read(ctx)()
+
+	if row, ok := m.DB.Table(table).Rows[req.ID]; ok && (row.(domain.Activity).DeletedAt != nil) == req.Trashed {
+		res.Activity = row.(domain.Activity)
+	}
+
+	return
+}
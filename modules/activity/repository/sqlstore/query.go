@@ -0,0 +1,37 @@
+package sqlstore
+
+import (
+	"strings"
+
+	"github.com/fahmiaz411/devcode/config/database"
+	"github.com/fahmiaz411/devcode/modules/activity/domain"
+)
+
+func where(conditions []string) string {
+	if len(conditions) == 0 {
+		return ""
+	}
+
+	return "WHERE " + strings.Join(conditions, " AND ")
+}
+
+// trash keeps either the live or the deleted rows
+func trash(trashed bool) string {
+	if trashed {
+		return "deleted_at IS NOT NULL"
+	}
+
+	return "deleted_at IS NULL"
+}
+
+// lock is the locking clause of a Get One, if any
+func lock(dialect database.Dialect, lock string) string {
+	switch lock {
+	case domain.LockShare:
+		return dialect.Lock(false)
+	case domain.LockUpdate:
+		return dialect.Lock(true)
+	}
+
+	return ""
+}
@@ -0,0 +1,21 @@
+package sqlstore
+
+import (
+	"github.com/fahmiaz411/devcode/config/database"
+	"github.com/fahmiaz411/devcode/modules/activity/domain"
+)
+
+var sortColumns = map[string]string{
+	domain.SortID:        "activity_id",
+	domain.SortCreatedAt: "created_at",
+	domain.SortUpdatedAt: "updated_at",
+}
+
+// sortColumn is the column a sort orders by, titles compared byte by byte
+func sortColumn(dialect database.Dialect, sort string) string {
+	if sort == domain.SortTitle {
+		return dialect.Binary("title")
+	}
+
+	return sortColumns[sort]
+}
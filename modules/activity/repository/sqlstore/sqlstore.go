@@ -0,0 +1,376 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/fahmiaz411/devcode/config/database"
+	"github.com/fahmiaz411/devcode/helper/constant"
+	"github.com/fahmiaz411/devcode/modules/activity/domain"
+	"github.com/fahmiaz411/devcode/modules/activity/interfaces"
+)
+
+type SqlRepository struct {
+	Conn *sql.DB
+	Dialect database.Dialect
+}
+
+func NewSqlRepository(Conn *sql.DB, Dialect database.Dialect) interfaces.ActivityRepository {
+	return &SqlRepository{
+		Conn: Conn,
+		Dialect: Dialect,
+	}
+}
+
+// conn is the transaction of the unit of work ctx runs in, if any
+func (m *SqlRepository) conn(ctx context.Context) database.Executor {
+	return m.Dialect.Conn(ctx, m.Conn)
+}
+
+func (m *SqlRepository) Create(ctx context.Context, req domain.ActivityCreateRequest) (res domain.ActivityCreateResponse, err error) {
+	now := time.Now().UTC()
+
+	// Email nullable
+	var email sql.NullString
+	if req.Email != constant.EmptyString {
+		email.Valid = true
+		email.String = req.Email
+	}
+
+	res.ID, err = m.Dialect.Insert(ctx, m.conn(ctx), `
+		INSERT INTO activities (
+			title,
+			email,
+			created_at,
+			updated_at
+		) VALUES (
+			?,
+			?,
+			?,
+			?
+		)
+	`, "activity_id", req.Title, email, now, now)
+	if err != nil {
+		return
+	}
+
+	res.Title = req.Title
+	res.Email = req.Email
+	res.CreatedAt = now
+	res.UpdatedAt = now
+	res.Version = domain.VersionDefault
+	
+	return
+}
+
+func (m *SqlRepository) Update(ctx context.Context, req domain.ActivityUpdateRequest) (res domain.ActivityUpdateResponse, err error) {
+	var stmt *sql.Stmt
+	stmt, err = m.conn(ctx).PrepareContext(ctx, `
+		UPDATE activities 
+		SET
+			title = ?,
+			updated_at = ?,
+			version = version + 1
+		WHERE activity_id = ? AND version = ?
+	`)
+	if err != nil {
+		return
+	}
+	defer stmt.Close()
+
+	var result sql.Result
+	result, err = stmt.ExecContext(ctx, req.Title, req.UpdatedAt, req.ID, req.Version)
+	if err != nil {
+		return
+	}
+
+	if affected, _ := result.RowsAffected(); affected == int64(constant.ZeroValue) {
+		err = domain.ErrVersionConflict
+	}
+	
+	return
+}
+
+func (m *SqlRepository) Delete(ctx context.Context, req domain.ActivityDeleteRequest) (res domain.ActivityDeleteResponse, err error) {
+	var stmt *sql.Stmt
+	stmt, err = m.conn(ctx).PrepareContext(ctx, `
+		UPDATE activities 
+		SET
+			deleted_at = ?,
+			updated_at = ?,
+			version = version + 1
+		WHERE activity_id = ? AND version = ?
+	`)
+	if err != nil {
+		return
+	}
+	defer stmt.Close()
+
+	var result sql.Result
+	result, err = stmt.ExecContext(ctx, req.DeletedAt, req.DeletedAt, req.ID, req.Version)
+	if err != nil {
+		return
+	}
+
+	if affected, _ := result.RowsAffected(); affected == int64(constant.ZeroValue) {
+		err = domain.ErrVersionConflict
+	}
+	
+	return
+}
+
+func (m *SqlRepository) Restore(ctx context.Context, req domain.ActivityRestoreRequest) (res domain.ActivityRestoreResponse, err error) {
+	var stmt *sql.Stmt
+	stmt, err = m.conn(ctx).PrepareContext(ctx, `
+		UPDATE activities SET deleted_at = NULL, updated_at = ?, version = version + 1 WHERE activity_id = ? AND version = ? AND deleted_at IS NOT NULL
+	`)
+	if err != nil {
+		return
+	}
+	defer stmt.Close()
+
+	var result sql.Result
+	result, err = stmt.ExecContext(ctx, req.UpdatedAt, req.ID, req.Version)
+	if err != nil {
+		return
+	}
+
+	if affected, _ := result.RowsAffected(); affected == int64(constant.ZeroValue) {
+		err = domain.ErrVersionConflict
+	}
+	
+	return
+}
+
+func (m *SqlRepository) Purge(ctx context.Context, req domain.ActivityPurgeRequest) (res domain.ActivityPurgeResponse, err error) {
+	var stmt *sql.Stmt
+	stmt, err = m.conn(ctx).PrepareContext(ctx, `
+		DELETE FROM activities WHERE activity_id = ? AND deleted_at = ?
+	`)
+	if err != nil {
+		return
+	}
+	defer stmt.Close()
+
+	var result sql.Result
+	result, err = stmt.ExecContext(ctx, req.ID, req.DeletedAt)
+	if err != nil {
+		return
+	}
+
+	if affected, _ := result.RowsAffected(); affected == int64(constant.ZeroValue) {
+		err = domain.ErrNotPurged
+	}
+	
+	return
+}
+
+func (m *SqlRepository) GetExpired(ctx context.Context, req domain.ActivityGetExpiredRequest) (res domain.ActivityGetExpiredResponse, err error) {
+	res.Activities = []domain.Activity{}
+
+	var rows *sql.Rows
+	rows, err = m.conn(ctx).QueryContext(ctx, fmt.Sprintf(`
+		SELECT activity_id, deleted_at FROM activities WHERE deleted_at < ? AND activity_id > ? ORDER BY activity_id LIMIT ?%s
+	`, lock(m.Dialect, req.Lock)), req.DeletedBefore, req.AfterID, req.Limit)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var act domain.Activity
+		if err = rows.Scan(&act.ID, &act.DeletedAt); err != nil {
+			return
+		}
+
+		res.Activities = append(res.Activities, act)
+	}
+
+	err = rows.Err()
+	
+	return
+}
+
+func (m *SqlRepository) GetAll(ctx context.Context, req domain.ActivityGetAllRequest) (res domain.ActivityGetAllResponse, err error) {
+	res.Activities = []domain.Activity{}
+	res.Paging.Limit = req.Limit
+	res.Paging.Offset = req.Offset
+
+	conditions := []string{trash(req.Trashed)}
+	values := []any{}
+
+	// Total
+	if err = m.conn(ctx).QueryRowContext(ctx, fmt.Sprintf(`
+		SELECT COUNT(*) FROM activities %s
+	`, where(conditions)), values...).Scan(&res.Paging.Total); err != nil {
+		return
+	}
+
+	column := sortColumn(m.Dialect, req.Sort)
+
+	// Keyset
+	if req.After != nil {
+		var after any
+		after, err = domain.ParseSortValue(req.Sort, req.After.Value)
+		if err != nil {
+			return
+		}
+
+		conditions = append(conditions, req.Keyset(column, "activity_id"))
+		values = append(values, after, after, req.After.ID)
+	}
+
+	var queryLimit string
+	if req.Limit != constant.ZeroValue {
+		// One more row tells whether there is a next page
+		queryLimit = "LIMIT ? OFFSET ?"
+		values = append(values, req.Limit+1, req.Offset)
+	}
+	
+	var stmt *sql.Stmt
+	stmt, err = m.conn(ctx).PrepareContext(ctx, fmt.Sprintf(`
+		SELECT 
+			activity_id,
+			title,
+			email,
+			created_at,
+			updated_at,
+			deleted_at,
+			version
+		FROM activities
+		%s
+		ORDER BY %s
+		%s
+	`, where(conditions), req.OrderBy(column, "activity_id"), queryLimit))
+	if err != nil {
+		return
+	}
+	defer stmt.Close()
+
+	var rows *sql.Rows
+	rows, err = stmt.QueryContext(ctx, values...)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var act domain.Activity
+
+		var (
+			email sql.NullString
+			deletedAt sql.NullTime
+		)
+
+		if err = rows.Scan(
+			&act.ID,
+			&act.Title,
+			&email,
+			&act.CreatedAt,
+			&act.UpdatedAt,
+			&deletedAt,
+			&act.Version,
+		); err != nil {
+			return
+		}
+
+		if email.Valid {
+			act.Email = email.String
+		}
+
+		if deletedAt.Valid {
+			act.DeletedAt = &deletedAt.Time
+		}
+
+		res.Activities = append(res.Activities, act)
+	}
+
+	if req.Limit != constant.ZeroValue && len(res.Activities) > req.Limit {
+		res.Activities = res.Activities[:req.Limit]
+
+		last := res.Activities[req.Limit-1]
+		res.Paging.NextCursor = req.Next(last.SortValue(req.Sort), last.ID)
+	}
+
+	return
+}
+
+func (m *SqlRepository) GetAllStamp(ctx context.Context, req domain.ActivityGetAllRequest) (res domain.ActivityGetAllStampResponse, err error) {
+	var lastModified database.NullTime
+
+	// Listed rows are counted, the filtered ones that left the list still date it
+	if err = m.conn(ctx).QueryRowContext(ctx, fmt.Sprintf(`
+		SELECT
+			COUNT(CASE WHEN %[1]s THEN 1 END),
+			COALESCE(SUM(CASE WHEN %[1]s THEN version END), 0),
+			COALESCE(MAX(CASE WHEN %[1]s THEN activity_id END), 0),
+			MAX(updated_at)
+		FROM activities
+	`, trash(req.Trashed))).Scan(&res.Count, &res.Versions, &res.LastID, &lastModified); err != nil {
+		return
+	}
+
+	if lastModified.Valid {
+		res.LastModified = lastModified.Time
+	}
+
+	return
+}
+
+func (m *SqlRepository) GetOne(ctx context.Context, req domain.ActivityGetOneRequest) (res domain.ActivityGetOneResponse, err error) {
+	var stmt *sql.Stmt
+	stmt, err = m.conn(ctx).PrepareContext(ctx, fmt.Sprintf(`
+		SELECT 
+			activity_id,
+			title,
+			email,
+			created_at,
+			updated_at,
+			deleted_at,
+			version
+		FROM activities
+		WHERE activity_id = ? AND %s%s
+	`, trash(req.Trashed), lock(m.Dialect, req.Lock)))
+	if err != nil {
+		return
+	}
+	defer stmt.Close()
+
+	var rows *sql.Rows
+	rows, err = stmt.QueryContext(ctx, req.ID)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		var (
+			email sql.NullString
+			deletedAt sql.NullTime
+		)
+
+		if err = rows.Scan(
+			&res.ID,
+			&res.Title,
+			&email,
+			&res.CreatedAt,
+			&res.UpdatedAt,
+			&deletedAt,
+			&res.Version,
+		); err != nil {
+			return
+		}
+
+		if email.Valid {
+			res.Email = email.String
+		}
+
+		if deletedAt.Valid {
+			res.DeletedAt = &deletedAt.Time
+		}
+	}
+
+	return
+}
\ No newline at end of file
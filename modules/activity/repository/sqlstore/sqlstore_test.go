@@ -0,0 +1,266 @@
+package sqlstore
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/fahmiaz411/devcode/config/database"
+	"github.com/fahmiaz411/devcode/config/migration"
+	"github.com/fahmiaz411/devcode/helper/pagination"
+	"github.com/fahmiaz411/devcode/modules/activity/domain"
+)
+
+// newStore is an activity store on a migrated in-memory SQLite database
+func newStore(t *testing.T) *SqlRepository {
+	t.Helper()
+
+	db := database.NewDatabase(database.Config{Driver: database.DriverSqlite, Sqlite: database.SqliteConfig{Path: ":memory:"}})
+	t.Cleanup(func() { db.Conn.Close() })
+
+	migrator, err := migration.NewMigrator(db.Conn, database.DriverSqlite)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = migrator.Up(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	return &SqlRepository{Conn: db.Conn, Dialect: database.DriverSqlite}
+}
+
+func create(t *testing.T, m *SqlRepository, title string) domain.Activity {
+	t.Helper()
+
+	res, err := m.Create(context.Background(), domain.ActivityCreateRequest{Title: title})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return res.Activity
+}
+
+func getOne(t *testing.T, m *SqlRepository, id int64, trashed bool) domain.Activity {
+	t.Helper()
+
+	res, err := m.GetOne(context.Background(), domain.ActivityGetOneRequest{ID: id, Trashed: trashed})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return res.Activity
+}
+
+func TestCreateGetOne(t *testing.T) {
+	m := newStore(t)
+
+	res, err := m.Create(context.Background(), domain.ActivityCreateRequest{Title: "Home", Email: "me@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := getOne(t, m, res.ID, false)
+	if got.ID != res.ID || got.Title != "Home" || got.Email != "me@example.com" || got.Version != domain.VersionDefault || got.DeletedAt != nil {
+		t.Errorf("activity = %+v", got)
+	}
+
+	// Email is nullable
+	if got := getOne(t, m, create(t, m, "Work").ID, false); got.Email != "" {
+		t.Errorf("email = %q, want none", got.Email)
+	}
+
+	if missing := getOne(t, m, res.ID+2, false); missing.ID != 0 {
+		t.Errorf("found activity %d, want none", missing.ID)
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	ctx := context.Background()
+	m := newStore(t)
+	activity := create(t, m, "a")
+
+	at := time.Now().UTC().Add(time.Minute).Truncate(time.Second)
+	if _, err := m.Update(ctx, domain.ActivityUpdateRequest{ID: activity.ID, Title: "b", UpdatedAt: at, Version: activity.Version}); err != nil {
+		t.Fatal(err)
+	}
+
+	got := getOne(t, m, activity.ID, false)
+	if got.Title != "b" || got.Version != activity.Version+1 || !got.UpdatedAt.Equal(at) {
+		t.Errorf("activity = %+v", got)
+	}
+
+	// Written against the version it read, a stale write changes nothing
+	_, err := m.Update(ctx, domain.ActivityUpdateRequest{ID: activity.ID, Title: "c", UpdatedAt: at, Version: activity.Version})
+	if !errors.Is(err, domain.ErrVersionConflict) {
+		t.Fatalf("error = %v, want a version conflict", err)
+	}
+
+	if got := getOne(t, m, activity.ID, false); got.Title != "b" {
+		t.Errorf("title = %q after a stale write", got.Title)
+	}
+}
+
+func TestDelete(t *testing.T) {
+	ctx := context.Background()
+	m := newStore(t)
+	activity := create(t, m, "a")
+	at := time.Now().UTC()
+
+	if _, err := m.Delete(ctx, domain.ActivityDeleteRequest{ID: activity.ID, Version: activity.Version + 1, DeletedAt: at}); !errors.Is(err, domain.ErrVersionConflict) {
+		t.Fatalf("error = %v, want a version conflict", err)
+	}
+
+	if _, err := m.Delete(ctx, domain.ActivityDeleteRequest{ID: activity.ID, Version: activity.Version, DeletedAt: at}); err != nil {
+		t.Fatal(err)
+	}
+
+	// In the trash, out of the live activities
+	if got := getOne(t, m, activity.ID, false); got.ID != 0 {
+		t.Errorf("deleted activity %d still live", got.ID)
+	}
+
+	trashed := getOne(t, m, activity.ID, true)
+	if trashed.DeletedAt == nil || trashed.Version != activity.Version+1 {
+		t.Errorf("trashed activity = %+v", trashed)
+	}
+}
+
+func TestGetAll(t *testing.T) {
+	ctx := context.Background()
+	m := newStore(t)
+
+	for _, title := range []string{"b", "a", "B", "c"} {
+		create(t, m, title)
+	}
+
+	// list runs req validated like the usecase does
+	list := func(req domain.ActivityGetAllRequest) (titles []string, paging pagination.Paging) {
+		t.Helper()
+
+		if err := req.Validate(domain.SortAllList); err != nil {
+			t.Fatal(err)
+		}
+
+		res, err := m.GetAll(ctx, req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, activity := range res.Activities {
+			titles = append(titles, activity.Title)
+		}
+
+		return titles, res.Paging
+	}
+
+	// By title byte by byte, a page at a time
+	req := domain.ActivityGetAllRequest{Request: pagination.Request{Limit: 3, Sort: domain.SortTitle, Order: pagination.OrderAsc}}
+	titles, paging := list(req)
+	if !reflect.DeepEqual(titles, []string{"B", "a", "b"}) || paging.Total != 4 || paging.NextCursor == "" {
+		t.Fatalf("first page = %v, %+v", titles, paging)
+	}
+
+	req.Cursor = paging.NextCursor
+	if titles, paging = list(req); !reflect.DeepEqual(titles, []string{"c"}) || paging.NextCursor != "" {
+		t.Errorf("second page = %v, %+v", titles, paging)
+	}
+
+	// The trash apart from the live activities
+	trashed := getOne(t, m, 1, false)
+	if _, err := m.Delete(ctx, domain.ActivityDeleteRequest{ID: trashed.ID, Version: trashed.Version, DeletedAt: time.Now().UTC()}); err != nil {
+		t.Fatal(err)
+	}
+
+	if titles, _ = list(domain.ActivityGetAllRequest{}); !reflect.DeepEqual(titles, []string{"a", "B", "c"}) {
+		t.Errorf("live activities = %v, want a, B, c", titles)
+	}
+
+	if titles, _ = list(domain.ActivityGetAllRequest{Trashed: true}); !reflect.DeepEqual(titles, []string{"b"}) {
+		t.Errorf("trash = %v, want b", titles)
+	}
+}
+
+func TestRestore(t *testing.T) {
+	ctx := context.Background()
+	m := newStore(t)
+	activity := create(t, m, "a")
+	at := time.Now().UTC()
+
+	// A live activity is not restored
+	if _, err := m.Restore(ctx, domain.ActivityRestoreRequest{ID: activity.ID, UpdatedAt: at, Version: activity.Version}); !errors.Is(err, domain.ErrVersionConflict) {
+		t.Fatalf("live: error = %v, want a version conflict", err)
+	}
+
+	if _, err := m.Delete(ctx, domain.ActivityDeleteRequest{ID: activity.ID, Version: activity.Version, DeletedAt: at}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Changed since the trash was read, the restore writes nothing
+	if _, err := m.Restore(ctx, domain.ActivityRestoreRequest{ID: activity.ID, UpdatedAt: at, Version: activity.Version}); !errors.Is(err, domain.ErrVersionConflict) {
+		t.Fatalf("stale: error = %v, want a version conflict", err)
+	}
+
+	if _, err := m.Restore(ctx, domain.ActivityRestoreRequest{ID: activity.ID, UpdatedAt: at, Version: activity.Version + 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := getOne(t, m, activity.ID, false); got.DeletedAt != nil || got.Version != activity.Version+2 {
+		t.Errorf("restored activity = %+v", got)
+	}
+}
+
+func TestPurgeExpired(t *testing.T) {
+	ctx := context.Background()
+	m := newStore(t)
+	at := time.Now().UTC()
+
+	for i, title := range []string{"a", "b", "c", "live"} {
+		activity := create(t, m, title)
+		if title == "live" {
+			continue
+		}
+
+		if _, err := m.Delete(ctx, domain.ActivityDeleteRequest{ID: activity.ID, Version: activity.Version, DeletedAt: at.Add(time.Duration(i) * time.Hour)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Deleted before the time, a page by ID
+	expired, err := m.GetExpired(ctx, domain.ActivityGetExpiredRequest{DeletedBefore: at.Add(2 * time.Hour), Limit: 1, Lock: domain.LockUpdate})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(expired.Activities) != 1 || expired.Activities[0].ID != 1 || !expired.Activities[0].DeletedAt.Equal(at) {
+		t.Fatalf("expired = %+v, want 1", expired.Activities)
+	}
+
+	next, err := m.GetExpired(ctx, domain.ActivityGetExpiredRequest{DeletedBefore: at.Add(2 * time.Hour), AfterID: 1, Limit: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(next.Activities) != 1 || next.Activities[0].ID != 2 {
+		t.Fatalf("next = %+v, want 2", next.Activities)
+	}
+
+	// Restored and deleted again since it was read, it stays
+	if _, err = m.Purge(ctx, domain.ActivityPurgeRequest{ID: 1, DeletedAt: at.Add(time.Minute)}); !errors.Is(err, domain.ErrNotPurged) {
+		t.Fatalf("error = %v, want not purged", err)
+	}
+
+	if _, err = m.Purge(ctx, domain.ActivityPurgeRequest{ID: 4, DeletedAt: at}); !errors.Is(err, domain.ErrNotPurged) {
+		t.Fatalf("live: error = %v, want not purged", err)
+	}
+
+	if _, err = m.Purge(ctx, domain.ActivityPurgeRequest{ID: 1, DeletedAt: *expired.Activities[0].DeletedAt}); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := getOne(t, m, 1, true); got.ID != 0 {
+		t.Errorf("purged activity %d still in the trash", got.ID)
+	}
+}
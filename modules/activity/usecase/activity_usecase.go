@@ -2,145 +2,565 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"net/http"
 	"time"
 
 	"github.com/fahmiaz411/devcode/helper/constant"
+	"github.com/fahmiaz411/devcode/helper/failure"
+	"github.com/fahmiaz411/devcode/helper/field"
 	"github.com/fahmiaz411/devcode/helper/message"
-	"github.com/fahmiaz411/devcode/helper/web"
+	"github.com/fahmiaz411/devcode/helper/pagination"
+	"github.com/fahmiaz411/devcode/helper/slice"
 	"github.com/fahmiaz411/devcode/modules/activity/domain"
 	"github.com/fahmiaz411/devcode/modules/activity/interfaces"
 	"github.com/fahmiaz411/devcode/modules/activity/repository"
-
-	"github.com/gofiber/fiber/v2"
+	todoDomain "github.com/fahmiaz411/devcode/modules/todo/domain"
 )
 
 type Usecase struct {
 	repo           *repository.Repository
+	todos          interfaces.TodoStore
 	contentTimeout time.Duration
+	cascade        string
 }
 
-func NewUsecase(repo *repository.Repository, timeout time.Duration) interfaces.ActivityUsecase {
+// NewUsecase constructor, cascade is one of domain.CascadeAllList and applies to todos
+func NewUsecase(repo *repository.Repository, timeout time.Duration, cascade string, todos interfaces.TodoStore) interfaces.ActivityUsecase {
 	return &Usecase{
 		repo:           repo,
+		todos:          todos,
 		contentTimeout: timeout,
+		cascade:        cascade,
 	}
 }
 
+// versionConflict is a failed If-Match, or a concurrent write when the client sent none
+func versionConflict(id int64, conditional bool) error {
+	msg := message.Modified(domain.Model, "ID", fmt.Sprint(id))
+	if conditional {
+		return failure.Precondition(msg)
+	}
+
+	return failure.Conflict(msg)
+}
 
-func (u *Usecase) Create(c *fiber.Ctx, req domain.ActivityCreateRequest) (res domain.ActivityCreateResponse, err error) {
-	ctx, cancel := context.WithTimeout(c.UserContext(), u.contentTimeout)
+func (u *Usecase) Create(ctx context.Context, req domain.ActivityCreateRequest) (res domain.ActivityCreateResponse, err error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contentTimeout)
 	defer cancel()
 
-	res, err = u.repo.MySQL.Create(ctx, req)
+	if req.Title == constant.EmptyString {
+		err = failure.Validation(message.CanotNull(field.Title))
+		return
+	}
+
+	res, err = u.repo.Store.Create(ctx, req)
 	if err != nil {
-		c.Status(http.StatusInternalServerError).JSON(web.BaseResponse{
-			Status: http.StatusText(http.StatusInternalServerError),
-			Message: err.Error(),
-			Data: struct{}{},
-		})
+		err = failure.Internal(err)
 		return
 	}
 
 	return
 }
 
-func (u *Usecase) Update(c *fiber.Ctx, req domain.ActivityUpdateRequest) (res domain.ActivityUpdateResponse, err error) {
-	ctx, cancel := context.WithTimeout(c.UserContext(), u.contentTimeout)
+func (u *Usecase) Update(ctx context.Context, req domain.ActivityUpdateRequest) (res domain.ActivityUpdateResponse, err error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contentTimeout)
 	defer cancel()
 
-	var activity domain.ActivityGetOneResponse
-	activity, err = u.GetOne(c, domain.ActivityGetOneRequest{
-		ID: req.ID,
+	if req.Title == constant.EmptyString {
+		err = failure.Validation(message.CanotNull(field.Title))
+		return
+	}
+
+	// Without If-Match the version read below still keeps a concurrent write from being overwritten
+	conditional := req.Version != int64(constant.ZeroValue)
+
+	err = u.repo.Transactor.WithinTransaction(ctx, func(ctx context.Context) (err error) {
+		var activity domain.ActivityGetOneResponse
+		activity, err = u.GetOne(ctx, domain.ActivityGetOneRequest{
+			ID: req.ID,
+		})
+		if err != nil {
+			return
+		}
+
+		if !conditional {
+			req.Version = activity.Version
+		} else if req.Version != activity.Version {
+			err = versionConflict(req.ID, conditional)
+			return
+		}
+
+		req.UpdatedAt = time.Now().UTC()
+
+		_, err = u.repo.Store.Update(ctx, req)
+		if errors.Is(err, domain.ErrVersionConflict) {
+			err = versionConflict(req.ID, conditional)
+			return
+		} else if err != nil {
+			return
+		}
+
+		res.Activity = activity.Activity
+		res.Title = req.Title
+		res.UpdatedAt = req.UpdatedAt
+		res.Version = activity.Version + 1
+
+		return
 	})
 	if err != nil {
+		err = failure.Internal(err)
 		return
 	}
 
-	req.UpdatedAt = time.Now().UTC()
+	return 
+}
+
+// Delete moves an activity group to the trash, its todos follow the cascade in the same unit of work
+func (u *Usecase) Delete(ctx context.Context, req domain.ActivityDeleteRequest) (res domain.ActivityDeleteResponse, err error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contentTimeout)
+	defer cancel()
+
+	conditional := req.Version != int64(constant.ZeroValue)
+
+	err = u.repo.Transactor.WithinTransaction(ctx, func(ctx context.Context) (err error) {
+		// Locked first, a todo put in the group meanwhile would miss the cascade
+		var activity domain.ActivityGetOneResponse
+		activity, err = u.GetOne(ctx, domain.ActivityGetOneRequest{
+			ID: req.ID,
+			Lock: domain.LockUpdate,
+		})
+		if err != nil {
+			return
+		}
+
+		if !conditional {
+			req.Version = activity.Version
+		} else if req.Version != activity.Version {
+			err = versionConflict(req.ID, conditional)
+			return
+		}
+
+		// The todos deleted along with the group share its deleted_at, Restore finds them by it
+		req.DeletedAt = time.Now().UTC()
+
+		switch u.cascade {
+		case domain.CascadeRestrict:
+			var todos todoDomain.TodoCountByActivityResponse
+			todos, err = u.todos.CountByActivity(ctx, todoDomain.TodoCountByActivityRequest{
+				ActivityGroupID: req.ID,
+			})
+			if err != nil {
+				return
+			} else if todos.Count != int64(constant.ZeroValue) {
+				err = failure.Conflict(message.NotEmpty(domain.Model, "ID", fmt.Sprint(req.ID), "todo items"))
+				return
+			}
+
+		case domain.CascadeHardDelete:
+			_, err = u.todos.PurgeByActivity(ctx, todoDomain.TodoPurgeByActivityRequest{
+				ActivityGroupID: req.ID,
+			})
+
+		default:
+			_, err = u.todos.DeleteByActivity(ctx, todoDomain.TodoDeleteByActivityRequest{
+				ActivityGroupID: req.ID,
+				DeletedAt: req.DeletedAt,
+			})
+		}
+		if err != nil {
+			return
+		}
+
+		res, err = u.repo.Store.Delete(ctx, req)
+		if errors.Is(err, domain.ErrVersionConflict) {
+			err = versionConflict(req.ID, conditional)
+		}
+
+		return
+	})
+	if err != nil {
+		err = failure.Internal(err)
+	}
+
+	return 
+}
+
+// Restore takes an activity group out of the trash, with the todos deleted along with it
+func (u *Usecase) Restore(ctx context.Context, req domain.ActivityRestoreRequest) (res domain.ActivityRestoreResponse, err error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contentTimeout)
+	defer cancel()
+
+	err = u.repo.Transactor.WithinTransaction(ctx, func(ctx context.Context) (err error) {
+		var activity domain.ActivityGetOneResponse
+		activity, err = u.GetOne(ctx, domain.ActivityGetOneRequest{
+			ID: req.ID,
+			Trashed: true,
+		})
+		if err != nil {
+			return
+		}
+
+		req.UpdatedAt = time.Now().UTC()
+
+		if _, err = u.todos.RestoreByActivity(ctx, todoDomain.TodoRestoreByActivityRequest{
+			ActivityGroupID: req.ID,
+			DeletedAt: *activity.DeletedAt,
+			UpdatedAt: req.UpdatedAt,
+		}); err != nil {
+			return
+		}
+
+		// Restored or changed since it was read
+		req.Version = activity.Version
+		if _, err = u.repo.Store.Restore(ctx, req); errors.Is(err, domain.ErrVersionConflict) {
+			err = versionConflict(req.ID, false)
+			return
+		} else if err != nil {
+			return
+		}
 
-	res, err = u.repo.MySQL.Update(ctx, req)
+		res.Activity = activity.Activity
+		res.UpdatedAt = req.UpdatedAt
+		res.DeletedAt = nil
+		res.Version = activity.Version + 1
+
+		return
+	})
 	if err != nil {
-		c.Status(http.StatusInternalServerError).JSON(web.BaseResponse{
-			Status: http.StatusText(http.StatusInternalServerError),
-			Message: err.Error(),
-			Data: struct{}{},
+		err = failure.Internal(err)
+		return
+	}
+
+	return
+}
+
+// Purge permanently deletes an activity group and all its todos, only from the trash
+func (u *Usecase) Purge(ctx context.Context, req domain.ActivityPurgeRequest) (res domain.ActivityPurgeResponse, err error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contentTimeout)
+	defer cancel()
+
+	err = u.repo.Transactor.WithinTransaction(ctx, func(ctx context.Context) (err error) {
+		var activity domain.ActivityGetOneResponse
+		activity, err = u.GetOne(ctx, domain.ActivityGetOneRequest{
+			ID: req.ID,
+			Trashed: true,
+			Lock: domain.LockUpdate,
 		})
+		if err != nil {
+			return
+		}
+
+		if _, err = u.todos.PurgeByActivity(ctx, todoDomain.TodoPurgeByActivityRequest{
+			ActivityGroupID: req.ID,
+		}); err != nil {
+			return
+		}
+
+		req.DeletedAt = *activity.DeletedAt
+		res, err = u.repo.Store.Purge(ctx, req)
+		if errors.Is(err, domain.ErrNotPurged) {
+			err = failure.NotFound(message.NotFound(domain.Model, "ID", fmt.Sprint(req.ID)))
+		}
+
 		return
+	})
+	if err != nil {
+		err = failure.Internal(err)
 	}
 
-	res.ID = activity.ID
-	res.Title = req.Title
-	res.Email = activity.Email
-	res.CreatedAt = activity.CreatedAt
-	res.UpdatedAt = req.UpdatedAt
-	res.DeletedAt = activity.DeletedAt
+	return
+}
 
-	return 
+// PurgeExpired permanently deletes the activity groups, and their todos, deleted longer ago than the retention.
+// Each batch of groups is a unit of work of its own, a failure leaves the rest in the trash for the next run.
+func (u *Usecase) PurgeExpired(ctx context.Context, req domain.ActivityPurgeExpiredRequest) (res domain.ActivityPurgeExpiredResponse, err error) {
+	if req.Retention <= 0 {
+		err = failure.Validation(message.MustPositive("retention"))
+		return
+	}
+
+	if req.Batch <= 0 {
+		err = failure.Validation(message.MustPositive("batch"))
+		return
+	}
+
+	deletedBefore := time.Now().UTC().Add(-req.Retention)
+
+	// A dry run leaves the groups in place, the next batch starts after the last ID instead
+	var afterID int64
+	for {
+		var (
+			batch domain.ActivityPurgeExpiredResponse
+			ids []int64
+		)
+		batch, ids, err = u.purgeExpired(ctx, req, deletedBefore, afterID)
+		if err != nil {
+			err = failure.Internal(err)
+			return
+		}
+
+		res.Activities += batch.Activities
+		res.Todos += batch.Todos
+
+		if len(ids) < req.Batch {
+			return
+		}
+
+		afterID = ids[len(ids)-1]
+	}
 }
 
-func (u *Usecase) Delete(c *fiber.Ctx, req domain.ActivityDeleteRequest) (res domain.ActivityDeleteResponse, err error) {
-	ctx, cancel := context.WithTimeout(c.UserContext(), u.contentTimeout)
+// purgeExpired purges a single batch of the expired activity groups, those after an ID
+func (u *Usecase) purgeExpired(ctx context.Context, req domain.ActivityPurgeExpiredRequest, deletedBefore time.Time, afterID int64) (res domain.ActivityPurgeExpiredResponse, ids []int64, err error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contentTimeout)
 	defer cancel()
 
-	_, err = u.GetOne(c, domain.ActivityGetOneRequest{
-		ID: req.ID,
+	err = u.repo.Transactor.WithinTransaction(ctx, func(ctx context.Context) (err error) {
+		var expired domain.ActivityGetExpiredResponse
+		getExpired := domain.ActivityGetExpiredRequest{
+			DeletedBefore: deletedBefore,
+			AfterID: afterID,
+			Limit: req.Batch,
+		}
+		if !req.DryRun {
+			getExpired.Lock = domain.LockUpdate
+		}
+
+		expired, err = u.repo.Store.GetExpired(ctx, getExpired)
+		if err != nil {
+			return
+		}
+
+		for _, activity := range expired.Activities {
+			id := activity.ID
+			ids = append(ids, id)
+
+			// The group goes first, as it was read, its todos only along with it
+			if !req.DryRun {
+				_, err = u.repo.Store.Purge(ctx, domain.ActivityPurgeRequest{
+					ID: id,
+					DeletedAt: *activity.DeletedAt,
+				})
+				if errors.Is(err, domain.ErrNotPurged) {
+					err = nil
+					continue
+				} else if err != nil {
+					return
+				}
+			}
+
+			var todos todoDomain.TodoCountByActivityResponse
+			todos, err = u.todos.CountByActivity(ctx, todoDomain.TodoCountByActivityRequest{
+				ActivityGroupID: id,
+				WithDeleted: true,
+			})
+			if err != nil {
+				return
+			}
+
+			res.Activities++
+			res.Todos += todos.Count
+
+			if req.DryRun {
+				continue
+			}
+
+			if _, err = u.todos.PurgeByActivity(ctx, todoDomain.TodoPurgeByActivityRequest{
+				ActivityGroupID: id,
+			}); err != nil {
+				return
+			}
+		}
+
+		return
 	})
 	if err != nil {
+		res = domain.ActivityPurgeExpiredResponse{}
+	}
+
+	return
+}
+
+func (u *Usecase) GetAll(ctx context.Context, req domain.ActivityGetAllRequest) (res domain.ActivityGetAllResponse, err error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contentTimeout)
+	defer cancel()
+
+	if err = req.Validate(domain.SortAllList); err != nil {
+		err = failure.Validation(err.Error())
 		return
 	}
-	
-	res, err = u.repo.MySQL.Delete(ctx, req)
+
+	if err = validateInclude(req.Include); err != nil {
+		return
+	}
+
+	res, err = u.repo.Store.GetAll(ctx, req)
 	if err != nil {
-		c.Status(http.StatusInternalServerError).JSON(web.BaseResponse{
-			Status: http.StatusText(http.StatusInternalServerError),
-			Message: err.Error(),
-			Data: struct{}{},
-		})
+		err = failure.Internal(err)
+		return
 	}
 
-	return 
+	if err = u.include(ctx, req.Include, res.Activities); err != nil {
+		err = failure.Internal(err)
+		return
+	}
+
+	return
 }
 
-func (u *Usecase) GetAll(c *fiber.Ctx, req domain.ActivityGetAllRequest) (res domain.ActivityGetAllResponse, err error) {
-	ctx, cancel := context.WithTimeout(c.UserContext(), u.contentTimeout)
+// GetAllStamp tells cheaply whether the result of GetAll changed
+func (u *Usecase) GetAllStamp(ctx context.Context, req domain.ActivityGetAllRequest) (res domain.ActivityGetAllStampResponse, err error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contentTimeout)
 	defer cancel()
 
-	res, err = u.repo.MySQL.GetAll(ctx, req)
+	if err = req.Validate(domain.SortAllList); err != nil {
+		err = failure.Validation(err.Error())
+		return
+	}
+
+	res, err = u.repo.Store.GetAllStamp(ctx, req)
 	if err != nil {
-		c.Status(http.StatusInternalServerError).JSON(web.BaseResponse{
-			Status: http.StatusText(http.StatusInternalServerError),
-			Message: err.Error(),
-			Data: struct{}{},
-		})
+		err = failure.Internal(err)
 		return
 	}
 
 	return
 }
 
-func (u *Usecase) GetOne(c *fiber.Ctx, req domain.ActivityGetOneRequest) (res domain.ActivityGetOneResponse, err error) {
-	ctx, cancel := context.WithTimeout(c.UserContext(), u.contentTimeout)
+func (u *Usecase) GetOne(ctx context.Context, req domain.ActivityGetOneRequest) (res domain.ActivityGetOneResponse, err error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contentTimeout)
 	defer cancel()
 
-	res, err = u.repo.MySQL.GetOne(ctx, req)
+	if err = validateInclude(req.Include); err != nil {
+		return
+	}
+
+	res, err = u.repo.Store.GetOne(ctx, req)
 	if err != nil {
-		c.Status(http.StatusInternalServerError).JSON(web.BaseResponse{
-			Status: http.StatusText(http.StatusInternalServerError),
-			Message: err.Error(),
-			Data: struct{}{},
-		})
+		err = failure.Internal(err)
 		return
 	} else if res.ID == int64(constant.ZeroValue) {
-		c.Status(http.StatusNotFound).JSON(web.BaseResponse{
-			Status: http.StatusText(http.StatusNotFound),
-			Message: message.NotFound(domain.Model, "ID", fmt.Sprint(req.ID)),
-			Data: struct{}{},
-		})
-		err = fmt.Errorf(http.StatusText(http.StatusNotFound))
+		err = failure.NotFound(message.NotFound(domain.Model, "ID", fmt.Sprint(req.ID)))
 		return
 	}
 
+	activities := []domain.Activity{res.Activity}
+	if err = u.include(ctx, req.Include, activities); err != nil {
+		err = failure.Internal(err)
+		return
+	}
+
+	res.Activity = activities[0]
+
 	return 
-}
\ No newline at end of file
+}
+
+func validateInclude(include []string) error {
+	for _, name := range include {
+		if !slice.Includes(domain.IncludeAllList, name) {
+			return failure.Validation(message.ShoudMatchEnum(field.Include, domain.IncludeAllList))
+		}
+	}
+
+	return nil
+}
+
+// include embeds the todos of the activity groups, each include is a single query whatever the number of groups
+func (u *Usecase) include(ctx context.Context, include []string, activities []domain.Activity) (err error) {
+	if len(include) == constant.ZeroValue || len(activities) == constant.ZeroValue {
+		return
+	}
+
+	ids := make([]int64, len(activities))
+	for i, act := range activities {
+		ids[i] = act.ID
+	}
+
+	if slice.Includes(include, domain.IncludeTodoItems) {
+		var todos todoDomain.TodoGetAllResponse
+		todos, err = u.todos.GetAll(ctx, todoDomain.TodoGetAllRequest{
+			ActivityGroupIDs: ids,
+			Request: pagination.Request{
+				Sort:  todoDomain.SortPosition,
+				Order: pagination.OrderAsc,
+			},
+		})
+		if err != nil {
+			return
+		}
+
+		byActivity := map[int64][]todoDomain.Todo{}
+		for _, todo := range todos.Todos {
+			byActivity[todo.ActivityGroupID] = append(byActivity[todo.ActivityGroupID], todo)
+		}
+
+		for i := range activities {
+			items := append([]todoDomain.Todo{}, byActivity[activities[i].ID]...)
+			activities[i].TodoItems = &items
+		}
+	}
+
+	if slice.Includes(include, domain.IncludeCounts) {
+		var counts todoDomain.TodoGroupCountResponse
+		counts, err = u.todos.GroupCount(ctx, todoDomain.TodoGroupCountRequest{
+			ActivityGroupIDs: ids,
+		})
+		if err != nil {
+			return
+		}
+
+		byActivity := map[int64]*todoDomain.TodoCounts{}
+		for i := range activities {
+			activities[i].Counts = todoDomain.NewTodoCounts()
+			byActivity[activities[i].ID] = activities[i].Counts
+		}
+
+		for _, group := range counts.Groups {
+			byActivity[group.ActivityGroupID].Add(group)
+		}
+	}
+
+	return
+}
+
+
+// Exists tells whether todos can be put in the activity group, GetOne leaves out deleted ones.
+// Inside a unit of work the group stays locked against Delete and Purge until it ends.
+func (u *Usecase) Exists(ctx context.Context, id int64) (exists bool, err error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contentTimeout)
+	defer cancel()
+
+	var res domain.ActivityGetOneResponse
+	res, err = u.repo.Store.GetOne(ctx, domain.ActivityGetOneRequest{
+		ID: id,
+		Lock: domain.LockShare,
+	})
+	if err != nil {
+		err = failure.Internal(err)
+		return
+	}
+
+	exists = res.ID != int64(constant.ZeroValue)
+
+	return
+}
+
+// Email is where the reminders of the todos of the activity group go, empty when it has none or is deleted
+func (u *Usecase) Email(ctx context.Context, id int64) (email string, err error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contentTimeout)
+	defer cancel()
+
+	var res domain.ActivityGetOneResponse
+	res, err = u.repo.Store.GetOne(ctx, domain.ActivityGetOneRequest{
+		ID: id,
+	})
+	if err != nil {
+		err = failure.Internal(err)
+		return
+	}
+
+	email = res.Email
+
+	return
+}
@@ -0,0 +1,396 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/fahmiaz411/devcode/config/database"
+	"github.com/fahmiaz411/devcode/helper/failure"
+	"github.com/fahmiaz411/devcode/helper/field"
+	"github.com/fahmiaz411/devcode/helper/message"
+	"github.com/fahmiaz411/devcode/modules/activity/domain"
+	"github.com/fahmiaz411/devcode/modules/activity/interfaces"
+	"github.com/fahmiaz411/devcode/modules/activity/repository"
+	todoDomain "github.com/fahmiaz411/devcode/modules/todo/domain"
+	todoInterfaces "github.com/fahmiaz411/devcode/modules/todo/interfaces"
+	todoRepository "github.com/fahmiaz411/devcode/modules/todo/repository"
+	todoUsecase "github.com/fahmiaz411/devcode/modules/todo/usecase"
+)
+
+// newUsecases are the activity and todo usecases on a shared memory database, wired like the app
+func newUsecases(cascade string) (u interfaces.ActivityUsecase, todos todoInterfaces.TodoUsecase) {
+	db := database.NewDatabase(database.Config{Driver: database.DriverMemory})
+	todoRepo := todoRepository.NewRepository(db)
+
+	u = NewUsecase(repository.NewRepository(db), time.Second, cascade, todoRepo.Store)
+	todos = todoUsecase.NewUsecase(todoRepo, time.Second, u)
+
+	return
+}
+
+// checkErr fails unless err is a failure of kind with msg, or nil when kind is empty
+func checkErr(t *testing.T, name string, err error, kind failure.Kind, msg string) {
+	t.Helper()
+
+	if kind == "" {
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", name, err)
+		}
+		return
+	}
+
+	var e *failure.Error
+	if !errors.As(err, &e) {
+		t.Errorf("%s: error = %v, want %s %q", name, err, kind, msg)
+		return
+	}
+
+	if e.Kind != kind || e.Message != msg {
+		t.Errorf("%s: error = %s %q, want %s %q", name, e.Kind, e.Message, kind, msg)
+	}
+}
+
+// seed creates an activity group with a todo per title
+func seed(t *testing.T, u interfaces.ActivityUsecase, todos todoInterfaces.TodoUsecase, title string, titles ...string) (activity domain.Activity, items []todoDomain.Todo) {
+	t.Helper()
+
+	ctx := context.Background()
+	res, err := u.Create(ctx, domain.ActivityCreateRequest{Title: title})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, title := range titles {
+		todo, err := todos.Create(ctx, todoDomain.TodoCreateRequest{Title: title, ActivityGroupID: res.ID})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		items = append(items, todo.Todo)
+	}
+
+	return res.Activity, items
+}
+
+// trash is the IDs of the activity groups in the trash
+func trash(t *testing.T, u interfaces.ActivityUsecase) (ids []int64) {
+	t.Helper()
+
+	res, err := u.GetAll(context.Background(), domain.ActivityGetAllRequest{Trashed: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, activity := range res.Activities {
+		ids = append(ids, activity.ID)
+	}
+
+	return
+}
+
+// todo is a todo read live, or from the trash, nil when it is in neither
+func todo(t *testing.T, todos todoInterfaces.TodoUsecase, id int64, trashed bool) *todoDomain.Todo {
+	t.Helper()
+
+	res, err := todos.GetOne(context.Background(), todoDomain.TodoGetOneRequest{ID: id, Trashed: trashed})
+	if failure.KindOf(err) == failure.KindNotFound {
+		return nil
+	} else if err != nil {
+		t.Fatal(err)
+	}
+
+	return &res.Todo
+}
+
+func TestDeleteCascade(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		cascade string
+		// todos is whether the group has a todo
+		todos bool
+		kind  failure.Kind
+		msg   string
+		// live and trashed tell where the todo of the group ends up
+		live, trashed bool
+	}{
+		{cascade: domain.CascadeSoftDelete, todos: true, trashed: true},
+		{cascade: domain.CascadeHardDelete, todos: true},
+		{cascade: domain.CascadeRestrict, todos: true, kind: failure.KindConflict, msg: message.NotEmpty(domain.Model, "ID", "1", "todo items"), live: true},
+		{cascade: domain.CascadeRestrict},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s, todos %t", tt.cascade, tt.todos), func(t *testing.T) {
+			u, todos := newUsecases(tt.cascade)
+
+			titles := []string{}
+			if tt.todos {
+				titles = append(titles, "a")
+			}
+			activity, items := seed(t, u, todos, "home", titles...)
+
+			_, err := u.Delete(ctx, domain.ActivityDeleteRequest{ID: activity.ID})
+			checkErr(t, "delete", err, tt.kind, tt.msg)
+
+			if inTrash := len(trash(t, u)) == 1; inTrash != (tt.kind == "") {
+				t.Errorf("group in the trash = %t", inTrash)
+			}
+
+			for _, item := range items {
+				if live := todo(t, todos, item.ID, false) != nil; live != tt.live {
+					t.Errorf("todo live = %t, want %t", live, tt.live)
+				}
+
+				trashed := todo(t, todos, item.ID, true)
+				if (trashed != nil) != tt.trashed {
+					t.Errorf("todo in the trash = %t, want %t", trashed != nil, tt.trashed)
+				}
+			}
+		})
+	}
+}
+
+func TestRestore(t *testing.T) {
+	ctx := context.Background()
+	u, todos := newUsecases(domain.CascadeSoftDelete)
+	activity, items := seed(t, u, todos, "home", "a", "b")
+
+	_, err := u.Restore(ctx, domain.ActivityRestoreRequest{ID: activity.ID})
+	checkErr(t, "live group", err, failure.KindNotFound, message.NotFound(domain.Model, "ID", "1"))
+
+	// Deleted before the group, a todo stays in the trash when the group is restored
+	if _, err = todos.Delete(ctx, todoDomain.TodoDeleteRequest{ID: items[1].ID}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = u.Delete(ctx, domain.ActivityDeleteRequest{ID: activity.ID}); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := u.Restore(ctx, domain.ActivityRestoreRequest{ID: activity.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res.DeletedAt != nil || res.Version != activity.Version+2 {
+		t.Errorf("restored group = %+v", res.Activity)
+	}
+
+	if len(trash(t, u)) != 0 {
+		t.Errorf("trash = %v, want none", trash(t, u))
+	}
+
+	if got := todo(t, todos, items[0].ID, false); got == nil || got.Version != items[0].Version+2 {
+		t.Errorf("todo deleted along = %+v, want it restored", got)
+	}
+
+	if todo(t, todos, items[1].ID, true) == nil {
+		t.Errorf("todo deleted before = live, want it in the trash")
+	}
+
+	_, err = u.Restore(ctx, domain.ActivityRestoreRequest{ID: activity.ID})
+	checkErr(t, "restored again", err, failure.KindNotFound, message.NotFound(domain.Model, "ID", "1"))
+}
+
+func TestPurge(t *testing.T) {
+	ctx := context.Background()
+	u, todos := newUsecases(domain.CascadeSoftDelete)
+	activity, items := seed(t, u, todos, "home", "a")
+
+	_, err := u.Purge(ctx, domain.ActivityPurgeRequest{ID: activity.ID})
+	checkErr(t, "live group", err, failure.KindNotFound, message.NotFound(domain.Model, "ID", "1"))
+
+	if _, err = u.Delete(ctx, domain.ActivityDeleteRequest{ID: activity.ID}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = u.Purge(ctx, domain.ActivityPurgeRequest{ID: activity.ID}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(trash(t, u)) != 0 {
+		t.Errorf("trash = %v, want none", trash(t, u))
+	}
+
+	if todo(t, todos, items[0].ID, true) != nil {
+		t.Errorf("todo of the purged group still in the trash")
+	}
+}
+
+func TestPurgeExpired(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		batch  int
+		dryRun bool
+	}{
+		{name: "a batch", batch: 10},
+		{name: "several batches", batch: 2},
+		{name: "batch of the expired groups", batch: 3},
+		{name: "dry run", batch: 2, dryRun: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, todos := newUsecases(domain.CascadeSoftDelete)
+
+			// Groups 1 to 3 expired with a todo each, 4 deleted within the retention, 5 live
+			var items []todoDomain.Todo
+			for i := 0; i < 5; i++ {
+				_, seeded := seed(t, u, todos, fmt.Sprint(i), "a")
+				items = append(items, seeded...)
+			}
+
+			for id := int64(1); id <= 4; id++ {
+				if id == 4 {
+					time.Sleep(50 * time.Millisecond)
+				}
+
+				if _, err := u.Delete(ctx, domain.ActivityDeleteRequest{ID: id}); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			res, err := u.PurgeExpired(ctx, domain.ActivityPurgeExpiredRequest{Retention: 25 * time.Millisecond, Batch: tt.batch, DryRun: tt.dryRun})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if res.Activities != 3 || res.Todos != 3 {
+				t.Errorf("purged = %+v, want 3 groups and 3 todos", res)
+			}
+
+			// A dry run purges nothing
+			left := 1
+			if tt.dryRun {
+				left = 4
+			}
+
+			if got := len(trash(t, u)); got != left {
+				t.Errorf("trash = %d groups, want %d", got, left)
+			}
+
+			for i, item := range items {
+				gone := todo(t, todos, item.ID, false) == nil && todo(t, todos, item.ID, true) == nil
+				if want := i < 3 && !tt.dryRun; gone != want {
+					t.Errorf("todo of group %d purged = %t, want %t", item.ActivityGroupID, gone, want)
+				}
+			}
+		})
+	}
+}
+
+func TestPurgeExpiredInvalid(t *testing.T) {
+	u, _ := newUsecases(domain.CascadeSoftDelete)
+
+	_, err := u.PurgeExpired(context.Background(), domain.ActivityPurgeExpiredRequest{Batch: 1})
+	checkErr(t, "retention", err, failure.KindValidation, message.MustPositive("retention"))
+
+	_, err = u.PurgeExpired(context.Background(), domain.ActivityPurgeExpiredRequest{Retention: time.Hour})
+	checkErr(t, "batch", err, failure.KindValidation, message.MustPositive("batch"))
+}
+
+// The routes nested under a deleted activity group are NotFound, as under a missing one
+func TestNestedDeleted(t *testing.T) {
+	ctx := context.Background()
+	u, todos := newUsecases(domain.CascadeSoftDelete)
+	activity, _ := seed(t, u, todos, "home", "a")
+	notFound := message.NotFound(todoDomain.ActivityModel, "ID", "1")
+
+	if _, err := u.Delete(ctx, domain.ActivityDeleteRequest{ID: activity.ID}); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := todos.GetAll(ctx, todoDomain.TodoGetAllRequest{ActivityGroupID: activity.ID, CheckActivity: true})
+	checkErr(t, "list", err, failure.KindNotFound, notFound)
+
+	_, err = todos.GetAllStamp(ctx, todoDomain.TodoGetAllRequest{ActivityGroupID: activity.ID, CheckActivity: true})
+	checkErr(t, "count", err, failure.KindNotFound, notFound)
+
+	_, err = todos.Create(ctx, todoDomain.TodoCreateRequest{Title: "b", ActivityGroupID: activity.ID, CheckActivity: true})
+	checkErr(t, "create", err, failure.KindNotFound, notFound)
+
+	_, err = todos.Stats(ctx, todoDomain.TodoStatsRequest{ActivityGroupID: activity.ID, CheckActivity: true})
+	checkErr(t, "stats", err, failure.KindNotFound, notFound)
+}
+
+func TestInclude(t *testing.T) {
+	ctx := context.Background()
+	u, todos := newUsecases(domain.CascadeSoftDelete)
+
+	// More todos than a page, an include embeds all of them
+	titles := make([]string, 25)
+	for i := range titles {
+		titles[i] = fmt.Sprint(i)
+	}
+	home, items := seed(t, u, todos, "home", titles...)
+	work, _ := seed(t, u, todos, "work")
+
+	// A deleted todo is left out, a completed one counted apart
+	if _, err := todos.Delete(ctx, todoDomain.TodoDeleteRequest{ID: items[0].ID}); err != nil {
+		t.Fatal(err)
+	}
+
+	done := false
+	if _, err := todos.Update(ctx, todoDomain.TodoUpdateRequest{ID: items[1].ID, IsActive: &done, Priority: todoDomain.PriorityLow}); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := u.GetAll(ctx, domain.ActivityGetAllRequest{Include: domain.IncludeAllList})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	byID := map[int64]domain.Activity{}
+	for _, activity := range res.Activities {
+		byID[activity.ID] = activity
+	}
+
+	got := byID[home.ID]
+	if got.TodoItems == nil || len(*got.TodoItems) != 24 || (*got.TodoItems)[0].ID != items[1].ID {
+		t.Errorf("todo items of home = %v", got.TodoItems)
+	}
+
+	if got.Counts == nil || got.Counts.Total != 24 || got.Counts.Active != 23 || got.Counts.ByPriority[todoDomain.PriorityLow] != 1 || got.Counts.ByPriority[todoDomain.PriorityDefault] != 23 {
+		t.Errorf("counts of home = %+v", got.Counts)
+	}
+
+	// A group without todos has them empty, every priority at zero
+	empty := byID[work.ID]
+	if empty.TodoItems == nil || len(*empty.TodoItems) != 0 || empty.Counts == nil || empty.Counts.Total != 0 || len(empty.Counts.ByPriority) != len(todoDomain.PriorityAllList) {
+		t.Errorf("work = %v, %+v", empty.TodoItems, empty.Counts)
+	}
+
+	// Left out unless included
+	one, err := u.GetOne(ctx, domain.ActivityGetOneRequest{ID: home.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if one.TodoItems != nil || one.Counts != nil {
+		t.Errorf("home without include = %v, %v", one.TodoItems, one.Counts)
+	}
+
+	one, err = u.GetOne(ctx, domain.ActivityGetOneRequest{ID: home.ID, Include: []string{domain.IncludeCounts}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if one.TodoItems != nil || one.Counts == nil || one.Counts.Total != 24 {
+		t.Errorf("home with counts = %v, %+v", one.TodoItems, one.Counts)
+	}
+
+	// Only the includes of the list
+	invalid := message.ShoudMatchEnum(field.Include, domain.IncludeAllList)
+
+	_, err = u.GetAll(ctx, domain.ActivityGetAllRequest{Include: []string{domain.IncludeCounts, "todos"}})
+	checkErr(t, "list", err, failure.KindValidation, invalid)
+
+	_, err = u.GetOne(ctx, domain.ActivityGetOneRequest{ID: home.ID, Include: []string{"email"}})
+	checkErr(t, "one", err, failure.KindValidation, invalid)
+}
@@ -0,0 +1,74 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"github.com/fahmiaz411/devcode/modules/activity/domain"
+	"github.com/fahmiaz411/devcode/modules/activity/interfaces"
+)
+
+// RetentionWorker purges the activity groups that stayed in the trash longer than Retention
+type RetentionWorker struct {
+	Usecase   interfaces.ActivityUsecase
+	Retention time.Duration
+	Interval  time.Duration
+	Batch     int
+	DryRun    bool
+
+	// Totals since start, a dry run counts the same rows again on every run
+	PurgedActivities atomic.Int64
+	PurgedTodos      atomic.Int64
+}
+
+func NewRetentionWorker(usecase interfaces.ActivityUsecase, retention, interval time.Duration, batch int, dryRun bool) *RetentionWorker {
+	return &RetentionWorker{
+		Usecase:   usecase,
+		Retention: retention,
+		Interval:  interval,
+		Batch:     batch,
+		DryRun:    dryRun,
+	}
+}
+
+// Start runs a purge right away then every Interval, until ctx is done
+func (w *RetentionWorker) Start(ctx context.Context) {
+	ticker := time.NewTicker(w.Interval)
+	defer ticker.Stop()
+
+	for {
+		w.Run(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Run does a single purge and logs its outcome
+func (w *RetentionWorker) Run(ctx context.Context) {
+	res, err := w.Usecase.PurgeExpired(ctx, domain.ActivityPurgeExpiredRequest{
+		Retention: w.Retention,
+		Batch:     w.Batch,
+		DryRun:    w.DryRun,
+	})
+	if err != nil {
+		log.Printf("retention: purge failed after %d activity groups and %d todo items: %v", res.Activities, res.Todos, err)
+		return
+	}
+
+	activities := w.PurgedActivities.Add(res.Activities)
+	todos := w.PurgedTodos.Add(res.Todos)
+
+	verb := "purged"
+	if w.DryRun {
+		verb = "dry run, would purge"
+	}
+
+	log.Printf("retention: %s %d activity groups and %d todo items deleted over %s ago (total %d and %d)",
+		verb, res.Activities, res.Todos, w.Retention, activities, todos)
+}
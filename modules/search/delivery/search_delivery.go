@@ -0,0 +1,42 @@
+package delivery
+
+import (
+	"github.com/fahmiaz411/devcode/helper/message"
+	"github.com/fahmiaz411/devcode/helper/query"
+	"github.com/fahmiaz411/devcode/helper/web"
+	"github.com/fahmiaz411/devcode/modules/search/domain"
+	"github.com/fahmiaz411/devcode/modules/search/interfaces"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type RESTHandler struct {
+	Usecase interfaces.SearchUsecase
+}
+
+func NewRESTHandler(f fiber.Router, usecase interfaces.SearchUsecase) {
+	handler := &RESTHandler{
+		Usecase: usecase,
+	}
+
+	f.Get("/search", handler.Search)
+}
+
+func (h *RESTHandler) Search(c *fiber.Ctx) error {
+	req := domain.SearchRequest{
+		Query: c.Query(query.Query),
+		Types: web.QueryList(c, query.Types),
+		Limit: c.QueryInt(query.Limit),
+	}
+
+	res, err := h.Usecase.Search(c.UserContext(), req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(web.BaseResponse{
+		Status: message.Success,
+		Message: message.Success,
+		Data: res.Hits,
+	})
+}
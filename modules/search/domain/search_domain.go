@@ -0,0 +1,65 @@
+package domain
+
+import (
+	"strings"
+)
+
+const (
+	Model = "Search"
+)
+
+// Type, what a hit is
+const (
+	TypeActivity = "activity"
+	TypeTodo = "todo"
+)
+
+var (
+	TypeAllList = []string{
+		TypeActivity,
+		TypeTodo,
+	}
+)
+
+// Limit, the number of hits returned
+const (
+	LimitDefault = 20
+	LimitMax = 100
+)
+
+// TermsMax bounds the words of a query the portable search matches, each of them is a LIKE
+const TermsMax = 10
+
+// Hit is an activity group or a todo whose title matches the query, Score ranks it against the others
+type Hit struct {
+	Type            string  `json:"type"`
+	ID              int64   `json:"id"`
+	Title           string  `json:"title"`
+	ActivityGroupID *int64  `json:"activity_group_id,omitempty"`
+	Score           float64 `json:"score"`
+}
+
+// Terms are the lower case words of a query, as the portable search matches them
+func Terms(query string) []string {
+	terms := strings.Fields(strings.ToLower(query))
+	if len(terms) > TermsMax {
+		terms = terms[:TermsMax]
+	}
+
+	return terms
+}
+
+// Search
+
+type SearchRequest struct {
+	Query string
+
+	// Types is some of TypeAllList, all of them when empty
+	Types []string
+
+	Limit int
+}
+
+type SearchResponse struct {
+	Hits []Hit
+}
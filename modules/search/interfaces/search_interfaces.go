@@ -0,0 +1,15 @@
+package interfaces
+
+import (
+	"github.com/fahmiaz411/devcode/modules/search/domain"
+
+	"context"
+)
+
+type SearchUsecase interface {
+	Search(ctx context.Context, req domain.SearchRequest) (res domain.SearchResponse, err error)
+}
+
+type SearchRepository interface {
+	Search(ctx context.Context, req domain.SearchRequest) (res domain.SearchResponse, err error)
+}
@@ -0,0 +1,97 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/fahmiaz411/devcode/config/database"
+	"github.com/fahmiaz411/devcode/helper/slice"
+	activityDomain "github.com/fahmiaz411/devcode/modules/activity/domain"
+	"github.com/fahmiaz411/devcode/modules/search/domain"
+	"github.com/fahmiaz411/devcode/modules/search/interfaces"
+	todoDomain "github.com/fahmiaz411/devcode/modules/todo/domain"
+)
+
+const (
+	activities = "activities"
+	todos      = "todos"
+)
+
+type MemoryRepository struct {
+	DB *database.MemoryDB
+}
+
+func NewMemoryRepository(DB *database.MemoryDB) interfaces.SearchRepository {
+	return &MemoryRepository{
+		DB: DB,
+	}
+}
+
+// Search ranks the titles as the portable SQL search does, by the terms of the query they contain
+func (m *MemoryRepository) Search(ctx context.Context, req domain.SearchRequest) (res domain.SearchResponse, err error) {
+	defer m.DB.Read(ctx)()
+
+	patterns := domain.Terms(req.Query)
+	if len(patterns) > 1 {
+		patterns = append(patterns, strings.Join(patterns, " "))
+	}
+
+	score := func(title string) (score float64) {
+		title = strings.ToLower(title)
+		for _, pattern := range patterns {
+			if strings.Contains(title, pattern) {
+				score++
+			}
+		}
+
+		return
+	}
+
+	res.Hits = []domain.Hit{}
+
+	if slice.Includes(req.Types, domain.TypeActivity) {
+		for _, row := range m.DB.Table(activities).Rows {
+			if act := row.(activityDomain.Activity); act.DeletedAt == nil && score(act.Title) > 0 {
+				res.Hits = append(res.Hits, domain.Hit{
+					Type: domain.TypeActivity,
+					ID: act.ID,
+					Title: act.Title,
+					Score: score(act.Title),
+				})
+			}
+		}
+	}
+
+	if slice.Includes(req.Types, domain.TypeTodo) {
+		for _, row := range m.DB.Table(todos).Rows {
+			if todo := row.(todoDomain.Todo); todo.DeletedAt == nil && score(todo.Title) > 0 {
+				activityGroupID := todo.ActivityGroupID
+				res.Hits = append(res.Hits, domain.Hit{
+					Type: domain.TypeTodo,
+					ID: todo.ID,
+					Title: todo.Title,
+					ActivityGroupID: &activityGroupID,
+					Score: score(todo.Title),
+				})
+			}
+		}
+	}
+
+	sort.Slice(res.Hits, func(i, j int) bool {
+		a, b := res.Hits[i], res.Hits[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		} else if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+
+		return a.ID < b.ID
+	})
+
+	if len(res.Hits) > req.Limit {
+		res.Hits = res.Hits[:req.Limit]
+	}
+
+	return
+}
@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"github.com/fahmiaz411/devcode/config/database"
+	"github.com/fahmiaz411/devcode/modules/search/interfaces"
+	"github.com/fahmiaz411/devcode/modules/search/repository/memory"
+	"github.com/fahmiaz411/devcode/modules/search/repository/sqlstore"
+)
+
+type Repository struct {
+	Store interfaces.SearchRepository
+}
+
+// NewRepository constructor, the SQL drivers share a store speaking their dialect
+func NewRepository(db *database.Database) *Repository {
+	var store interfaces.SearchRepository
+
+	switch db.Driver {
+	case database.DriverMemory:
+		store = memory.NewMemoryRepository(db.Memory)
+	default:
+		store = sqlstore.NewSqlRepository(db.Conn, database.Dialect(db.Driver))
+	}
+
+	return &Repository{
+		Store: store,
+	}
+}
@@ -0,0 +1,49 @@
+package sqlstore
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/fahmiaz411/devcode/config/database"
+	"github.com/fahmiaz411/devcode/modules/search/domain"
+)
+
+// indexed tells whether the title of every table searched has a MySQL FULLTEXT index, scores of both kinds do not rank together
+func (m *SqlRepository) indexed(ctx context.Context, types []string) (indexed bool, err error) {
+	names := []any{}
+	for _, t := range types {
+		names = append(names, tables[t].name)
+	}
+
+	var count int
+	if err = m.conn(ctx).QueryRowContext(ctx, fmt.Sprintf(`
+		SELECT COUNT(DISTINCT table_name)
+		FROM information_schema.statistics
+		WHERE table_schema = DATABASE() AND index_type = 'FULLTEXT' AND column_name = 'title' AND table_name IN (%s)
+	`, placeholders(len(names))), names...).Scan(&count); err != nil {
+		return
+	}
+
+	indexed = count == len(names)
+
+	return
+}
+
+// fulltext ranks the titles by the relevance the MySQL FULLTEXT indexes give them for the query
+func fulltext(req domain.SearchRequest) (query string, values []any) {
+	selects := []string{}
+	for _, t := range req.Types {
+		selects = append(selects, fmt.Sprintf(`
+			SELECT '%s' AS hit_type, %s AS id, %s AS activity_group_id, title, MATCH(title) AGAINST (?) AS score
+			FROM %s
+			WHERE deleted_at IS NULL AND MATCH(title) AGAINST (?)
+		`, t, tables[t].id, activityGroupID(database.DriverMysql, t), tables[t].name))
+
+		values = append(values, req.Query, req.Query)
+	}
+
+	query = hits(selects)
+	values = append(values, req.Limit)
+
+	return
+}
@@ -0,0 +1,84 @@
+package sqlstore
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fahmiaz411/devcode/config/database"
+	"github.com/fahmiaz411/devcode/modules/search/domain"
+)
+
+// table is where a type of hit is read from
+type table struct {
+	name    string
+	id      string
+	grouped bool
+}
+
+var tables = map[string]table{
+	domain.TypeActivity: {"activities", "activity_id", false},
+	domain.TypeTodo:     {"todos", "todo_id", true},
+}
+
+// activityGroupID is the activity_group_id of the hits of a type, typed for the UNION when the table has none
+func activityGroupID(dialect database.Dialect, t string) string {
+	if tables[t].grouped {
+		return "activity_group_id"
+	}
+
+	switch dialect {
+	case database.DriverMysql:
+		return "CAST(NULL AS SIGNED)"
+	case database.DriverPostgres:
+		return "CAST(NULL AS BIGINT)"
+	}
+
+	return "NULL"
+}
+
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+// hits ranks the hits of every select together, the best first
+func hits(selects []string) string {
+	return fmt.Sprintf(`
+		SELECT hit_type, id, activity_group_id, title, score FROM (
+			%s
+		) hits
+		WHERE score > 0
+		ORDER BY score DESC, hit_type, id
+		LIMIT ?
+	`, strings.Join(selects, " UNION ALL "))
+}
+
+// portable ranks the titles by the number of terms of the query they contain, the whole query counting once more
+func portable(dialect database.Dialect, req domain.SearchRequest) (query string, values []any) {
+	patterns := domain.Terms(req.Query)
+	if len(patterns) > 1 {
+		patterns = append(patterns, strings.Join(patterns, " "))
+	}
+
+	scores := make([]string, len(patterns))
+	for i := range patterns {
+		scores[i] = "CASE WHEN " + dialect.Like("LOWER(title)") + " THEN 1 ELSE 0 END"
+	}
+
+	selects := []string{}
+	for _, t := range req.Types {
+		selects = append(selects, fmt.Sprintf(`
+			SELECT '%s' AS hit_type, %s AS id, %s AS activity_group_id, title, %s AS score
+			FROM %s
+			WHERE deleted_at IS NULL
+		`, t, tables[t].id, activityGroupID(dialect, t), strings.Join(scores, " + "), tables[t].name))
+
+		for _, pattern := range patterns {
+			values = append(values, database.Contains(pattern))
+		}
+	}
+
+	query = hits(selects)
+	values = append(values, req.Limit)
+
+	return
+}
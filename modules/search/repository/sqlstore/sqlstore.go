@@ -0,0 +1,78 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/fahmiaz411/devcode/config/database"
+	"github.com/fahmiaz411/devcode/modules/search/domain"
+	"github.com/fahmiaz411/devcode/modules/search/interfaces"
+)
+
+type SqlRepository struct {
+	Conn *sql.DB
+	Dialect database.Dialect
+}
+
+func NewSqlRepository(Conn *sql.DB, Dialect database.Dialect) interfaces.SearchRepository {
+	return &SqlRepository{
+		Conn: Conn,
+		Dialect: Dialect,
+	}
+}
+
+// conn is the transaction of the unit of work ctx runs in, if any
+func (m *SqlRepository) conn(ctx context.Context) database.Executor {
+	return m.Dialect.Conn(ctx, m.Conn)
+}
+
+// Search matches the terms of the query, on MySQL it uses the FULLTEXT indexes when every table searched has one
+func (m *SqlRepository) Search(ctx context.Context, req domain.SearchRequest) (res domain.SearchResponse, err error) {
+	var indexed bool
+	if m.Dialect == database.DriverMysql {
+		if indexed, err = m.indexed(ctx, req.Types); err != nil {
+			return
+		}
+	}
+
+	query, values := portable(m.Dialect, req)
+	if indexed {
+		query, values = fulltext(req)
+	}
+
+	res.Hits, err = m.hits(ctx, query, values)
+
+	return
+}
+
+func (m *SqlRepository) hits(ctx context.Context, query string, values []any) (hits []domain.Hit, err error) {
+	hits = []domain.Hit{}
+
+	var rows *sql.Rows
+	rows, err = m.conn(ctx).QueryContext(ctx, query, values...)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			hit domain.Hit
+			activityGroupID sql.NullInt64
+		)
+
+		if err = rows.Scan(&hit.Type, &hit.ID, &activityGroupID, &hit.Title, &hit.Score); err != nil {
+			return
+		}
+
+		if activityGroupID.Valid {
+			hit.ActivityGroupID = &activityGroupID.Int64
+		}
+
+		hits = append(hits, hit)
+	}
+
+	err = rows.Err()
+
+	return
+}
@@ -0,0 +1,75 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/fahmiaz411/devcode/helper/constant"
+	"github.com/fahmiaz411/devcode/helper/failure"
+	"github.com/fahmiaz411/devcode/helper/field"
+	"github.com/fahmiaz411/devcode/helper/message"
+	"github.com/fahmiaz411/devcode/helper/slice"
+	"github.com/fahmiaz411/devcode/modules/search/domain"
+	"github.com/fahmiaz411/devcode/modules/search/interfaces"
+	"github.com/fahmiaz411/devcode/modules/search/repository"
+)
+
+type Usecase struct {
+	repo           *repository.Repository
+	contentTimeout time.Duration
+}
+
+func NewUsecase(repo *repository.Repository, timeout time.Duration) interfaces.SearchUsecase {
+	return &Usecase{
+		repo:           repo,
+		contentTimeout: timeout,
+	}
+}
+
+// Search finds the activity groups and the todos whose title matches the query, the best hits first
+func (u *Usecase) Search(ctx context.Context, req domain.SearchRequest) (res domain.SearchResponse, err error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contentTimeout)
+	defer cancel()
+
+	if req.Query = strings.TrimSpace(req.Query); req.Query == constant.EmptyString {
+		err = failure.Validation(message.CannotEmpty(field.Query))
+		return
+	}
+
+	if len(req.Types) == constant.ZeroValue {
+		req.Types = domain.TypeAllList
+	}
+
+	// A type given twice is searched once, its hits would come twice otherwise
+	types := []string{}
+	for _, t := range req.Types {
+		if !slice.Includes(domain.TypeAllList, t) {
+			err = failure.Validation(message.ShoudMatchEnum(field.Types, domain.TypeAllList))
+			return
+		}
+
+		if !slice.Includes(types, t) {
+			types = append(types, t)
+		}
+	}
+	req.Types = types
+
+	if req.Limit < constant.ZeroValue {
+		err = failure.Validation(message.CannotNegative(field.Limit))
+		return
+	} else if req.Limit > domain.LimitMax {
+		err = failure.Validation(message.TooMany(field.Limit, domain.LimitMax))
+		return
+	} else if req.Limit == constant.ZeroValue {
+		req.Limit = domain.LimitDefault
+	}
+
+	res, err = u.repo.Store.Search(ctx, req)
+	if err != nil {
+		err = failure.Internal(err)
+		return
+	}
+
+	return
+}
@@ -0,0 +1,74 @@
+package usecase
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/fahmiaz411/devcode/helper/failure"
+	"github.com/fahmiaz411/devcode/helper/field"
+	"github.com/fahmiaz411/devcode/helper/message"
+	"github.com/fahmiaz411/devcode/modules/search/domain"
+	"github.com/fahmiaz411/devcode/modules/search/repository"
+)
+
+// store keeps the request the usecase searched with
+type store struct {
+	req domain.SearchRequest
+}
+
+func (s *store) Search(ctx context.Context, req domain.SearchRequest) (res domain.SearchResponse, err error) {
+	s.req = req
+	return
+}
+
+func TestSearchTypes(t *testing.T) {
+	tests := []struct {
+		name  string
+		types []string
+		want  []string
+		err   string
+	}{
+		{
+			name: "all by default",
+			want: domain.TypeAllList,
+		},
+		{
+			name:  "repeated",
+			types: []string{domain.TypeTodo, domain.TypeTodo},
+			want:  []string{domain.TypeTodo},
+		},
+		{
+			name:  "repeated among others, first order kept",
+			types: []string{domain.TypeTodo, domain.TypeActivity, domain.TypeTodo},
+			want:  []string{domain.TypeTodo, domain.TypeActivity},
+		},
+		{
+			name:  "unknown",
+			types: []string{domain.TypeTodo, "tag"},
+			err:   message.ShoudMatchEnum(field.Types, domain.TypeAllList),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &store{}
+			u := NewUsecase(&repository.Repository{Store: s}, time.Second)
+
+			_, err := u.Search(context.Background(), domain.SearchRequest{Query: "milk", Types: tt.types})
+			if tt.err != "" {
+				if failure.KindOf(err) != failure.KindValidation || err.Error() != tt.err {
+					t.Fatalf("error = %v, want %q", err, tt.err)
+				}
+				return
+			} else if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(s.req.Types, tt.want) {
+				t.Errorf("types = %v, want %v", s.req.Types, tt.want)
+			}
+		})
+	}
+}
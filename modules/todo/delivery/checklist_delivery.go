@@ -0,0 +1,137 @@
+package delivery
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/fahmiaz411/devcode/helper/failure"
+	"github.com/fahmiaz411/devcode/helper/message"
+	"github.com/fahmiaz411/devcode/helper/params"
+	"github.com/fahmiaz411/devcode/helper/web"
+	"github.com/fahmiaz411/devcode/modules/todo/domain"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h *RESTHandler) GetChecklist(c *fiber.Ctx) error {
+	todoId, err := strconv.ParseInt(c.Params(params.TodoId), 10, 64)
+	if err != nil {
+		return failure.Validation(message.InvalidId(domain.Model))
+	}
+
+	res, err := h.Usecase.GetChecklist(c.UserContext(), domain.ChecklistGetAllRequest{
+		TodoID: todoId,
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(web.BaseResponse{
+		Status: message.Success,
+		Message: message.Success,
+		Data: res.Items,
+	})
+}
+
+func (h *RESTHandler) CreateChecklistItem(c *fiber.Ctx) error {
+	todoId, err := strconv.ParseInt(c.Params(params.TodoId), 10, 64)
+	if err != nil {
+		return failure.Validation(message.InvalidId(domain.Model))
+	}
+
+	req := domain.ChecklistCreateRequest{}
+	c.BodyParser(&req)
+	req.TodoID = todoId
+
+	res, err := h.Usecase.CreateChecklistItem(c.UserContext(), req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(http.StatusCreated).JSON(web.BaseResponse{
+		Status: message.Success,
+		Message: message.Success,
+		Data: res,
+	})
+}
+
+func (h *RESTHandler) UpdateChecklistItem(c *fiber.Ctx) error {
+	todoId, itemId, err := checklistItemIds(c)
+	if err != nil {
+		return err
+	}
+
+	req := domain.ChecklistUpdateRequest{}
+	c.BodyParser(&req)
+	req.ID, req.TodoID = itemId, todoId
+
+	res, err := h.Usecase.UpdateChecklistItem(c.UserContext(), req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(web.BaseResponse{
+		Status: message.Success,
+		Message: message.Success,
+		Data: res,
+	})
+}
+
+// MoveChecklistItem takes {"before": id} or {"after": id}, another item of the checklist
+func (h *RESTHandler) MoveChecklistItem(c *fiber.Ctx) error {
+	todoId, itemId, err := checklistItemIds(c)
+	if err != nil {
+		return err
+	}
+
+	req := domain.ChecklistMoveRequest{}
+	c.BodyParser(&req)
+	req.ID, req.TodoID = itemId, todoId
+
+	res, err := h.Usecase.MoveChecklistItem(c.UserContext(), req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(web.BaseResponse{
+		Status: message.Success,
+		Message: message.Success,
+		Data: res,
+	})
+}
+
+func (h *RESTHandler) DeleteChecklistItem(c *fiber.Ctx) error {
+	todoId, itemId, err := checklistItemIds(c)
+	if err != nil {
+		return err
+	}
+
+	res, err := h.Usecase.DeleteChecklistItem(c.UserContext(), domain.ChecklistDeleteRequest{
+		ID: itemId,
+		TodoID: todoId,
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(web.BaseResponse{
+		Status: message.Success,
+		Message: message.Success,
+		Data: res,
+	})
+}
+
+// checklistItemIds reads the todo and the checklist item of the path
+func checklistItemIds(c *fiber.Ctx) (todoId, itemId int64, err error) {
+	if todoId, err = strconv.ParseInt(c.Params(params.TodoId), 10, 64); err != nil {
+		err = failure.Validation(message.InvalidId(domain.Model))
+		return
+	}
+
+	if itemId, err = strconv.ParseInt(c.Params(params.ItemId), 10, 64); err != nil {
+		err = failure.Validation(message.InvalidId(domain.ChecklistModel))
+		return
+	}
+
+	return
+}
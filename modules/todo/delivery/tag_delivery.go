@@ -0,0 +1,109 @@
+package delivery
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/fahmiaz411/devcode/helper/failure"
+	"github.com/fahmiaz411/devcode/helper/message"
+	"github.com/fahmiaz411/devcode/helper/params"
+	"github.com/fahmiaz411/devcode/helper/query"
+	"github.com/fahmiaz411/devcode/helper/web"
+	"github.com/fahmiaz411/devcode/modules/todo/domain"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetAllTags lists the tags by name, ?name= narrows the list to some of them
+func (h *RESTHandler) GetAllTags(c *fiber.Ctx) error {
+	res, err := h.Usecase.GetAllTags(c.UserContext(), domain.TagGetAllRequest{
+		Names: web.QueryList(c, query.Name),
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(web.BaseResponse{
+		Status: message.Success,
+		Message: message.Success,
+		Data: res.Tags,
+	})
+}
+
+func (h *RESTHandler) GetOneTag(c *fiber.Ctx) error {
+	tagId, err := strconv.ParseInt(c.Params(params.TagId), 10, 64)
+	if err != nil {
+		return failure.Validation(message.InvalidId(domain.TagModel))
+	}
+
+	res, err := h.Usecase.GetOneTag(c.UserContext(), domain.TagGetOneRequest{
+		ID: tagId,
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(web.BaseResponse{
+		Status: message.Success,
+		Message: message.Success,
+		Data: res,
+	})
+}
+
+func (h *RESTHandler) CreateTag(c *fiber.Ctx) error {
+	req := domain.TagCreateRequest{}
+	c.BodyParser(&req)
+
+	res, err := h.Usecase.CreateTag(c.UserContext(), req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(http.StatusCreated).JSON(web.BaseResponse{
+		Status: message.Success,
+		Message: message.Success,
+		Data: res,
+	})
+}
+
+func (h *RESTHandler) UpdateTag(c *fiber.Ctx) error {
+	tagId, err := strconv.ParseInt(c.Params(params.TagId), 10, 64)
+	if err != nil {
+		return failure.Validation(message.InvalidId(domain.TagModel))
+	}
+
+	req := domain.TagUpdateRequest{}
+	c.BodyParser(&req)
+	req.ID = tagId
+
+	res, err := h.Usecase.UpdateTag(c.UserContext(), req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(web.BaseResponse{
+		Status: message.Success,
+		Message: message.Success,
+		Data: res,
+	})
+}
+
+func (h *RESTHandler) DeleteTag(c *fiber.Ctx) error {
+	tagId, err := strconv.ParseInt(c.Params(params.TagId), 10, 64)
+	if err != nil {
+		return failure.Validation(message.InvalidId(domain.TagModel))
+	}
+
+	res, err := h.Usecase.DeleteTag(c.UserContext(), domain.TagDeleteRequest{
+		ID: tagId,
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(web.BaseResponse{
+		Status: message.Success,
+		Message: message.Success,
+		Data: res,
+	})
+}
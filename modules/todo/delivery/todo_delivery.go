@@ -4,13 +4,15 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/fahmiaz411/devcode/helper/constant"
+	"github.com/fahmiaz411/devcode/helper/failure"
 	"github.com/fahmiaz411/devcode/helper/field"
 	"github.com/fahmiaz411/devcode/helper/message"
+	"github.com/fahmiaz411/devcode/helper/pagination"
 	"github.com/fahmiaz411/devcode/helper/params"
 	"github.com/fahmiaz411/devcode/helper/query"
-	"github.com/fahmiaz411/devcode/helper/slice"
 	"github.com/fahmiaz411/devcode/helper/web"
 	"github.com/fahmiaz411/devcode/modules/todo/domain"
 	"github.com/fahmiaz411/devcode/modules/todo/interfaces"
@@ -29,38 +31,76 @@ func NewRESTHandler(f fiber.Router, usecase interfaces.TodoUsecase) {
 
 	f.Post("/todo-items", handler.Create)
 
+	// Batches, registered before the :todoId routes
+	f.Post("/todo-items/bulk", handler.BulkCreate)
+
+	f.Patch("/todo-items/bulk", handler.BulkUpdate)
+
+	f.Delete("/todo-items/bulk", handler.BulkDelete)
+
 	f.Patch(fmt.Sprintf("/todo-items/:%s", params.TodoId), handler.Update)
 
 	f.Delete(fmt.Sprintf("/todo-items/:%s", params.TodoId), handler.Delete)
 
+	f.Post(fmt.Sprintf("/todo-items/:%s/move", params.TodoId), handler.Move)
+
+	// Checklist of a todo
+	f.Get(fmt.Sprintf("/todo-items/:%s/checklist", params.TodoId), handler.GetChecklist)
+
+	f.Post(fmt.Sprintf("/todo-items/:%s/checklist", params.TodoId), handler.CreateChecklistItem)
+
+	f.Patch(fmt.Sprintf("/todo-items/:%s/checklist/:%s", params.TodoId, params.ItemId), handler.UpdateChecklistItem)
+
+	f.Delete(fmt.Sprintf("/todo-items/:%s/checklist/:%s", params.TodoId, params.ItemId), handler.DeleteChecklistItem)
+
+	f.Post(fmt.Sprintf("/todo-items/:%s/checklist/:%s/move", params.TodoId, params.ItemId), handler.MoveChecklistItem)
+
+	// Tags, attached and detached by a todo change
+	f.Get("/tags", handler.GetAllTags)
+
+	f.Post("/tags", handler.CreateTag)
+
+	f.Get(fmt.Sprintf("/tags/:%s", params.TagId), handler.GetOneTag)
+
+	f.Patch(fmt.Sprintf("/tags/:%s", params.TagId), handler.UpdateTag)
+
+	f.Delete(fmt.Sprintf("/tags/:%s", params.TagId), handler.DeleteTag)
+
 	f.Get("/todo-items", handler.GetAll)
 
+	// Trash, registered before the :todoId routes
+	f.Get("/todo-items/trash", handler.GetTrash)
+
+	f.Post(fmt.Sprintf("/todo-items/trash/:%s/restore", params.TodoId), handler.Restore)
+
+	f.Delete(fmt.Sprintf("/todo-items/trash/:%s", params.TodoId), handler.Purge)
+
+	// Stats, registered before the :todoId routes
+	f.Get("/todo-items/stats", handler.Stats)
+
 	f.Get(fmt.Sprintf("/todo-items/:%s", params.TodoId), handler.GetOne)
+
+	// Nested under the activity group, which must exist
+	f.Get(fmt.Sprintf("/activity-groups/:%s/todo-items", params.ActivityId), handler.GetAllByActivity)
+
+	f.Post(fmt.Sprintf("/activity-groups/:%s/todo-items", params.ActivityId), handler.CreateByActivity)
+
+	f.Get(fmt.Sprintf("/activity-groups/:%s/todo-items/count", params.ActivityId), handler.CountByActivity)
+
+	f.Get(fmt.Sprintf("/activity-groups/:%s/todo-items/stats", params.ActivityId), handler.StatsByActivity)
 }
 
 func (h *RESTHandler) Create(c *fiber.Ctx) error {
 	req := domain.TodoCreateRequest{}
 	c.BodyParser(&req)
 
-	if req.Title == constant.EmptyString {
-		return c.Status(http.StatusBadRequest).JSON(web.BaseResponse{
-			Status: http.StatusText(http.StatusBadRequest),
-			Message: message.CanotNull(field.Title),
-			Data: struct{}{},
-		})
-	} else if req.ActivityGroupID == int64(constant.ZeroValue) {
-		return c.Status(http.StatusBadRequest).JSON(web.BaseResponse{
-			Status: http.StatusText(http.StatusBadRequest),
-			Message: message.CanotNull(field.ActivityGroupID),
-			Data: struct{}{},
-		})
-	}
-
-	res, err := h.Usecase.Create(c, req)
+	res, err := h.Usecase.Create(c.UserContext(), req)
 	if err != nil {
-		return nil
+		return err
 	}
 
+	c.Set(fiber.HeaderETag, web.ETag(res.Version))
+
 	return c.Status(http.StatusCreated).JSON(web.BaseResponse{
 		Status: message.Success,
 		Message: message.Success,
@@ -71,45 +111,22 @@ func (h *RESTHandler) Create(c *fiber.Ctx) error {
 func (h *RESTHandler) Update(c *fiber.Ctx) error {
 	todoId, err := strconv.ParseInt(c.Params(params.TodoId), 10, 64)
 	if err != nil {
-		return c.Status(http.StatusBadRequest).JSON(web.BaseResponse{
-			Status: http.StatusText(http.StatusBadRequest),
-			Message: message.InvalidId(domain.Model),
-			Data: struct{}{},
-		})
+		return failure.Validation(message.InvalidId(domain.Model))
 	}
 
 	req := domain.TodoUpdateRequest{
 		ID: todoId,
+		Version: web.IfMatch(c),
 	}
 	c.BodyParser(&req)
 
-	if (
-		req.Title == constant.EmptyString && 
-		req.IsActive == nil &&
-		req.Priority == constant.EmptyString) {
-
-		return c.Status(http.StatusBadRequest).JSON(web.BaseResponse{
-			Status: http.StatusText(http.StatusBadRequest), 
-			Message: message.InvalidRequestBody,
-			Data: struct{}{},
-		})
-	}
-
-	if req.Priority != constant.EmptyString {
-		if !slice.Includes(domain.PriorityAllList, req.Priority) {
-			return c.Status(http.StatusBadRequest).JSON(web.BaseResponse{
-				Status: http.StatusText(http.StatusBadRequest), 
-				Message: message.ShoudMatchEnum(field.Priority, domain.PriorityAllList),
-				Data: struct{}{},
-			})
-		}
-	}
-
-	res, err := h.Usecase.Update(c, req)
+	res, err := h.Usecase.Update(c.UserContext(), req)
 	if err != nil {
-		return nil
+		return err
 	}
 
+	c.Set(fiber.HeaderETag, web.ETag(res.Version))
+
 	return c.JSON(web.BaseResponse{
 		Status: message.Success,
 		Message: message.Success,
@@ -120,22 +137,46 @@ func (h *RESTHandler) Update(c *fiber.Ctx) error {
 func (h *RESTHandler) Delete(c *fiber.Ctx) error {
 	todoId, err := strconv.ParseInt(c.Params(params.TodoId), 10, 64)
 	if err != nil {
-		return c.Status(http.StatusBadRequest).JSON(web.BaseResponse{
-			Status: http.StatusText(http.StatusBadRequest),
-			Message: message.InvalidId(domain.Model),
-			Data: struct{}{},
-		})
+		return failure.Validation(message.InvalidId(domain.Model))
 	}
 
 	req := domain.TodoDeleteRequest{
 		ID: todoId,
+		Version: web.IfMatch(c),
+	}
+
+	res, err := h.Usecase.Delete(c.UserContext(), req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(web.BaseResponse{
+		Status: message.Success,
+		Message: message.Success,
+		Data: res,
+	})
+}
+
+// Move takes {"before": id} or {"after": id}, another todo of the activity group
+func (h *RESTHandler) Move(c *fiber.Ctx) error {
+	todoId, err := strconv.ParseInt(c.Params(params.TodoId), 10, 64)
+	if err != nil {
+		return failure.Validation(message.InvalidId(domain.Model))
+	}
+
+	req := domain.TodoMoveRequest{
+		ID: todoId,
+		Version: web.IfMatch(c),
 	}
+	c.BodyParser(&req)
 
-	res, err := h.Usecase.Delete(c, req)
+	res, err := h.Usecase.Move(c.UserContext(), req)
 	if err != nil {
-		return nil
+		return err
 	}
 
+	c.Set(fiber.HeaderETag, web.ETag(res.Version))
+
 	return c.JSON(web.BaseResponse{
 		Status: message.Success,
 		Message: message.Success,
@@ -143,14 +184,221 @@ func (h *RESTHandler) Delete(c *fiber.Ctx) error {
 	})
 }
 
+func (h *RESTHandler) Restore(c *fiber.Ctx) error {
+	todoId, err := strconv.ParseInt(c.Params(params.TodoId), 10, 64)
+	if err != nil {
+		return failure.Validation(message.InvalidId(domain.Model))
+	}
+
+	req := domain.TodoRestoreRequest{
+		ID: todoId,
+	}
+
+	res, err := h.Usecase.Restore(c.UserContext(), req)
+	if err != nil {
+		return err
+	}
+
+	c.Set(fiber.HeaderETag, web.ETag(res.Version))
+
+	return c.JSON(web.BaseResponse{
+		Status: message.Success,
+		Message: message.Success,
+		Data: res,
+	})
+}
+
+func (h *RESTHandler) Purge(c *fiber.Ctx) error {
+	todoId, err := strconv.ParseInt(c.Params(params.TodoId), 10, 64)
+	if err != nil {
+		return failure.Validation(message.InvalidId(domain.Model))
+	}
+
+	req := domain.TodoPurgeRequest{
+		ID: todoId,
+	}
+
+	res, err := h.Usecase.Purge(c.UserContext(), req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(web.BaseResponse{
+		Status: message.Success,
+		Message: message.Success,
+		Data: res,
+	})
+}
+
+func (h *RESTHandler) BulkCreate(c *fiber.Ctx) error {
+	req := domain.TodoBulkCreateRequest{}
+	if err := c.BodyParser(&req.Todos); err != nil {
+		return failure.Validation(message.InvalidRequestBody)
+	}
+
+	res, err := h.Usecase.BulkCreate(c.UserContext(), req)
+	if err != nil {
+		return err
+	}
+
+	return bulkResponse(c, http.StatusCreated, res)
+}
+
+func (h *RESTHandler) BulkUpdate(c *fiber.Ctx) error {
+	req := domain.TodoBulkUpdateRequest{}
+	if err := c.BodyParser(&req.Todos); err != nil {
+		return failure.Validation(message.InvalidRequestBody)
+	}
+
+	res, err := h.Usecase.BulkUpdate(c.UserContext(), req)
+	if err != nil {
+		return err
+	}
+
+	return bulkResponse(c, http.StatusOK, res)
+}
+
+func (h *RESTHandler) BulkDelete(c *fiber.Ctx) error {
+	req := domain.TodoBulkDeleteRequest{}
+	if err := c.BodyParser(&req.Todos); err != nil {
+		return failure.Validation(message.InvalidRequestBody)
+	}
+
+	res, err := h.Usecase.BulkDelete(c.UserContext(), req)
+	if err != nil {
+		return err
+	}
+
+	return bulkResponse(c, http.StatusOK, res)
+}
+
+// bulkResponse writes the outcome of each todo of a batch, in the order of the request
+func bulkResponse(c *fiber.Ctx, code int, res domain.TodoBulkResponse) error {
+	results := make([]web.BulkResult, len(res.Results))
+	for i, result := range res.Results {
+		results[i].Err = result.Err
+		if result.Todo != nil {
+			results[i].Data = result.Todo
+		}
+	}
+
+	return web.Bulk(c, code, results)
+}
+
 func (h *RESTHandler) GetAll(c *fiber.Ctx) error {
-	req := domain.TodoGetAllRequest{		
+	return h.list(c, domain.TodoGetAllRequest{
 		ActivityGroupID: int64(c.QueryInt(query.ActivityGroupID)),
+	})
+}
+
+func (h *RESTHandler) GetTrash(c *fiber.Ctx) error {
+	return h.list(c, domain.TodoGetAllRequest{
+		Trashed: true,
+		ActivityGroupID: int64(c.QueryInt(query.ActivityGroupID)),
+	})
+}
+
+func (h *RESTHandler) GetAllByActivity(c *fiber.Ctx) error {
+	activityId, err := strconv.ParseInt(c.Params(params.ActivityId), 10, 64)
+	if err != nil {
+		return failure.Validation(message.InvalidId(domain.ActivityModel))
+	}
+
+	return h.list(c, domain.TodoGetAllRequest{
+		ActivityGroupID: activityId,
+		CheckActivity: true,
+	})
+}
+
+func (h *RESTHandler) CreateByActivity(c *fiber.Ctx) error {
+	activityId, err := strconv.ParseInt(c.Params(params.ActivityId), 10, 64)
+	if err != nil {
+		return failure.Validation(message.InvalidId(domain.ActivityModel))
+	}
+
+	req := domain.TodoCreateRequest{}
+	c.BodyParser(&req)
+
+	// The path names the activity group, the body cannot move the todo elsewhere
+	req.ActivityGroupID = activityId
+	req.CheckActivity = true
+
+	res, err := h.Usecase.Create(c.UserContext(), req)
+	if err != nil {
+		return err
+	}
+
+	c.Set(fiber.HeaderETag, web.ETag(res.Version))
+
+	return c.Status(http.StatusCreated).JSON(web.BaseResponse{
+		Status: message.Success,
+		Message: message.Success,
+		Data: res,
+	})
+}
+
+// CountByActivity counts the todos of an activity group with the filters of GetAll, paging aside
+func (h *RESTHandler) CountByActivity(c *fiber.Ctx) error {
+	activityId, err := strconv.ParseInt(c.Params(params.ActivityId), 10, 64)
+	if err != nil {
+		return failure.Validation(message.InvalidId(domain.ActivityModel))
+	}
+
+	req := domain.TodoGetAllRequest{
+		ActivityGroupID: activityId,
+		CheckActivity: true,
+	}
+	if err = filter(c, &req); err != nil {
+		return err
+	}
+
+	stamp, err := h.Usecase.GetAllStamp(c.UserContext(), req)
+	if err != nil {
+		return err
+	}
+
+	if web.NotModified(c, web.ListETag(c, stamp.Stamp), stamp.LastModified) {
+		return c.SendStatus(http.StatusNotModified)
+	}
+
+	return c.JSON(web.BaseResponse{
+		Status: message.Success,
+		Message: message.Success,
+		Data: domain.TodoCountResponse{
+			Count: stamp.Count,
+		},
+	})
+}
+
+func (h *RESTHandler) Stats(c *fiber.Ctx) error {
+	return h.stats(c, domain.TodoStatsRequest{})
+}
+
+func (h *RESTHandler) StatsByActivity(c *fiber.Ctx) error {
+	activityId, err := strconv.ParseInt(c.Params(params.ActivityId), 10, 64)
+	if err != nil {
+		return failure.Validation(message.InvalidId(domain.ActivityModel))
+	}
+
+	return h.stats(c, domain.TodoStatsRequest{
+		ActivityGroupID: activityId,
+		CheckActivity: true,
+	})
+}
+
+// stats serves Stats and StatsByActivity over the days from and to, both included
+func (h *RESTHandler) stats(c *fiber.Ctx, req domain.TodoStatsRequest) (err error) {
+	if req.From, err = queryTime(c, query.From, false); err != nil {
+		return failure.Validation(message.InvalidDate(query.From))
+	}
+
+	if req.To, err = queryTime(c, query.To, true); err != nil {
+		return failure.Validation(message.InvalidDate(query.To))
 	}
 
-	res, err := h.Usecase.GetAll(c, req)
+	res, err := h.Usecase.Stats(c.UserContext(), req)
 	if err != nil {
-		return nil
+		return err
 	}
 
 	return c.JSON(web.BaseResponse{
@@ -160,23 +408,98 @@ func (h *RESTHandler) GetAll(c *fiber.Ctx) error {
 	})
 }
 
+// list serves GetAll, GetTrash and GetAllByActivity, req tells which rows they list
+func (h *RESTHandler) list(c *fiber.Ctx, req domain.TodoGetAllRequest) error {
+	if err := filter(c, &req); err != nil {
+		return err
+	}
+
+	req.Request = pagination.Request{
+		Limit: c.QueryInt(query.Limit),
+		Offset: c.QueryInt(query.Offset),
+		Cursor: c.Query(query.Cursor),
+		Sort: c.Query(query.Sort),
+		Order: c.Query(query.Order),
+	}
+
+	// Polling clients get a 304 without the list being read
+	stamp, err := h.Usecase.GetAllStamp(c.UserContext(), req)
+	if err != nil {
+		return err
+	}
+
+	if web.NotModified(c, web.ListETag(c, stamp.Stamp), stamp.LastModified) {
+		return c.SendStatus(http.StatusNotModified)
+	}
+
+	res, err := h.Usecase.GetAll(c.UserContext(), req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(web.BaseResponse{
+		Status: message.Success,
+		Message: message.Success,
+		Data: res.Todos,
+		Paging: &res.Paging,
+	})
+}
+
+// filter reads the query filters of a list into req
+func filter(c *fiber.Ctx, req *domain.TodoGetAllRequest) error {
+	req.Priorities = web.QueryList(c, query.Priority)
+	req.Tags = web.QueryList(c, query.Tag)
+	req.Title = c.Query(query.Title)
+	req.Due = c.Query(query.Due)
+
+	if isActive := c.Query(query.IsActive); isActive != constant.EmptyString {
+		value, err := strconv.ParseBool(isActive)
+		if err != nil {
+			return failure.Validation(message.InvalidBoolean(field.IsActive))
+		}
+		req.IsActive = &value
+	}
+
+	dates := []struct {
+		key   string
+		upper bool
+		value **time.Time
+	}{
+		{query.CreatedFrom, false, &req.CreatedFrom},
+		{query.CreatedTo, true, &req.CreatedTo},
+		{query.UpdatedFrom, false, &req.UpdatedFrom},
+		{query.UpdatedTo, true, &req.UpdatedTo},
+		{query.DueFrom, false, &req.DueFrom},
+		{query.DueTo, true, &req.DueTo},
+	}
+	for _, date := range dates {
+		value, err := queryTime(c, date.key, date.upper)
+		if err != nil {
+			return failure.Validation(message.InvalidDate(date.key))
+		}
+		*date.value = value
+	}
+
+	return nil
+}
+
 func (h *RESTHandler) GetOne(c *fiber.Ctx) error {
 	todoId, err := strconv.ParseInt(c.Params(params.TodoId), 10, 64)
 	if err != nil {
-		return c.Status(http.StatusBadRequest).JSON(web.BaseResponse{
-			Status: http.StatusText(http.StatusBadRequest),
-			Message: message.InvalidId(domain.Model),
-			Data: struct{}{},
-		})
+		return failure.Validation(message.InvalidId(domain.Model))
 	}
 
 	req := domain.TodoGetOneRequest{
 		ID: todoId,
 	}
 
-	res, err := h.Usecase.GetOne(c, req)
+	res, err := h.Usecase.GetOne(c.UserContext(), req)
 	if err != nil {
-		return nil
+		return err
+	}
+
+	if web.NotModified(c, web.ETag(res.Version), res.UpdatedAt) {
+		return c.SendStatus(http.StatusNotModified)
 	}
 
 	return c.JSON(web.BaseResponse{
@@ -184,4 +507,27 @@ func (h *RESTHandler) GetOne(c *fiber.Ctx) error {
 		Message: message.Success,
 		Data: res,
 	})
+}
+
+
+// queryTime reads a date (YYYY-MM-DD) or an RFC 3339 time, an upper bound date covers that whole day
+func queryTime(c *fiber.Ctx, key string, upper bool) (res *time.Time, err error) {
+	value := c.Query(key)
+	if value == constant.EmptyString {
+		return
+	}
+
+	var t time.Time
+	if t, err = time.Parse(time.DateOnly, value); err == nil {
+		if upper {
+			t = t.AddDate(0, 0, 1)
+		}
+	} else if t, err = time.Parse(time.RFC3339, value); err != nil {
+		return
+	}
+
+	t = t.UTC()
+	res = &t
+
+	return
 }
\ No newline at end of file
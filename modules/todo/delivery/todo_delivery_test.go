@@ -0,0 +1,215 @@
+package delivery
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/fahmiaz411/devcode/config/database"
+	"github.com/fahmiaz411/devcode/helper/web"
+	"github.com/fahmiaz411/devcode/modules/todo/domain"
+	"github.com/fahmiaz411/devcode/modules/todo/interfaces"
+	"github.com/fahmiaz411/devcode/modules/todo/repository"
+	"github.com/fahmiaz411/devcode/modules/todo/usecase"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// activities is a lookup of the activity groups by ID to their email
+type activities map[int64]string
+
+func (a activities) Exists(ctx context.Context, id int64) (exists bool, err error) {
+	_, exists = a[id]
+	return
+}
+
+func (a activities) Email(ctx context.Context, id int64) (email string, err error) {
+	return a[id], nil
+}
+
+// newApp serves the todo routes of a usecase on the memory driver, with activity group 1
+func newApp() (app *fiber.App, u interfaces.TodoUsecase) {
+	db := database.NewDatabase(database.Config{Driver: database.DriverMemory})
+	u = usecase.NewUsecase(repository.NewRepository(db), time.Second, activities{1: ""})
+
+	app = fiber.New(fiber.Config{ErrorHandler: web.ErrorHandler})
+	NewRESTHandler(app, u)
+
+	return
+}
+
+// get runs a GET of target, conditional on etag unless it is empty
+func get(t *testing.T, app *fiber.App, target, etag string) *http.Response {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	if etag != "" {
+		req.Header.Set(fiber.HeaderIfNoneMatch, etag)
+	}
+
+	res, err := app.Test(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	return res
+}
+
+func TestConditionalGetAfterRenumber(t *testing.T) {
+	ctx := context.Background()
+	app, u := newApp()
+
+	var ids []int64
+	for _, title := range []string{"anchor", "b", "c"} {
+		res, err := u.Create(ctx, domain.TodoCreateRequest{Title: title, ActivityGroupID: 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, res.ID)
+	}
+
+	// The anchor alone, only the renumbering changes it
+	one := fmt.Sprintf("/todo-items/%d", ids[0])
+	list := "/todo-items?title=anchor"
+
+	etags := map[string]string{}
+	for _, target := range []string{one, list} {
+		res := get(t, app, target, "")
+		if res.StatusCode != http.StatusOK || res.Header.Get(fiber.HeaderETag) == "" {
+			t.Fatalf("%s: status = %d, etag = %q", target, res.StatusCode, res.Header.Get(fiber.HeaderETag))
+		}
+		etags[target] = res.Header.Get(fiber.HeaderETag)
+
+		if res = get(t, app, target, etags[target]); res.StatusCode != http.StatusNotModified {
+			t.Fatalf("%s: unchanged status = %d, want %d", target, res.StatusCode, http.StatusNotModified)
+		}
+	}
+
+	// Moving b and c in turn right after the anchor halves the gap each time, until the group is renumbered
+	for i := 0; i < 12; i++ {
+		if _, err := u.Move(ctx, domain.TodoMoveRequest{ID: ids[i%2+1], After: &ids[0]}); err != nil {
+			t.Fatalf("move %d: %v", i, err)
+		}
+	}
+
+	for target, etag := range etags {
+		res := get(t, app, target, etag)
+		if res.StatusCode != http.StatusOK {
+			t.Errorf("%s: status = %d after the renumbering, want %d", target, res.StatusCode, http.StatusOK)
+		}
+
+		if got := res.Header.Get(fiber.HeaderETag); got == etag {
+			t.Errorf("%s: etag %s unchanged by the renumbering", target, got)
+		}
+	}
+}
+
+func TestTagFilter(t *testing.T) {
+	ctx := context.Background()
+	app, u := newApp()
+
+	for _, name := range []string{"work", "home"} {
+		if _, err := u.CreateTag(ctx, domain.TagCreateRequest{Name: name}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for title, tags := range map[string][]string{"a": {"work"}, "b": {"home"}, "c": nil} {
+		res, err := u.Create(ctx, domain.TodoCreateRequest{Title: title, ActivityGroupID: 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if tags == nil {
+			continue
+		}
+
+		if _, err = u.Update(ctx, domain.TodoUpdateRequest{ID: res.ID, AttachTags: tags}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Repeated or comma separated, any of the tags, in any case
+	tests := []struct {
+		query string
+		want  int
+	}{
+		{query: "tag=work", want: 1},
+		{query: "tag=WORK", want: 1},
+		{query: "tag=work&tag=home", want: 2},
+		{query: "tag=work,home", want: 2},
+		{query: "tag=errands", want: 0},
+		{query: "", want: 3},
+	}
+
+	for _, tt := range tests {
+		res, err := app.Test(httptest.NewRequest(http.MethodGet, "/todo-items?"+tt.query, nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var body struct {
+			Data []domain.Todo `json:"data"`
+		}
+		err = json.NewDecoder(res.Body).Decode(&body)
+		res.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(body.Data) != tt.want {
+			t.Errorf("%s: %d todos, want %d", tt.query, len(body.Data), tt.want)
+		}
+	}
+}
+
+func TestQueryTime(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		upper bool
+		want  string
+		err   bool
+	}{
+		{name: "none"},
+		{name: "date, lower bound", value: "2030-01-31", want: "2030-01-31T00:00:00Z"},
+		{name: "date, upper bound covers the day", value: "2030-01-31", upper: true, want: "2030-02-01T00:00:00Z"},
+		{name: "date, upper bound over the year", value: "2030-12-31", upper: true, want: "2031-01-01T00:00:00Z"},
+		{name: "time, as given", value: "2030-01-31T09:30:00Z", upper: true, want: "2030-01-31T09:30:00Z"},
+		{name: "time, in UTC", value: "2030-01-31T09:30:00+07:00", want: "2030-01-31T02:30:00Z"},
+		{name: "invalid", value: "31/01/2030", err: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var res *time.Time
+			var err error
+
+			app := fiber.New()
+			app.Get("/", func(c *fiber.Ctx) error {
+				res, err = queryTime(c, "at", tt.upper)
+				return nil
+			})
+
+			get(t, app, "/?at="+url.QueryEscape(tt.value), "")
+			if (err != nil) != tt.err {
+				t.Fatalf("error = %v, want one %t", err, tt.err)
+			}
+
+			got := ""
+			if res != nil {
+				got = res.Format(time.RFC3339)
+			}
+
+			if got != tt.want {
+				t.Errorf("time = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package domain
+
+import (
+	"time"
+)
+
+const (
+	ChecklistModel = "Checklist item"
+
+	// ChecklistLimit bounds the items of a todo, a move may renumber all of them
+	ChecklistLimit = 100
+)
+
+// ChecklistItem is a step of a todo, its progress counts the items done.
+// Completing the todo checks every item off, unchecking one or adding one reopens it.
+// The items go to the trash and back with the todo, and are purged with it.
+type ChecklistItem struct {
+	ID        int64     `json:"id"`
+	TodoID    int64     `json:"todo_id"`
+	Title     string    `json:"title"`
+	IsDone    bool      `json:"is_done"`
+	Position  int64     `json:"position"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Progress is the percentage of done items, rounded down, nil for a todo without checklist
+func Progress(total, done int64) *int {
+	if total == 0 {
+		return nil
+	}
+
+	progress := int(done * 100 / total)
+
+	return &progress
+}
+
+// SetChecklist writes the item counts of the todo and its progress
+func (t *Todo) SetChecklist(total, done int64) {
+	t.ChecklistTotal = total
+	t.ChecklistDone = done
+	t.Progress = Progress(total, done)
+}
+
+// Create
+
+type ChecklistCreateRequest struct {
+	TodoID   int64  `json:"-"`
+	Title    string `json:"title"`
+	IsDone   bool   `json:"is_done"`
+	Position int64  `json:"-"`
+}
+
+type ChecklistCreateResponse struct {
+	ChecklistItem
+}
+
+// Update
+
+type ChecklistUpdateRequest struct {
+	ID        int64     `json:"-"`
+	TodoID    int64     `json:"-"`
+	Title     string    `json:"title"`
+	IsDone    *bool     `json:"is_done"`
+	Position  *int64    `json:"-"`
+	UpdatedAt time.Time `json:"-"`
+}
+
+type ChecklistUpdateResponse struct {
+	ChecklistItem
+}
+
+// Move, an item put right before or right after another of its todo
+
+type ChecklistMoveRequest struct {
+	ID     int64  `json:"-"`
+	TodoID int64  `json:"-"`
+	Before *int64 `json:"before"`
+	After  *int64 `json:"after"`
+}
+
+type ChecklistMoveResponse struct {
+	ChecklistItem
+}
+
+// Check, every item of a todo done
+
+type ChecklistCheckRequest struct {
+	TodoID    int64
+	UpdatedAt time.Time
+}
+
+type ChecklistCheckResponse struct {
+}
+
+// Delete
+
+type ChecklistDeleteRequest struct {
+	ID     int64
+	TodoID int64
+}
+
+type ChecklistDeleteResponse struct {
+}
+
+// Get All, the items of a todo in their order
+
+type ChecklistGetAllRequest struct {
+	TodoID int64
+}
+
+type ChecklistGetAllResponse struct {
+	Items []ChecklistItem
+}
+
+// Touch, a new version of a todo whose checklist changed
+
+type TodoTouchRequest struct {
+	ID        int64
+	Version   int64
+	UpdatedAt time.Time
+}
+
+type TodoTouchResponse struct {
+}
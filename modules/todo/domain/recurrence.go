@@ -0,0 +1,203 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Frequency, how often a recurring todo comes back
+const (
+	FrequencyDaily   = "daily"
+	FrequencyWeekly  = "weekly"
+	FrequencyMonthly = "monthly"
+)
+
+var (
+	FrequencyAllList = []string{
+		FrequencyDaily,
+		FrequencyWeekly,
+		FrequencyMonthly,
+	}
+
+	// WeekdayAllList are the RRULE weekdays, from Monday as the week starts
+	WeekdayAllList = []string{"MO", "TU", "WE", "TH", "FR", "SA", "SU"}
+)
+
+// IntervalMax bounds the interval of a recurrence, a year of days
+const IntervalMax = 366
+
+// Recurrence is the schedule of a recurring todo, stored as an RRULE.
+// Completing an occurrence creates the next one, until the Count-th occurrence or the last date before Until.
+type Recurrence struct {
+	Frequency string `json:"frequency"`
+
+	// Interval is every how many days, weeks or months, 1 when left out
+	Interval int `json:"interval"`
+
+	// Weekdays are the days of a weekly recurrence, those of WeekdayAllList
+	Weekdays []string `json:"by_weekday,omitempty"`
+
+	// MonthDay is the day of a monthly recurrence, the last day of shorter months.
+	// It is the day of the due date when left out.
+	MonthDay int `json:"by_month_day,omitempty"`
+
+	Until *time.Time `json:"until,omitempty"`
+	Count int        `json:"count,omitempty"`
+}
+
+// String is the RRULE of the recurrence, e.g. FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH;COUNT=10
+func (r Recurrence) String() string {
+	parts := []string{
+		"FREQ=" + strings.ToUpper(r.Frequency),
+		"INTERVAL=" + strconv.Itoa(r.Interval),
+	}
+
+	if len(r.Weekdays) != 0 {
+		parts = append(parts, "BYDAY="+strings.Join(r.Weekdays, ","))
+	}
+
+	if r.MonthDay != 0 {
+		parts = append(parts, "BYMONTHDAY="+strconv.Itoa(r.MonthDay))
+	}
+
+	if r.Until != nil {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format(rruleTime))
+	}
+
+	if r.Count != 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+
+	return strings.Join(parts, ";")
+}
+
+// rruleTime is the UTC form of an RRULE date time
+const rruleTime = "20060102T150405Z"
+
+// ParseRecurrence reads a recurrence back from its RRULE, as String writes it
+func ParseRecurrence(rrule string) (r Recurrence, err error) {
+	for _, part := range strings.Split(rrule, ";") {
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			err = fmt.Errorf("recurrence %q: %q is not KEY=VALUE", rrule, part)
+			return
+		}
+
+		switch key {
+		case "FREQ":
+			r.Frequency = strings.ToLower(value)
+		case "INTERVAL":
+			r.Interval, err = strconv.Atoi(value)
+		case "BYDAY":
+			r.Weekdays = strings.Split(value, ",")
+		case "BYMONTHDAY":
+			r.MonthDay, err = strconv.Atoi(value)
+		case "UNTIL":
+			var until time.Time
+			until, err = time.Parse(rruleTime, value)
+			r.Until = &until
+		case "COUNT":
+			r.Count, err = strconv.Atoi(value)
+		default:
+			err = fmt.Errorf("recurrence %q: unknown %s", rrule, key)
+		}
+
+		if err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// Next is the first date of the recurrence after the occurrence at from, keeping its time of day.
+// ok is false once the recurrence ended, occurrence being the number of from in the series.
+func (r Recurrence) Next(from time.Time, occurrence int64) (next time.Time, ok bool) {
+	if r.Count != 0 && occurrence >= int64(r.Count) {
+		return
+	}
+
+	interval := r.Interval
+	if interval < 1 {
+		interval = 1
+	}
+
+	switch r.Frequency {
+	case FrequencyDaily:
+		next = from.AddDate(0, 0, interval)
+	case FrequencyWeekly:
+		next = r.nextWeekday(from, interval)
+	case FrequencyMonthly:
+		next = r.nextMonthDay(from, interval)
+	default:
+		return
+	}
+
+	if r.Until != nil && next.After(*r.Until) {
+		return
+	}
+
+	return next, true
+}
+
+// nextWeekday is the next of the weekdays within every interval-th week from the week of from
+func (r Recurrence) nextWeekday(from time.Time, interval int) time.Time {
+	if len(r.Weekdays) == 0 {
+		return from.AddDate(0, 0, 7*interval)
+	}
+
+	days := map[int]bool{}
+	for _, weekday := range r.Weekdays {
+		for i, w := range WeekdayAllList {
+			if w == weekday {
+				days[i] = true
+			}
+		}
+	}
+
+	// Days from Monday, and the Monday of the week of from
+	offset := func(t time.Time) int { return (int(t.Weekday()) + 6) % 7 }
+	monday := from.AddDate(0, 0, -offset(from))
+
+	// The rest of the week of from, then the first matching day of the next week of the schedule
+	for i := 1; i <= 7*(interval+1); i++ {
+		day := from.AddDate(0, 0, i)
+		weeks := int(day.Sub(monday).Hours()/24) / 7
+		if weeks%interval == 0 && days[offset(day)] {
+			return day
+		}
+	}
+
+	// None of the weekdays is valid
+	return from.AddDate(0, 0, 7*interval)
+}
+
+// nextMonthDay is the month day interval months after from, the last day of the month when it has fewer days
+func (r Recurrence) nextMonthDay(from time.Time, interval int) time.Time {
+	day := r.MonthDay
+	if day == 0 {
+		day = from.Day()
+	}
+
+	first := time.Date(from.Year(), from.Month(), 1, from.Hour(), from.Minute(), from.Second(), 0, from.Location()).AddDate(0, interval, 0)
+	if last := first.AddDate(0, 1, -1).Day(); day > last {
+		day = last
+	}
+
+	return first.AddDate(0, 0, day-1)
+}
+
+// NullRecurrence is the recurrence of a change, Set tells a null ending it from the field being left out
+type NullRecurrence struct {
+	Recurrence *Recurrence
+	Set        bool
+}
+
+func (n *NullRecurrence) UnmarshalJSON(b []byte) error {
+	n.Set = true
+
+	return json.Unmarshal(b, &n.Recurrence)
+}
@@ -0,0 +1,99 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRecurrenceNext(t *testing.T) {
+	// 2030-01-07 is a Monday
+	day := func(month time.Month, d int) time.Time {
+		return time.Date(2030, month, d, 9, 30, 0, 0, time.UTC)
+	}
+	until := day(time.January, 10)
+
+	tests := []struct {
+		name       string
+		recurrence Recurrence
+		from       time.Time
+		occurrence int64
+		want       time.Time
+		ok         bool
+	}{
+		{name: "daily", recurrence: Recurrence{Frequency: FrequencyDaily}, from: day(time.January, 7), want: day(time.January, 8), ok: true},
+		{name: "every 3 days", recurrence: Recurrence{Frequency: FrequencyDaily, Interval: 3}, from: day(time.January, 30), want: day(time.February, 2), ok: true},
+		{name: "every 2 weeks", recurrence: Recurrence{Frequency: FrequencyWeekly, Interval: 2}, from: day(time.January, 9), want: day(time.January, 23), ok: true},
+		{name: "weekdays, later in the week", recurrence: Recurrence{Frequency: FrequencyWeekly, Weekdays: []string{"MO", "TH"}}, from: day(time.January, 7), want: day(time.January, 10), ok: true},
+		{name: "weekdays, next week", recurrence: Recurrence{Frequency: FrequencyWeekly, Weekdays: []string{"MO", "TH"}}, from: day(time.January, 10), want: day(time.January, 14), ok: true},
+		{name: "weekdays every 2 weeks, same week", recurrence: Recurrence{Frequency: FrequencyWeekly, Interval: 2, Weekdays: []string{"WE"}}, from: day(time.January, 7), want: day(time.January, 9), ok: true},
+		{name: "weekdays every 2 weeks, skips a week", recurrence: Recurrence{Frequency: FrequencyWeekly, Interval: 2, Weekdays: []string{"MO", "TH"}}, from: day(time.January, 10), want: day(time.January, 21), ok: true},
+		{name: "monthly", recurrence: Recurrence{Frequency: FrequencyMonthly, MonthDay: 15}, from: day(time.January, 15), want: day(time.February, 15), ok: true},
+		{name: "monthly, the day of from", recurrence: Recurrence{Frequency: FrequencyMonthly}, from: day(time.March, 12), want: day(time.April, 12), ok: true},
+		{name: "monthly, shorter month", recurrence: Recurrence{Frequency: FrequencyMonthly, MonthDay: 31}, from: day(time.January, 31), want: day(time.February, 28), ok: true},
+		{name: "monthly, back to the day", recurrence: Recurrence{Frequency: FrequencyMonthly, MonthDay: 31}, from: day(time.February, 28), want: day(time.March, 31), ok: true},
+		{name: "monthly, leap year", recurrence: Recurrence{Frequency: FrequencyMonthly, MonthDay: 30}, from: time.Date(2032, time.January, 30, 9, 30, 0, 0, time.UTC), want: time.Date(2032, time.February, 29, 9, 30, 0, 0, time.UTC), ok: true},
+		{name: "every 3 months, over the year", recurrence: Recurrence{Frequency: FrequencyMonthly, Interval: 3, MonthDay: 15}, from: day(time.November, 15), want: time.Date(2031, time.February, 15, 9, 30, 0, 0, time.UTC), ok: true},
+		{name: "before the count", recurrence: Recurrence{Frequency: FrequencyDaily, Count: 3}, from: day(time.January, 7), occurrence: 2, want: day(time.January, 8), ok: true},
+		{name: "count reached", recurrence: Recurrence{Frequency: FrequencyDaily, Count: 3}, from: day(time.January, 7), occurrence: 3},
+		{name: "on until", recurrence: Recurrence{Frequency: FrequencyDaily, Until: &until}, from: day(time.January, 9), want: until, ok: true},
+		{name: "after until", recurrence: Recurrence{Frequency: FrequencyDaily, Until: &until}, from: until},
+		{name: "unknown frequency", recurrence: Recurrence{Frequency: "yearly"}, from: day(time.January, 7)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next, ok := tt.recurrence.Next(tt.from, tt.occurrence)
+			if ok != tt.ok {
+				t.Fatalf("ok = %t, want %t", ok, tt.ok)
+			}
+
+			if ok && !next.Equal(tt.want) {
+				t.Errorf("next = %s, want %s", next, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecurrenceRRULE(t *testing.T) {
+	until := time.Date(2030, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		recurrence Recurrence
+		rrule      string
+	}{
+		{recurrence: Recurrence{Frequency: FrequencyDaily, Interval: 1}, rrule: "FREQ=DAILY;INTERVAL=1"},
+		{recurrence: Recurrence{Frequency: FrequencyWeekly, Interval: 2, Weekdays: []string{"MO", "TH"}, Count: 10}, rrule: "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH;COUNT=10"},
+		{recurrence: Recurrence{Frequency: FrequencyMonthly, Interval: 1, MonthDay: 31, Until: &until}, rrule: "FREQ=MONTHLY;INTERVAL=1;BYMONTHDAY=31;UNTIL=20300301T000000Z"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rrule, func(t *testing.T) {
+			if got := tt.recurrence.String(); got != tt.rrule {
+				t.Fatalf("rrule = %s, want %s", got, tt.rrule)
+			}
+
+			parsed, err := ParseRecurrence(tt.rrule)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(parsed, tt.recurrence) {
+				t.Errorf("parsed = %+v, want %+v", parsed, tt.recurrence)
+			}
+		})
+	}
+}
+
+func TestParseRecurrenceInvalid(t *testing.T) {
+	for _, rrule := range []string{
+		"FREQ",
+		"FREQ=DAILY;INTERVAL=two",
+		"FREQ=DAILY;UNTIL=2030-03-01",
+		"FREQ=DAILY;BYHOUR=9",
+	} {
+		if _, err := ParseRecurrence(rrule); err == nil {
+			t.Errorf("%s: want an error", rrule)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrTagExists is returned by a write giving a tag the name another one has
+	ErrTagExists = errors.New("tag name exists")
+)
+
+const (
+	TagModel = "Tag"
+
+	// TagNameMax is the longest tag name, in characters
+	TagNameMax = 64
+)
+
+// Tag labels todos across activity groups, a todo lists the names of its tags.
+// Renaming or deleting a tag makes a new version of its todos.
+type Tag struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// TagName is the stored form of a tag name, trimmed and lower case
+func TagName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// TagNames are names in their stored form, blanks and repeats left out
+func TagNames(names []string) []string {
+	if names == nil {
+		return nil
+	}
+
+	seen := map[string]bool{}
+	res := []string{}
+	for _, name := range names {
+		if name = TagName(name); name != "" && !seen[name] {
+			seen[name] = true
+			res = append(res, name)
+		}
+	}
+
+	return res
+}
+
+// Create
+
+type TagCreateRequest struct {
+	Name string `json:"name"`
+}
+
+type TagCreateResponse struct {
+	Tag
+}
+
+// Update
+
+type TagUpdateRequest struct {
+	ID        int64     `json:"-"`
+	Name      string    `json:"name"`
+	UpdatedAt time.Time `json:"-"`
+}
+
+type TagUpdateResponse struct {
+	Tag
+}
+
+// Delete, detaching the tag from its todos
+
+type TagDeleteRequest struct {
+	ID        int64
+	UpdatedAt time.Time
+}
+
+type TagDeleteResponse struct {
+}
+
+// Attach and Detach, the tags of a todo
+
+type TagAttachRequest struct {
+	TodoID int64
+	TagIDs []int64
+}
+
+type TagAttachResponse struct {
+}
+
+type TagDetachRequest struct {
+	TodoID int64
+	TagIDs []int64
+}
+
+type TagDetachResponse struct {
+}
+
+// Get All, by name
+
+type TagGetAllRequest struct {
+	// Names narrows the list to the given tags
+	Names []string
+}
+
+type TagGetAllResponse struct {
+	Tags []Tag
+}
+
+// Get One
+
+type TagGetOneRequest struct {
+	ID int64
+}
+
+type TagGetOneResponse struct {
+	Tag
+}
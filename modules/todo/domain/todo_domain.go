@@ -1,6 +1,13 @@
 package domain
 
-import "time"
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/fahmiaz411/devcode/helper/pagination"
+)
 
 // Priority
 const (
@@ -28,8 +35,118 @@ const (
 	IsActiveDefault = true
 )
 
+// Occurrence, the first todo of a series or a todo that does not recur
+const (
+	OccurrenceDefault = 1
+)
+
+// Version, bumped on every change of a todo
+const (
+	VersionDefault = 1
+)
+
+var (
+	// ErrVersionConflict is returned by a write when the todo is no longer at the expected version
+	ErrVersionConflict = errors.New("todo version conflict")
+)
+
+// Position, the manual order of the todos of an activity group.
+// Ranks leave gaps so a move rewrites a single todo, the group is renumbered once a gap runs out.
+const (
+	PositionGap = 1024
+)
+
+// Sort
+const (
+	SortPosition = "position"
+	SortID = "id"
+	SortTitle = "title"
+	SortPriority = "priority"
+	SortCreatedAt = "createdAt"
+	SortUpdatedAt = "updatedAt"
+	SortDueAt = "dueAt"
+)
+
+var (
+	// SortAllList starts with the default sort
+	SortAllList = []string{
+		SortPosition,
+		SortID,
+		SortTitle,
+		SortPriority,
+		SortCreatedAt,
+		SortUpdatedAt,
+		SortDueAt,
+	}
+)
+
+// Due, the deadline views of a list
+const (
+	DueOverdue = "overdue"
+	DueToday = "today"
+	DueWeek = "week"
+)
+
+var (
+	DueAllList = []string{
+		DueOverdue,
+		DueToday,
+		DueWeek,
+	}
+
+	// DueNever stands for a todo without due date when sorting by it, after every dated one
+	DueNever = time.Date(9999, time.December, 31, 0, 0, 0, 0, time.UTC)
+)
+
+// PriorityRank orders priorities from very-low (0) to very-high, so descending lists the most urgent first
+func PriorityRank(priority string) int {
+	for i, p := range PriorityAllList {
+		if p == priority {
+			return len(PriorityAllList) - 1 - i
+		}
+	}
+
+	return 0
+}
+
+// SortValue is the cursor value of the todo for sort
+func (t Todo) SortValue(sort string) string {
+	switch sort {
+	case SortPosition:
+		return strconv.FormatInt(t.Position, 10)
+	case SortTitle:
+		return t.Title
+	case SortPriority:
+		return strconv.Itoa(PriorityRank(t.Priority))
+	case SortCreatedAt:
+		return t.CreatedAt.Format(time.RFC3339Nano)
+	case SortUpdatedAt:
+		return t.UpdatedAt.Format(time.RFC3339Nano)
+	case SortDueAt:
+		if t.DueAt == nil {
+			return DueNever.Format(time.RFC3339Nano)
+		}
+		return t.DueAt.Format(time.RFC3339Nano)
+	default:
+		return strconv.FormatInt(t.ID, 10)
+	}
+}
+
+// ParseSortValue converts a cursor value back to the type of the sort field
+func ParseSortValue(sort string, value string) (any, error) {
+	switch sort {
+	case SortTitle:
+		return value, nil
+	case SortCreatedAt, SortUpdatedAt, SortDueAt:
+		return time.Parse(time.RFC3339Nano, value)
+	default:
+		return strconv.ParseInt(value, 10, 64)
+	}
+}
+
 const (
 	Model = "Todo"
+	ActivityModel = "Activity"
 )
 
 type Todo struct {
@@ -38,8 +155,120 @@ type Todo struct {
 	Title     		string    `json:"title"`
 	IsActive		bool	  `json:"is_active"`
 	Priority		string	  `json:"priority"`
+	Position		int64	  `json:"position"`
 	CreatedAt 		time.Time `json:"createdAt"`
 	UpdatedAt 		time.Time `json:"updatedAt"`
+	DeletedAt 		*time.Time `json:"deletedAt"`
+	CompletedAt		*time.Time `json:"completedAt"`
+	DueAt			*time.Time `json:"due_at"`
+	RemindAt		*time.Time `json:"remind_at"`
+	RemindedAt		*time.Time `json:"reminded_at"`
+
+	// RemindAttempts counts the claims of the reminder, it is given up after RemindAttemptsMax until RemindAt changes
+	RemindAttempts	int64	`json:"remind_attempts"`
+
+	// Recurrence makes the todo come back once completed, Occurrence counts the todos of the series so far.
+	// NextID is the occurrence its completion created.
+	Recurrence		*Recurrence `json:"recurrence"`
+	Occurrence		int64	`json:"occurrence"`
+	NextID			*int64	`json:"next_id"`
+
+	// Checklist, counted on read, Progress is null without items
+	ChecklistTotal	int64	`json:"checklist_total"`
+	ChecklistDone	int64	`json:"checklist_done"`
+	Progress		*int	`json:"progress"`
+
+	// Tags are the names of the tags of the todo, read along with it
+	Tags			[]string `json:"tags"`
+
+	Version			int64	`json:"version"`
+}
+
+// CompletedAt is when a todo written at at is completed, nil while it is active
+func CompletedAt(isActive bool, at time.Time) *time.Time {
+	if isActive {
+		return nil
+	}
+
+	return &at
+}
+
+// SetActive writes is_active at at, a completion is dated once and reopening the todo clears it
+func (t *Todo) SetActive(isActive bool, at time.Time) {
+	t.IsActive = isActive
+
+	if isActive {
+		t.CompletedAt = nil
+	} else if t.CompletedAt == nil {
+		t.CompletedAt = &at
+	}
+}
+
+// With is the todo once a change is written, but for its stamps and version
+func (t Todo) With(req TodoUpdateRequest) Todo {
+	if req.ActivityGroupID != 0 {
+		t.ActivityGroupID = req.ActivityGroupID
+	}
+
+	if req.Title != "" {
+		t.Title = req.Title
+	}
+
+	if req.Priority != "" {
+		t.Priority = req.Priority
+	}
+
+	if req.IsActive != nil {
+		t.IsActive = *req.IsActive
+	}
+
+	if req.DueAt.Set {
+		t.DueAt = req.DueAt.Time
+	}
+
+	if req.RemindAt.Set {
+		t.RemindAt = req.RemindAt.Time
+	}
+
+	if req.Recurrence.Set {
+		t.Recurrence = req.Recurrence.Recurrence
+	}
+
+	return t
+}
+
+// Columns tells whether a change writes the todo itself, rather than only its tags
+func (r TodoUpdateRequest) Columns() bool {
+	return r.ActivityGroupID != 0 ||
+		r.Title != "" ||
+		r.IsActive != nil ||
+		r.Priority != "" ||
+		r.DueAt.Set ||
+		r.RemindAt.Set ||
+		r.Recurrence.Set
+}
+
+// Deadline is a due or remind time as stored, in UTC to the second
+func Deadline(at *time.Time) *time.Time {
+	if at == nil {
+		return nil
+	}
+
+	deadline := at.UTC().Truncate(time.Second)
+
+	return &deadline
+}
+
+// NullTime is a time field of a change, Set tells a null clearing it from the field being left out
+type NullTime struct {
+	Time *time.Time
+	Set  bool
+}
+
+func (n *NullTime) UnmarshalJSON(b []byte) error {
+	n.Set = true
+
+	return json.Unmarshal(b, &n.Time)
 }
 
 // Create
@@ -48,6 +277,19 @@ type TodoCreateRequest struct {
 	Title 	 		string `json:"title"`
 	ActivityGroupID int64  `json:"activity_group_id"`
 	IsActive		*bool	  `json:"is_active"`
+	DueAt			*time.Time `json:"due_at"`
+	RemindAt		*time.Time `json:"remind_at"`
+	Recurrence		*Recurrence `json:"recurrence"`
+
+	// Position is the end of the activity group, given by the usecase
+	Position		int64	  `json:"-"`
+
+	// Priority and Occurrence carry on a series, given by the usecase to the next occurrence
+	Priority		string	  `json:"-"`
+	Occurrence		int64	  `json:"-"`
+
+	// CheckActivity is set when the path names the activity group, a missing one is NotFound rather than invalid input
+	CheckActivity	bool	  `json:"-"`
 }
 
 type TodoCreateResponse struct {
@@ -58,37 +300,446 @@ type TodoCreateResponse struct {
 
 type TodoUpdateRequest struct {
 	ID 				int64 	`json:"-"`
+	ActivityGroupID int64	`json:"activity_group_id"`
 	Title 			string 	`json:"title"`
 	IsActive		*bool	`json:"is_active"`
 	Priority		string	`json:"priority"`
+	DueAt			NullTime `json:"due_at"`
+	RemindAt		NullTime `json:"remind_at"`
+	Recurrence		NullRecurrence `json:"recurrence"`
 	UpdatedAt time.Time 	`json:"-"`
+
+	// AttachTags and DetachTags name existing tags, the usecase writes them apart from the todo row
+	AttachTags		[]string `json:"attach_tags"`
+	DetachTags		[]string `json:"detach_tags"`
+
+	// Position is given by the usecase, on a move or a change of activity group
+	Position		*int64	`json:"-"`
+
+	// NextID is given by the usecase, when completing a recurring todo created its next occurrence
+	NextID			*int64	`json:"-"`
+
+	// Version expected by the write, from If-Match or as read by the usecase
+	Version			int64	`json:"-"`
 }
 
 type TodoUpdateResponse struct {
 	Todo
 }
 
+// Move, a todo put right before or right after another of its activity group
+
+type TodoMoveRequest struct {
+	ID		int64	`json:"-"`
+	Before	*int64	`json:"before"`
+	After	*int64	`json:"after"`
+
+	// Version expected by the write, from If-Match or as read by the usecase
+	Version	int64	`json:"-"`
+}
+
+type TodoMoveResponse struct {
+	Todo
+}
+
+// Neighbour, the todo next to another in the order of its activity group
+
+type TodoNeighbourRequest struct {
+	ActivityGroupID int64
+	Position int64
+	ID int64
+
+	// After looks past the todo rather than before it
+	After bool
+
+	// ExcludeID is left out, as the todo being moved
+	ExcludeID int64
+}
+
+// TodoNeighbourResponse has a zero ID when the todo is the first, or the last, of its group
+type TodoNeighbourResponse struct {
+	ID int64
+	Position int64
+}
+
+// Last Position, the end of an activity group
+
+type TodoLastPositionRequest struct {
+	ActivityGroupID int64
+}
+
+type TodoLastPositionResponse struct {
+	Position int64
+}
+
+// Renumber, new positions for todos keeping their order, a new version of each as the position is part of it
+
+type TodoRenumberItem struct {
+	ID int64
+	Position int64
+}
+
+type TodoRenumberRequest struct {
+	Todos []TodoRenumberItem
+	UpdatedAt time.Time
+}
+
+type TodoRenumberResponse struct {
+}
+
 // Delete
 
 type TodoDeleteRequest struct {
 	ID int64
+	Version int64
 }
 
 type TodoDeleteResponse struct {
 }
 
+// Restore
+
+type TodoRestoreRequest struct {
+	ID int64
+	UpdatedAt time.Time
+
+	// Version expected by the write, as read from the trash by the usecase
+	Version int64
+}
+
+type TodoRestoreResponse struct {
+	Todo
+}
+
+// Purge
+
+type TodoPurgeRequest struct {
+	ID int64
+}
+
+type TodoPurgeResponse struct {
+}
+
+// Bulk, a batch of todos written in one unit of work, all of them or none
+
+const (
+	BulkLimit = 100
+)
+
+type TodoBulkCreateRequest struct {
+	Todos []TodoCreateRequest
+}
+
+type TodoBulkCreateResponse struct {
+	Todos []Todo
+}
+
+// TodoBulkUpdateItem names its todo and the expected version, the URL and If-Match do it for a single todo
+type TodoBulkUpdateItem struct {
+	ID				int64	`json:"id"`
+	Version			int64	`json:"version"`
+	TodoUpdateRequest
+}
+
+type TodoBulkUpdateRequest struct {
+	Todos []TodoBulkUpdateItem
+	UpdatedAt time.Time
+}
+
+type TodoBulkUpdateResponse struct {
+}
+
+type TodoBulkDeleteItem struct {
+	ID				int64	`json:"id"`
+	Version			int64	`json:"version"`
+}
+
+type TodoBulkDeleteRequest struct {
+	Todos []TodoBulkDeleteItem
+}
+
+type TodoBulkDeleteResponse struct {
+}
+
+// TodoBulkResult is the outcome of the todo at the same index of the batch
+type TodoBulkResult struct {
+	Todo *Todo
+	Err error
+}
+
+type TodoBulkResponse struct {
+	Results []TodoBulkResult
+}
+
+// Rejected counts the todos of the batch that failed, a single one leaves the whole batch unwritten
+func (r TodoBulkResponse) Rejected() (count int) {
+	for _, result := range r.Results {
+		if result.Err != nil {
+			count++
+		}
+	}
+
+	return
+}
+
+// By Activity, what happens to the todos of an activity group with it
+
+type TodoCountByActivityRequest struct {
+	ActivityGroupID int64
+	WithDeleted bool
+}
+
+type TodoCountByActivityResponse struct {
+	Count int64
+}
+
+type TodoDeleteByActivityRequest struct {
+	ActivityGroupID int64
+	DeletedAt time.Time
+}
+
+type TodoDeleteByActivityResponse struct {
+}
+
+// TodoRestoreByActivityRequest restores the todos deleted at DeletedAt, along with their activity group
+type TodoRestoreByActivityRequest struct {
+	ActivityGroupID int64
+	DeletedAt time.Time
+	UpdatedAt time.Time
+}
+
+type TodoRestoreByActivityResponse struct {
+}
+
+type TodoPurgeByActivityRequest struct {
+	ActivityGroupID int64
+}
+
+type TodoPurgeByActivityResponse struct {
+}
+
+// Group Count, the live todos of activity groups counted by priority and state in one grouped query
+
+type TodoGroupCountRequest struct {
+	ActivityGroupIDs []int64
+}
+
+type TodoGroupCount struct {
+	ActivityGroupID int64
+	Priority string
+	IsActive bool
+	Count int64
+}
+
+type TodoGroupCountResponse struct {
+	Groups []TodoGroupCount
+}
+
+// TodoCounts are the aggregate counts of the todos of an activity group
+type TodoCounts struct {
+	Total		int64	`json:"total"`
+	Active		int64	`json:"active"`
+	ByPriority	map[string]int64 `json:"by_priority"`
+}
+
+// NewTodoCounts starts every priority at zero
+func NewTodoCounts() *TodoCounts {
+	counts := &TodoCounts{
+		ByPriority: map[string]int64{},
+	}
+
+	for _, priority := range PriorityAllList {
+		counts.ByPriority[priority] = 0
+	}
+
+	return counts
+}
+
+// Add counts a group of todos in
+func (c *TodoCounts) Add(group TodoGroupCount) {
+	c.Total += group.Count
+	c.ByPriority[group.Priority] += group.Count
+
+	if group.IsActive {
+		c.Active += group.Count
+	}
+}
+
+// Stats, how the work on the live todos goes, with their activity over a range of days
+
+const (
+	// StatsDaysDefault is the range ending today when none is given
+	StatsDaysDefault = 30
+	StatsDaysMax = 366
+)
+
+type TodoStatsRequest struct {
+	// ActivityGroupID narrows the stats to an activity group, all of them when zero
+	ActivityGroupID	int64
+	CheckActivity	bool
+
+	// From the start of a day, To the end of one, UTC
+	From			*time.Time
+	To				*time.Time
+}
+
+type TodoStatsDay struct {
+	Date		string	`json:"date"`
+	Created		int64	`json:"created"`
+	Completed	int64	`json:"completed"`
+}
+
+type TodoStatsResponse struct {
+	TodoCounts
+	Completed	int64	`json:"completed"`
+
+	// From and To are the first and the last day of Days
+	From		string	`json:"from"`
+	To			string	`json:"to"`
+	Days		[]TodoStatsDay `json:"days"`
+
+	// AvgCompletionSeconds is over the todos completed in the range, null when there are none
+	AvgCompletionSeconds *float64 `json:"avg_completion_seconds"`
+}
+
+// Aggregate, the SQL aggregation the stats are computed from
+
+type TodoAggregateRequest struct {
+	ActivityGroupID int64
+	From time.Time
+	To time.Time
+}
+
+type TodoAggregateResponse struct {
+	Groups []TodoGroupCount
+
+	// Created and Completed are counted by day, YYYY-MM-DD
+	Created map[string]int64
+	Completed map[string]int64
+
+	AvgCompletionSeconds *float64
+}
+
+// Reminders, claimed by a dispatcher for a lease so that a single replica sends each of them
+
+// RemindAttemptsMax is how many times a reminder is claimed, one failing past it is left unsent
+const RemindAttemptsMax = 5
+
+// Reminder is a todo due for its reminder, Email is where its activity group wants it sent
+type Reminder struct {
+	Todo
+	Email string `json:"email,omitempty"`
+}
+
+// Claim Reminders
+
+type TodoClaimRemindersRequest struct {
+	// Claim names the dispatcher, Lease is how long the todos are its own
+	Claim string
+	Lease time.Duration
+	Limit int
+
+	// Now and Until, the lease end, are given by the usecase
+	Now time.Time
+	Until time.Time
+}
+
+type TodoClaimRemindersResponse struct {
+	Todos []Todo
+}
+
+type TodoRemindersResponse struct {
+	Reminders []Reminder
+}
+
+// Renew Reminder, a new lease on a claimed todo right before it is sent
+
+type TodoRenewReminderRequest struct {
+	ID int64
+	Claim string
+	Lease time.Duration
+
+	// Until, the new lease end, is given by the usecase
+	Until time.Time
+}
+
+type TodoRenewReminderResponse struct {
+	// Renewed is false when the reminder was sent, or the lease ran out and another dispatcher took the todo
+	Renewed bool
+}
+
+// Mark Reminded, written once the reminder is sent and only while the claim still holds
+
+type TodoMarkRemindedRequest struct {
+	ID int64
+	Claim string
+	RemindedAt time.Time
+}
+
+type TodoMarkRemindedResponse struct {
+	// Marked is false when the lease ran out and another dispatcher took the todo
+	Marked bool
+}
+
 // Get All
 
 type TodoGetAllRequest struct {
 	ActivityGroupID int64 	`json:"activity_group_id"`
+	IsActive		*bool	`json:"is_active"`
+	Priorities		[]string `json:"priority"`
+	Title			string	`json:"title"`
+
+	// Tags keeps the todos with any of the given tags
+	Tags			[]string `json:"tag"`
+
+	// From bounds are inclusive, To bounds are exclusive
+	CreatedFrom		*time.Time `json:"created_from"`
+	CreatedTo		*time.Time `json:"created_to"`
+	UpdatedFrom		*time.Time `json:"updated_from"`
+	UpdatedTo		*time.Time `json:"updated_to"`
+	DueFrom			*time.Time `json:"due_from"`
+	DueTo			*time.Time `json:"due_to"`
+
+	// Due is one of DueAllList, the usecase narrows the due bounds to it
+	Due				string	`json:"due"`
+
+	// Trashed lists the deleted todos instead of the live ones
+	Trashed			bool	`json:"-"`
+
+	// IDs narrows the list to the given todos, as a batch reads them
+	IDs				[]int64	`json:"-"`
+
+	// ActivityGroupIDs narrows the list to several activity groups, as includes read them
+	ActivityGroupIDs	[]int64	`json:"-"`
+
+	// CheckActivity makes a missing or deleted activity group NotFound rather than an empty list
+	CheckActivity	bool	`json:"-"`
+
+	pagination.Request
+}
+
+type TodoGetAllResponse struct {
+	Todos  []Todo
+	Paging pagination.Paging
+}
+
+// Get All Stamp, whether a Get All changed without running it
+
+type TodoGetAllStampResponse struct {
+	pagination.Stamp
 }
 
-type TodoGetAllResponse []Todo
+// Count, the todos a Get All would list across all pages
+
+type TodoCountResponse struct {
+	Count int64 `json:"count"`
+}
 
 // Get One
 
 type TodoGetOneRequest struct {
 	ID int64
+	Trashed bool
 }
 
 type TodoGetOneResponse struct {
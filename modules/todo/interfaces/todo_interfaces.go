@@ -4,22 +4,92 @@ import (
 	"github.com/fahmiaz411/devcode/modules/todo/domain"
 
 	"context"
-
-	"github.com/gofiber/fiber/v2"
 )
 
 type TodoUsecase interface {
-	Create(c *fiber.Ctx, req domain.TodoCreateRequest) (res domain.TodoCreateResponse, err error)
-	Update(c *fiber.Ctx, req domain.TodoUpdateRequest) (res domain.TodoUpdateResponse, err error)
-	Delete(c *fiber.Ctx, req domain.TodoDeleteRequest) (res domain.TodoDeleteResponse, err error)
-	GetAll(c *fiber.Ctx, req domain.TodoGetAllRequest) (res domain.TodoGetAllResponse, err error)
-	GetOne(c *fiber.Ctx, req domain.TodoGetOneRequest) (res domain.TodoGetOneResponse, err error)
+	Create(ctx context.Context, req domain.TodoCreateRequest) (res domain.TodoCreateResponse, err error)
+	Update(ctx context.Context, req domain.TodoUpdateRequest) (res domain.TodoUpdateResponse, err error)
+	Delete(ctx context.Context, req domain.TodoDeleteRequest) (res domain.TodoDeleteResponse, err error)
+	Restore(ctx context.Context, req domain.TodoRestoreRequest) (res domain.TodoRestoreResponse, err error)
+	Purge(ctx context.Context, req domain.TodoPurgeRequest) (res domain.TodoPurgeResponse, err error)
+	Move(ctx context.Context, req domain.TodoMoveRequest) (res domain.TodoMoveResponse, err error)
+	BulkCreate(ctx context.Context, req domain.TodoBulkCreateRequest) (res domain.TodoBulkResponse, err error)
+	BulkUpdate(ctx context.Context, req domain.TodoBulkUpdateRequest) (res domain.TodoBulkResponse, err error)
+	BulkDelete(ctx context.Context, req domain.TodoBulkDeleteRequest) (res domain.TodoBulkResponse, err error)
+	GetAll(ctx context.Context, req domain.TodoGetAllRequest) (res domain.TodoGetAllResponse, err error)
+	GetAllStamp(ctx context.Context, req domain.TodoGetAllRequest) (res domain.TodoGetAllStampResponse, err error)
+	Stats(ctx context.Context, req domain.TodoStatsRequest) (res domain.TodoStatsResponse, err error)
+	ClaimReminders(ctx context.Context, req domain.TodoClaimRemindersRequest) (res domain.TodoRemindersResponse, err error)
+	RenewReminder(ctx context.Context, req domain.TodoRenewReminderRequest) (res domain.TodoRenewReminderResponse, err error)
+	MarkReminded(ctx context.Context, req domain.TodoMarkRemindedRequest) (res domain.TodoMarkRemindedResponse, err error)
+	GetOne(ctx context.Context, req domain.TodoGetOneRequest) (res domain.TodoGetOneResponse, err error)
+	GetChecklist(ctx context.Context, req domain.ChecklistGetAllRequest) (res domain.ChecklistGetAllResponse, err error)
+	CreateChecklistItem(ctx context.Context, req domain.ChecklistCreateRequest) (res domain.ChecklistCreateResponse, err error)
+	UpdateChecklistItem(ctx context.Context, req domain.ChecklistUpdateRequest) (res domain.ChecklistUpdateResponse, err error)
+	MoveChecklistItem(ctx context.Context, req domain.ChecklistMoveRequest) (res domain.ChecklistMoveResponse, err error)
+	DeleteChecklistItem(ctx context.Context, req domain.ChecklistDeleteRequest) (res domain.ChecklistDeleteResponse, err error)
+	CreateTag(ctx context.Context, req domain.TagCreateRequest) (res domain.TagCreateResponse, err error)
+	UpdateTag(ctx context.Context, req domain.TagUpdateRequest) (res domain.TagUpdateResponse, err error)
+	DeleteTag(ctx context.Context, req domain.TagDeleteRequest) (res domain.TagDeleteResponse, err error)
+	GetAllTags(ctx context.Context, req domain.TagGetAllRequest) (res domain.TagGetAllResponse, err error)
+	GetOneTag(ctx context.Context, req domain.TagGetOneRequest) (res domain.TagGetOneResponse, err error)
 }
 
-type TodoRepoMysql interface {
+type TodoRepository interface {
 	Create(ctx context.Context, req domain.TodoCreateRequest) (res domain.TodoCreateResponse, err error)
 	Update(ctx context.Context, req domain.TodoUpdateRequest) (res domain.TodoUpdateResponse, err error)
 	Delete(ctx context.Context, req domain.TodoDeleteRequest) (res domain.TodoDeleteResponse, err error)
+	Restore(ctx context.Context, req domain.TodoRestoreRequest) (res domain.TodoRestoreResponse, err error)
+	Purge(ctx context.Context, req domain.TodoPurgeRequest) (res domain.TodoPurgeResponse, err error)
+	BulkCreate(ctx context.Context, req domain.TodoBulkCreateRequest) (res domain.TodoBulkCreateResponse, err error)
+	BulkUpdate(ctx context.Context, req domain.TodoBulkUpdateRequest) (res domain.TodoBulkUpdateResponse, err error)
+	BulkDelete(ctx context.Context, req domain.TodoBulkDeleteRequest) (res domain.TodoBulkDeleteResponse, err error)
 	GetAll(ctx context.Context, req domain.TodoGetAllRequest) (res domain.TodoGetAllResponse, err error)
+	GetAllStamp(ctx context.Context, req domain.TodoGetAllRequest) (res domain.TodoGetAllStampResponse, err error)
 	GetOne(ctx context.Context, req domain.TodoGetOneRequest) (res domain.TodoGetOneResponse, err error)
+	GroupCount(ctx context.Context, req domain.TodoGroupCountRequest) (res domain.TodoGroupCountResponse, err error)
+	Aggregate(ctx context.Context, req domain.TodoAggregateRequest) (res domain.TodoAggregateResponse, err error)
+	LastPosition(ctx context.Context, req domain.TodoLastPositionRequest) (res domain.TodoLastPositionResponse, err error)
+	Neighbour(ctx context.Context, req domain.TodoNeighbourRequest) (res domain.TodoNeighbourResponse, err error)
+	Renumber(ctx context.Context, req domain.TodoRenumberRequest) (res domain.TodoRenumberResponse, err error)
+	ClaimReminders(ctx context.Context, req domain.TodoClaimRemindersRequest) (res domain.TodoClaimRemindersResponse, err error)
+	RenewReminder(ctx context.Context, req domain.TodoRenewReminderRequest) (res domain.TodoRenewReminderResponse, err error)
+	MarkReminded(ctx context.Context, req domain.TodoMarkRemindedRequest) (res domain.TodoMarkRemindedResponse, err error)
+	CountByActivity(ctx context.Context, req domain.TodoCountByActivityRequest) (res domain.TodoCountByActivityResponse, err error)
+	DeleteByActivity(ctx context.Context, req domain.TodoDeleteByActivityRequest) (res domain.TodoDeleteByActivityResponse, err error)
+	RestoreByActivity(ctx context.Context, req domain.TodoRestoreByActivityRequest) (res domain.TodoRestoreByActivityResponse, err error)
+	PurgeByActivity(ctx context.Context, req domain.TodoPurgeByActivityRequest) (res domain.TodoPurgeByActivityResponse, err error)
+	Touch(ctx context.Context, req domain.TodoTouchRequest) (res domain.TodoTouchResponse, err error)
+}
+
+// ChecklistRepository stores the checklist items of the todos, purging a todo purges its items
+type ChecklistRepository interface {
+	Create(ctx context.Context, req domain.ChecklistCreateRequest) (res domain.ChecklistCreateResponse, err error)
+	Update(ctx context.Context, req domain.ChecklistUpdateRequest) (res domain.ChecklistUpdateResponse, err error)
+	Delete(ctx context.Context, req domain.ChecklistDeleteRequest) (res domain.ChecklistDeleteResponse, err error)
+	Check(ctx context.Context, req domain.ChecklistCheckRequest) (res domain.ChecklistCheckResponse, err error)
+	GetAll(ctx context.Context, req domain.ChecklistGetAllRequest) (res domain.ChecklistGetAllResponse, err error)
+}
+
+// TagRepository stores the tags and which todos carry them, renaming or deleting a tag
+// makes a new version of its todos and purging a todo detaches its tags
+type TagRepository interface {
+	Create(ctx context.Context, req domain.TagCreateRequest) (res domain.TagCreateResponse, err error)
+	Update(ctx context.Context, req domain.TagUpdateRequest) (res domain.TagUpdateResponse, err error)
+	Delete(ctx context.Context, req domain.TagDeleteRequest) (res domain.TagDeleteResponse, err error)
+	GetAll(ctx context.Context, req domain.TagGetAllRequest) (res domain.TagGetAllResponse, err error)
+	GetOne(ctx context.Context, req domain.TagGetOneRequest) (res domain.TagGetOneResponse, err error)
+	Attach(ctx context.Context, req domain.TagAttachRequest) (res domain.TagAttachResponse, err error)
+	Detach(ctx context.Context, req domain.TagDetachRequest) (res domain.TagDetachResponse, err error)
+}
+
+// ActivityChecker is what the todo module needs from the activity module
+type ActivityChecker interface {
+	Exists(ctx context.Context, id int64) (exists bool, err error)
+	Email(ctx context.Context, id int64) (email string, err error)
+}
+
+// Notifier sends the reminder of a todo, an error leaves it to be sent again once its claim expires
+type Notifier interface {
+	Notify(ctx context.Context, reminder domain.Reminder) (err error)
 }
\ No newline at end of file
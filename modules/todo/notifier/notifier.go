@@ -0,0 +1,100 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/fahmiaz411/devcode/modules/todo/domain"
+	"github.com/fahmiaz411/devcode/modules/todo/interfaces"
+)
+
+// deliveredTTL is how long a partial delivery is remembered, a reminder is retried well within it
+const deliveredTTL = 24 * time.Hour
+
+// Notifiers sends a reminder through every notifier, it fails when any of them does.
+// It remembers the notifiers a reminder went through, so a retry only goes through those that failed.
+// The record is kept in memory: a retry by another replica, or after a restart, may go through a notifier again.
+type Notifiers struct {
+	List []interfaces.Notifier
+
+	mu        sync.Mutex
+	delivered map[delivery]time.Time
+}
+
+// delivery is a reminder of a todo, at its reminder time, that went through the notifier at Index of List
+type delivery struct {
+	TodoID   int64
+	RemindAt int64
+	Index    int
+}
+
+func NewNotifiers(list ...interfaces.Notifier) *Notifiers {
+	return &Notifiers{
+		List:      list,
+		delivered: map[delivery]time.Time{},
+	}
+}
+
+func (n *Notifiers) Notify(ctx context.Context, reminder domain.Reminder) (err error) {
+	var remindAt int64
+	if reminder.RemindAt != nil {
+		remindAt = reminder.RemindAt.UnixNano()
+	}
+
+	errs := []error{}
+	for i, notifier := range n.List {
+		key := delivery{TodoID: reminder.ID, RemindAt: remindAt, Index: i}
+		if n.sent(key) {
+			continue
+		}
+
+		if err := notifier.Notify(ctx, reminder); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		n.record(key)
+	}
+
+	// Delivered through all of them, there is nothing left to retry
+	if len(errs) == 0 {
+		n.forget(reminder.ID, remindAt)
+	}
+
+	return errors.Join(errs...)
+}
+
+func (n *Notifiers) sent(key delivery) bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	_, ok := n.delivered[key]
+
+	return ok
+}
+
+// record remembers a delivery and lets go of those older than deliveredTTL
+func (n *Notifiers) record(key delivery) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	now := time.Now()
+	for other, at := range n.delivered {
+		if now.Sub(at) > deliveredTTL {
+			delete(n.delivered, other)
+		}
+	}
+
+	n.delivered[key] = now
+}
+
+func (n *Notifiers) forget(todoID, remindAt int64) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	for i := range n.List {
+		delete(n.delivered, delivery{TodoID: todoID, RemindAt: remindAt, Index: i})
+	}
+}
@@ -0,0 +1,65 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/fahmiaz411/devcode/modules/todo/domain"
+)
+
+// fake counts its deliveries and fails while fail is set
+type fake struct {
+	sent int
+	fail bool
+}
+
+func (f *fake) Notify(ctx context.Context, reminder domain.Reminder) error {
+	if f.fail {
+		return errors.New("down")
+	}
+
+	f.sent++
+
+	return nil
+}
+
+func TestNotifiers(t *testing.T) {
+	ctx := context.Background()
+	mail, hook := &fake{}, &fake{fail: true}
+	notifiers := NewNotifiers(mail, hook)
+
+	r := reminder()
+	at := time.Date(2030, 1, 2, 9, 0, 0, 0, time.UTC)
+	r.RemindAt = &at
+
+	if err := notifiers.Notify(ctx, r); err == nil {
+		t.Fatal("want the error of the failing notifier")
+	}
+
+	// The retry only goes through the notifier that failed
+	hook.fail = false
+	if err := notifiers.Notify(ctx, r); err != nil {
+		t.Fatal(err)
+	}
+
+	if mail.sent != 1 || hook.sent != 1 {
+		t.Errorf("sent %d mails and %d webhooks, want 1 and 1", mail.sent, hook.sent)
+	}
+
+	// Delivered through all of them, the record is gone
+	if len(notifiers.delivered) != 0 {
+		t.Errorf("%d deliveries left on record, want none", len(notifiers.delivered))
+	}
+
+	// The next reminder of the todo, at another time, goes through every notifier
+	later := at.Add(24 * time.Hour)
+	r.RemindAt = &later
+	hook.fail = true
+	notifiers.Notify(ctx, r)
+
+	if mail.sent != 2 {
+		t.Errorf("sent %d mails, want 2", mail.sent)
+	}
+}
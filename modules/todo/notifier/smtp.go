@@ -0,0 +1,161 @@
+package notifier
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fahmiaz411/devcode/helper/constant"
+	"github.com/fahmiaz411/devcode/modules/todo/domain"
+	"github.com/fahmiaz411/devcode/modules/todo/interfaces"
+)
+
+// smtpTimeout bounds a whole mail, from the dial to the QUIT
+const smtpTimeout = 30 * time.Second
+
+// SMTPNotifier mails a reminder to the email of its activity group, or to To when the group has none.
+// It upgrades to TLS when the server offers STARTTLS, as smtp.SendMail does.
+type SMTPNotifier struct {
+	Addr    string
+	Host    string
+	Auth    smtp.Auth
+	From    string
+	To      string
+	Timeout time.Duration
+}
+
+// NewSMTPNotifier constructor, it authenticates only when given a username
+func NewSMTPNotifier(host string, port int, username, password, from, to string) interfaces.Notifier {
+	var auth smtp.Auth
+	if username != constant.EmptyString {
+		auth = smtp.PlainAuth(constant.EmptyString, username, password, host)
+	}
+
+	return &SMTPNotifier{
+		Addr:    net.JoinHostPort(host, strconv.Itoa(port)),
+		Host:    host,
+		Auth:    auth,
+		From:    from,
+		To:      to,
+		Timeout: smtpTimeout,
+	}
+}
+
+// Notify sends the mail, a reminder with nowhere to go is dropped rather than retried
+func (n *SMTPNotifier) Notify(ctx context.Context, reminder domain.Reminder) (err error) {
+	to := reminder.Email
+	if to == constant.EmptyString {
+		to = n.To
+	}
+
+	if to == constant.EmptyString {
+		return
+	}
+
+	if err = n.send(ctx, to, mail(n.From, to, reminder)); err != nil {
+		err = fmt.Errorf("smtp: todo %d: %w", reminder.ID, err)
+		return
+	}
+
+	return
+}
+
+// send delivers msg to to over a connection that gives up at the deadline of ctx, or after Timeout
+func (n *SMTPNotifier) send(ctx context.Context, to string, msg []byte) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, n.Timeout)
+	defer cancel()
+
+	var dialer net.Dialer
+	var conn net.Conn
+	conn, err = dialer.DialContext(ctx, "tcp", n.Addr)
+	if err != nil {
+		return
+	}
+
+	// The deadline covers every command and reply of the session, a stalled server cannot hold the worker
+	deadline, _ := ctx.Deadline()
+	if err = conn.SetDeadline(deadline); err != nil {
+		conn.Close()
+		return
+	}
+
+	var c *smtp.Client
+	c, err = smtp.NewClient(conn, n.Host)
+	if err != nil {
+		conn.Close()
+		return
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err = c.StartTLS(&tls.Config{ServerName: n.Host}); err != nil {
+			return
+		}
+	}
+
+	if n.Auth != nil {
+		if ok, _ := c.Extension("AUTH"); ok {
+			if err = c.Auth(n.Auth); err != nil {
+				return
+			}
+		}
+	}
+
+	if err = c.Mail(n.From); err != nil {
+		return
+	}
+
+	if err = c.Rcpt(to); err != nil {
+		return
+	}
+
+	var w io.WriteCloser
+	w, err = c.Data()
+	if err != nil {
+		return
+	}
+
+	if _, err = w.Write(msg); err != nil {
+		w.Close()
+		return
+	}
+
+	if err = w.Close(); err != nil {
+		return
+	}
+
+	return c.Quit()
+}
+
+// mail is the message of a reminder, plain text with CRLF line endings
+func mail(from, to string, reminder domain.Reminder) []byte {
+	// A title cannot add headers of its own
+	title := strings.NewReplacer("\r", " ", "\n", " ").Replace(reminder.Title)
+
+	due := "no due date"
+	if reminder.DueAt != nil {
+		due = "due " + reminder.DueAt.Format(time.RFC1123Z)
+	}
+
+	lines := []string{
+		"From: " + from,
+		"To: " + to,
+		"Subject: Reminder: " + title,
+		"Date: " + time.Now().UTC().Format(time.RFC1123Z),
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=UTF-8",
+		"",
+		title,
+		"",
+		fmt.Sprintf("Todo %d of activity group %d, %s.", reminder.ID, reminder.ActivityGroupID, due),
+		"",
+	}
+
+	return []byte(strings.Join(lines, "\r\n"))
+}
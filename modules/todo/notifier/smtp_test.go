@@ -0,0 +1,221 @@
+package notifier
+
+import (
+	"context"
+	"net"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// smtpServer is a fake SMTP server taking a single session, it rejects the recipients in reject
+type smtpServer struct {
+	listener net.Listener
+	reject   string
+
+	// The session as received, sent once it ended
+	done chan session
+}
+
+type session struct {
+	from string
+	rcpt []string
+	data string
+}
+
+func newSMTPServer(t *testing.T, reject string) *smtpServer {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	s := &smtpServer{listener: listener, reject: reject, done: make(chan session, 1)}
+	go s.serve()
+
+	return s
+}
+
+func (s *smtpServer) serve() {
+	conn, err := s.listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	var got session
+	defer func() { s.done <- got }()
+
+	text := textproto.NewConn(conn)
+	text.PrintfLine("220 localhost ESMTP")
+
+	for {
+		line, err := text.ReadLine()
+		if err != nil {
+			return
+		}
+
+		verb := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+		switch {
+		case verb == "EHLO" || verb == "HELO":
+			text.PrintfLine("250 localhost")
+		case strings.HasPrefix(strings.ToUpper(line), "MAIL FROM:"):
+			got.from = strings.Trim(line[len("MAIL FROM:"):], "<>")
+			text.PrintfLine("250 OK")
+		case strings.HasPrefix(strings.ToUpper(line), "RCPT TO:"):
+			rcpt := strings.Trim(line[len("RCPT TO:"):], "<>")
+			if rcpt == s.reject {
+				text.PrintfLine("550 No such user")
+				continue
+			}
+			got.rcpt = append(got.rcpt, rcpt)
+			text.PrintfLine("250 OK")
+		case verb == "DATA":
+			text.PrintfLine("354 Go ahead")
+			data, err := text.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			got.data = string(data)
+			text.PrintfLine("250 Queued")
+		case verb == "QUIT":
+			text.PrintfLine("221 Bye")
+			return
+		default:
+			text.PrintfLine("502 Not implemented")
+		}
+	}
+}
+
+func (s *smtpServer) notifier(t *testing.T, to string) *SMTPNotifier {
+	t.Helper()
+
+	host, port, _ := net.SplitHostPort(s.listener.Addr().String())
+	p, _ := strconv.Atoi(port)
+
+	notifier := NewSMTPNotifier(host, p, "", "", "todo@example.com", to).(*SMTPNotifier)
+	notifier.Timeout = time.Second
+
+	return notifier
+}
+
+func (s *smtpServer) session(t *testing.T) session {
+	t.Helper()
+
+	select {
+	case got := <-s.done:
+		return got
+	case <-time.After(2 * time.Second):
+		t.Fatal("the session did not end")
+	}
+
+	return session{}
+}
+
+func TestSMTPNotify(t *testing.T) {
+	tests := []struct {
+		name  string
+		email string
+		to    string
+		want  string
+	}{
+		{name: "email of the activity group", email: "me@example.com", to: "ops@example.com", want: "me@example.com"},
+		{name: "fallback", to: "ops@example.com", want: "ops@example.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newSMTPServer(t, "")
+
+			r := reminder()
+			r.Email = tt.email
+			r.Title = "Pay rent\r\nBcc: someone@example.com"
+
+			if err := server.notifier(t, tt.to).Notify(context.Background(), r); err != nil {
+				t.Fatal(err)
+			}
+
+			got := server.session(t)
+			if got.from != "todo@example.com" {
+				t.Errorf("from = %s, want todo@example.com", got.from)
+			}
+
+			if len(got.rcpt) != 1 || got.rcpt[0] != tt.want {
+				t.Errorf("recipients = %v, want %s", got.rcpt, tt.want)
+			}
+
+			if !strings.Contains(got.data, "Subject: Reminder: Pay rent  Bcc: someone@example.com\n") {
+				t.Errorf("data = %q, want the title on the subject line", got.data)
+			}
+
+			if strings.Contains(got.data, "\nBcc:") {
+				t.Errorf("data = %q, the title added a header", got.data)
+			}
+		})
+	}
+}
+
+func TestSMTPNotifyNowhere(t *testing.T) {
+	server := newSMTPServer(t, "")
+
+	r := reminder()
+	r.Email = ""
+
+	// Dropped without a connection, rather than retried
+	if err := server.notifier(t, "").Notify(context.Background(), r); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-server.done:
+		t.Error("connected without a recipient")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestSMTPNotifyRejected(t *testing.T) {
+	server := newSMTPServer(t, "me@example.com")
+
+	err := server.notifier(t, "").Notify(context.Background(), reminder())
+	if err == nil || !strings.HasPrefix(err.Error(), "smtp: todo 7: 550") {
+		t.Fatalf("error = %v, want the rejection", err)
+	}
+}
+
+func TestSMTPNotifyStalled(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	// Accepts and never greets
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		time.Sleep(2 * time.Second)
+	}()
+
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+	p, _ := strconv.Atoi(port)
+
+	notifier := NewSMTPNotifier(host, p, "", "", "todo@example.com", "").(*SMTPNotifier)
+	notifier.Timeout = 100 * time.Millisecond
+
+	start := time.Now()
+	if err := notifier.Notify(context.Background(), reminder()); err == nil {
+		t.Fatal("want an error from a stalled server")
+	}
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("gave up after %s, want about the timeout", elapsed)
+	}
+}
@@ -0,0 +1,93 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/fahmiaz411/devcode/helper/constant"
+	"github.com/fahmiaz411/devcode/modules/todo/domain"
+	"github.com/fahmiaz411/devcode/modules/todo/interfaces"
+)
+
+const (
+	// EventReminder is the event of a reminder webhook
+	EventReminder = "todo.reminder"
+
+	// HeaderSignature carries the hex HMAC-SHA256 of the body, keyed with the secret, when there is one
+	HeaderSignature = "X-Signature-SHA256"
+
+	webhookTimeout = 10 * time.Second
+)
+
+// WebhookNotifier posts a reminder as JSON to URL, any status but 2xx is a failure
+type WebhookNotifier struct {
+	URL    string
+	Secret string
+	Client *http.Client
+}
+
+func NewWebhookNotifier(url, secret string) interfaces.Notifier {
+	return &WebhookNotifier{
+		URL:    url,
+		Secret: secret,
+		Client: &http.Client{
+			Timeout: webhookTimeout,
+		},
+	}
+}
+
+// webhookPayload is the body of a reminder webhook
+type webhookPayload struct {
+	Event string          `json:"event"`
+	Data  domain.Reminder `json:"data"`
+}
+
+func (n *WebhookNotifier) Notify(ctx context.Context, reminder domain.Reminder) (err error) {
+	var body []byte
+	body, err = json.Marshal(webhookPayload{
+		Event: EventReminder,
+		Data:  reminder,
+	})
+	if err != nil {
+		return
+	}
+
+	var req *http.Request
+	req, err = http.NewRequestWithContext(ctx, http.MethodPost, n.URL, bytes.NewReader(body))
+	if err != nil {
+		return
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if n.Secret != constant.EmptyString {
+		mac := hmac.New(sha256.New, []byte(n.Secret))
+		mac.Write(body)
+		req.Header.Set(HeaderSignature, hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	var res *http.Response
+	res, err = n.Client.Do(req)
+	if err != nil {
+		err = fmt.Errorf("webhook: todo %d: %w", reminder.ID, err)
+		return
+	}
+	defer res.Body.Close()
+
+	// Drained so the connection is reused
+	io.Copy(io.Discard, res.Body)
+
+	if res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusMultipleChoices {
+		err = fmt.Errorf("webhook: todo %d: status %d", reminder.ID, res.StatusCode)
+		return
+	}
+
+	return
+}
@@ -0,0 +1,137 @@
+package notifier
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fahmiaz411/devcode/modules/todo/domain"
+)
+
+func reminder() domain.Reminder {
+	return domain.Reminder{
+		Todo: domain.Todo{
+			ID:              7,
+			ActivityGroupID: 3,
+			Title:           "Pay rent",
+		},
+		Email: "me@example.com",
+	}
+}
+
+func TestWebhookNotify(t *testing.T) {
+	tests := []struct {
+		name   string
+		secret string
+		status int
+		err    string
+	}{
+		{name: "ok", status: http.StatusOK},
+		{name: "accepted", status: http.StatusAccepted},
+		{name: "signed", secret: "s3cret", status: http.StatusNoContent},
+		{name: "redirect", status: http.StatusMultipleChoices, err: "webhook: todo 7: status 300"},
+		{name: "server error", status: http.StatusInternalServerError, err: "webhook: todo 7: status 500"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				body      []byte
+				header    http.Header
+				requested bool
+			)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requested = true
+				header = r.Header
+				body, _ = io.ReadAll(r.Body)
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			err := NewWebhookNotifier(server.URL, tt.secret).Notify(context.Background(), reminder())
+			if tt.err == "" && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			} else if tt.err != "" && (err == nil || err.Error() != tt.err) {
+				t.Fatalf("error = %v, want %s", err, tt.err)
+			}
+
+			if !requested {
+				t.Fatal("the webhook was not called")
+			}
+
+			if got := header.Get("Content-Type"); got != "application/json" {
+				t.Errorf("Content-Type = %s, want application/json", got)
+			}
+
+			var payload struct {
+				Event string          `json:"event"`
+				Data  domain.Reminder `json:"data"`
+			}
+			if err := json.Unmarshal(body, &payload); err != nil {
+				t.Fatal(err)
+			}
+
+			if payload.Event != EventReminder || payload.Data.ID != 7 || payload.Data.Email != "me@example.com" {
+				t.Errorf("payload = %s", body)
+			}
+
+			// The signature is the hex HMAC-SHA256 of the body, absent without a secret
+			want := ""
+			if tt.secret != "" {
+				mac := hmac.New(sha256.New, []byte(tt.secret))
+				mac.Write(body)
+				want = hex.EncodeToString(mac.Sum(nil))
+			}
+
+			if got := header.Get(HeaderSignature); got != want {
+				t.Errorf("%s = %q, want %q", HeaderSignature, got, want)
+			}
+		})
+	}
+}
+
+func TestWebhookNotifyTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	notifier := NewWebhookNotifier(server.URL, "").(*WebhookNotifier)
+	notifier.Client.Timeout = 50 * time.Millisecond
+
+	start := time.Now()
+	err := notifier.Notify(context.Background(), reminder())
+	if err == nil || !strings.HasPrefix(err.Error(), "webhook: todo 7: ") {
+		t.Fatalf("error = %v, want a timeout", err)
+	}
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("gave up after %s, want about the timeout", elapsed)
+	}
+}
+
+func TestWebhookNotifyCanceled(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if err := NewWebhookNotifier(server.URL, "").Notify(ctx, reminder()); err == nil {
+		t.Fatal("want an error once the context is done")
+	}
+}
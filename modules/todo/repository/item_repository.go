@@ -1,19 +1,48 @@
 package repository
 
 import (
-	"database/sql"
-
+	"github.com/fahmiaz411/devcode/config/database"
 	"github.com/fahmiaz411/devcode/modules/todo/interfaces"
-	"github.com/fahmiaz411/devcode/modules/todo/repository/mysql"
+	"github.com/fahmiaz411/devcode/modules/todo/repository/memory"
+	"github.com/fahmiaz411/devcode/modules/todo/repository/sqlstore"
 )
 
 type Repository struct {
-	MySQL interfaces.TodoRepoMysql
+	Store interfaces.TodoRepository
+
+	Checklist interfaces.ChecklistRepository
+
+	Tags interfaces.TagRepository
+
+	// Transactor runs a unit of work across the stores of every module
+	Transactor database.Transactor
 }
 
-// NewRepository constructor
-func NewRepository(mysqlConn *sql.DB) *Repository {
+// NewRepository constructor, the SQL drivers share a store speaking their dialect
+func NewRepository(db *database.Database) *Repository {
+	var (
+		store     interfaces.TodoRepository
+		checklist interfaces.ChecklistRepository
+		tags      interfaces.TagRepository
+	)
+
+	switch db.Driver {
+	case database.DriverMemory:
+		store = memory.NewMemoryRepository(db.Memory)
+		checklist = memory.NewMemoryChecklistRepository(db.Memory)
+		tags = memory.NewMemoryTagRepository(db.Memory)
+	default:
+		dialect := database.Dialect(db.Driver)
+
+		store = sqlstore.NewSqlRepository(db.Conn, dialect)
+		checklist = sqlstore.NewSqlChecklistRepository(db.Conn, dialect)
+		tags = sqlstore.NewSqlTagRepository(db.Conn, dialect)
+	}
+
 	return &Repository{
-		MySQL: mysql.NewMysqlRepository(mysqlConn),
+		Store:      store,
+		Checklist:  checklist,
+		Tags:       tags,
+		Transactor: database.NewTransactor(db),
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,172 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/fahmiaz411/devcode/config/database"
+	"github.com/fahmiaz411/devcode/helper/constant"
+	"github.com/fahmiaz411/devcode/modules/todo/domain"
+	"github.com/fahmiaz411/devcode/modules/todo/interfaces"
+)
+
+const (
+	// checklist holds the checklist items by item ID
+	checklist = "todo_checklist_items"
+
+	// checklistByTodo holds the IDs of the checklist items of each todo, by todo ID
+	checklistByTodo = "todo_checklist_items_by_todo"
+)
+
+type MemoryChecklistRepository struct {
+	DB *database.MemoryDB
+}
+
+func NewMemoryChecklistRepository(DB *database.MemoryDB) interfaces.ChecklistRepository {
+	return &MemoryChecklistRepository{
+		DB: DB,
+	}
+}
+
+func (m *MemoryChecklistRepository) Create(ctx context.Context, req domain.ChecklistCreateRequest) (res domain.ChecklistCreateResponse, err error) {
+	now := time.Now().UTC()
+
+	defer m.DB.Write(ctx)()
+
+	items := m.DB.Table(checklist)
+
+	res.ID = items.NextID()
+	res.TodoID = req.TodoID
+	res.Title = req.Title
+	res.IsDone = req.IsDone
+	res.Position = req.Position
+	res.CreatedAt = now
+	res.UpdatedAt = now
+
+	items.Set(res.ID, res.ChecklistItem)
+
+	byTodo := m.DB.Table(checklistByTodo)
+	byTodo.Set(req.TodoID, append(itemIDs(m.DB, req.TodoID), res.ID))
+
+	return
+}
+
+func (m *MemoryChecklistRepository) Update(ctx context.Context, req domain.ChecklistUpdateRequest) (res domain.ChecklistUpdateResponse, err error) {
+	defer m.DB.Write(ctx)()
+
+	items := m.DB.Table(checklist)
+
+	row, ok := items.Rows[req.ID]
+	if !ok || row.(domain.ChecklistItem).TodoID != req.TodoID {
+		return
+	}
+
+	item := row.(domain.ChecklistItem)
+
+	if req.Title != constant.EmptyString {
+		item.Title = req.Title
+	}
+
+	if req.IsDone != nil {
+		item.IsDone = *req.IsDone
+	}
+
+	if req.Position != nil {
+		item.Position = *req.Position
+	}
+
+	item.UpdatedAt = req.UpdatedAt
+	items.Set(req.ID, item)
+
+	return
+}
+
+func (m *MemoryChecklistRepository) Delete(ctx context.Context, req domain.ChecklistDeleteRequest) (res domain.ChecklistDeleteResponse, err error) {
+	defer m.DB.Write(ctx)()
+
+	items := m.DB.Table(checklist)
+
+	if row, ok := items.Rows[req.ID]; ok && row.(domain.ChecklistItem).TodoID == req.TodoID {
+		items.Delete(req.ID)
+
+		rest := []int64{}
+		for _, id := range itemIDs(m.DB, req.TodoID) {
+			if id != req.ID {
+				rest = append(rest, id)
+			}
+		}
+		m.DB.Table(checklistByTodo).Set(req.TodoID, rest)
+	}
+
+	return
+}
+
+func (m *MemoryChecklistRepository) Check(ctx context.Context, req domain.ChecklistCheckRequest) (res domain.ChecklistCheckResponse, err error) {
+	defer m.DB.Write(ctx)()
+
+	items := m.DB.Table(checklist)
+
+	for _, id := range itemIDs(m.DB, req.TodoID) {
+		if item := items.Rows[id].(domain.ChecklistItem); !item.IsDone {
+			item.IsDone = true
+			item.UpdatedAt = req.UpdatedAt
+			items.Set(id, item)
+		}
+	}
+
+	return
+}
+
+func (m *MemoryChecklistRepository) GetAll(ctx context.Context, req domain.ChecklistGetAllRequest) (res domain.ChecklistGetAllResponse, err error) {
+	defer m.DB.Read(ctx)()
+
+	items := m.DB.Table(checklist)
+
+	res.Items = []domain.ChecklistItem{}
+	for _, id := range itemIDs(m.DB, req.TodoID) {
+		res.Items = append(res.Items, items.Rows[id].(domain.ChecklistItem))
+	}
+
+	sort.Slice(res.Items, func(i, j int) bool {
+		if res.Items[i].Position != res.Items[j].Position {
+			return res.Items[i].Position < res.Items[j].Position
+		}
+		return res.Items[i].ID < res.Items[j].ID
+	})
+
+	return
+}
+
+// itemIDs are the IDs of the checklist items of a todo, a new slice the caller may append to
+func itemIDs(db *database.MemoryDB, todoID int64) []int64 {
+	ids, _ := db.Table(checklistByTodo).Rows[todoID].([]int64)
+
+	return append([]int64{}, ids...)
+}
+
+// counts are the checklist items of a todo and those done
+func counts(db *database.MemoryDB, todoID int64) (total, done int64) {
+	items := db.Table(checklist)
+
+	for _, id := range itemIDs(db, todoID) {
+		total++
+		if items.Rows[id].(domain.ChecklistItem).IsDone {
+			done++
+		}
+	}
+
+	return
+}
+
+// purge deletes the checklist items of the todos
+func purge(db *database.MemoryDB, todoIDs ...int64) {
+	items, byTodo := db.Table(checklist), db.Table(checklistByTodo)
+
+	for _, todoID := range todoIDs {
+		for _, id := range itemIDs(db, todoID) {
+			items.Delete(id)
+		}
+		byTodo.Delete(todoID)
+	}
+}
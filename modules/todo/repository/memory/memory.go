@@ -0,0 +1,754 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fahmiaz411/devcode/config/database"
+	"github.com/fahmiaz411/devcode/helper/constant"
+	"github.com/fahmiaz411/devcode/helper/pagination"
+	"github.com/fahmiaz411/devcode/helper/slice"
+	"github.com/fahmiaz411/devcode/modules/todo/domain"
+	"github.com/fahmiaz411/devcode/modules/todo/interfaces"
+)
+
+const (
+	table = "todos"
+
+	// claims holds the reminder leases by todo ID, they are not part of the todo
+	claims = "todo_reminder_claims"
+)
+
+type MemoryRepository struct {
+	DB *database.MemoryDB
+}
+
+func NewMemoryRepository(DB *database.MemoryDB) interfaces.TodoRepository {
+	return &MemoryRepository{
+		DB: DB,
+	}
+}
+
+func (m *MemoryRepository) Create(ctx context.Context, req domain.TodoCreateRequest) (res domain.TodoCreateResponse, err error) {
+	now := time.Now().UTC()
+
+	defer m.DB.Write(ctx)()
+
+	todos := m.DB.Table(table)
+
+	res.ID = todos.NextID()
+	res.Title = req.Title
+	res.ActivityGroupID = req.ActivityGroupID
+	res.Priority, res.Occurrence = series(req)
+	res.Position = req.Position
+	res.DueAt = req.DueAt
+	res.RemindAt = req.RemindAt
+	res.Recurrence = req.Recurrence
+	res.CreatedAt = now
+	res.UpdatedAt = now
+	res.Version = domain.VersionDefault
+
+	if req.IsActive != nil {
+		res.IsActive = *req.IsActive
+	} else {
+		res.IsActive = domain.IsActiveDefault
+	}
+
+	res.CompletedAt = domain.CompletedAt(res.IsActive, now)
+	res.Tags = []string{}
+
+	todos.Set(res.ID, res.Todo)
+
+	return
+}
+
+func (m *MemoryRepository) Update(ctx context.Context, req domain.TodoUpdateRequest) (res domain.TodoUpdateResponse, err error) {
+	defer m.DB.Write(ctx)()
+
+	todos := m.DB.Table(table)
+
+	row, ok := todos.Rows[req.ID]
+	if !ok || row.(domain.Todo).Version != req.Version {
+		err = domain.ErrVersionConflict
+		return
+	}
+
+	todo := row.(domain.Todo)
+
+	if req.ActivityGroupID != int64(constant.ZeroValue) {
+		todo.ActivityGroupID = req.ActivityGroupID
+	}
+
+	if req.Title != constant.EmptyString {
+		todo.Title = req.Title
+	}
+
+	if req.IsActive != nil {
+		todo.SetActive(*req.IsActive, req.UpdatedAt)
+	}
+
+	if req.Priority != constant.EmptyString {
+		todo.Priority = req.Priority
+	}
+
+	if req.Position != nil {
+		todo.Position = *req.Position
+	}
+
+	if req.DueAt.Set {
+		todo.DueAt = req.DueAt.Time
+	}
+
+	// A new reminder time is reminded of again
+	if req.RemindAt.Set {
+		todo.RemindAt = req.RemindAt.Time
+		todo.RemindedAt = nil
+		todo.RemindAttempts = int64(constant.ZeroValue)
+	}
+
+	if req.Recurrence.Set {
+		todo.Recurrence = req.Recurrence.Recurrence
+	}
+
+	if req.NextID != nil {
+		todo.NextID = req.NextID
+	}
+
+	todo.UpdatedAt = req.UpdatedAt
+	todo.Version++
+	todos.Set(req.ID, todo)
+
+	return
+}
+
+func (m *MemoryRepository) Delete(ctx context.Context, req domain.TodoDeleteRequest) (res domain.TodoDeleteResponse, err error) {
+	now := time.Now().UTC()
+
+	defer m.DB.Write(ctx)()
+
+	todos := m.DB.Table(table)
+
+	row, ok := todos.Rows[req.ID]
+	if !ok || row.(domain.Todo).Version != req.Version {
+		err = domain.ErrVersionConflict
+		return
+	}
+
+	todo := row.(domain.Todo)
+	todo.DeletedAt = &now
+	todo.UpdatedAt = now
+	todo.Version++
+	todos.Set(req.ID, todo)
+
+	return
+}
+
+func (m *MemoryRepository) Restore(ctx context.Context, req domain.TodoRestoreRequest) (res domain.TodoRestoreResponse, err error) {
+	defer m.DB.Write(ctx)()
+
+	todos := m.DB.Table(table)
+
+	row, ok := todos.Rows[req.ID]
+	if !ok || row.(domain.Todo).Version != req.Version || row.(domain.Todo).DeletedAt == nil {
+		err = domain.ErrVersionConflict
+		return
+	}
+
+	todo := row.(domain.Todo)
+	todo.DeletedAt = nil
+	todo.UpdatedAt = req.UpdatedAt
+	todo.Version++
+	todos.Set(req.ID, todo)
+
+	return
+}
+
+func (m *MemoryRepository) Purge(ctx context.Context, req domain.TodoPurgeRequest) (res domain.TodoPurgeResponse, err error) {
+	defer m.DB.Write(ctx)()
+
+	m.DB.Table(table).Delete(req.ID)
+	purge(m.DB, req.ID)
+	untag(m.DB, req.ID)
+
+	return
+}
+
+func (m *MemoryRepository) BulkCreate(ctx context.Context, req domain.TodoBulkCreateRequest) (res domain.TodoBulkCreateResponse, err error) {
+	now := time.Now().UTC()
+
+	defer m.DB.Write(ctx)()
+
+	todos := m.DB.Table(table)
+
+	res.Todos = make([]domain.Todo, len(req.Todos))
+	for i, todo := range req.Todos {
+		priority, occurrence := series(todo)
+
+		res.Todos[i] = domain.Todo{
+			ID: todos.NextID(),
+			ActivityGroupID: todo.ActivityGroupID,
+			Title: todo.Title,
+			IsActive: domain.IsActiveDefault,
+			Priority: priority,
+			Position: todo.Position,
+			DueAt: todo.DueAt,
+			RemindAt: todo.RemindAt,
+			Recurrence: todo.Recurrence,
+			Occurrence: occurrence,
+			CreatedAt: now,
+			UpdatedAt: now,
+			Tags: []string{},
+			Version: domain.VersionDefault,
+		}
+
+		if todo.IsActive != nil {
+			res.Todos[i].IsActive = *todo.IsActive
+		}
+
+		res.Todos[i].CompletedAt = domain.CompletedAt(res.Todos[i].IsActive, now)
+
+		todos.Set(res.Todos[i].ID, res.Todos[i])
+	}
+
+	return
+}
+
+func (m *MemoryRepository) BulkUpdate(ctx context.Context, req domain.TodoBulkUpdateRequest) (res domain.TodoBulkUpdateResponse, err error) {
+	defer m.DB.Write(ctx)()
+
+	todos := m.DB.Table(table)
+
+	// Every todo is checked before any is written, as the single statement of the SQL stores
+	for _, item := range req.Todos {
+		if row, ok := todos.Rows[item.ID]; !ok || row.(domain.Todo).Version != item.Version {
+			err = domain.ErrVersionConflict
+			return
+		}
+	}
+
+	for _, item := range req.Todos {
+		todo := todos.Rows[item.ID].(domain.Todo)
+
+		if item.ActivityGroupID != int64(constant.ZeroValue) {
+			todo.ActivityGroupID = item.ActivityGroupID
+		}
+
+		if item.Title != constant.EmptyString {
+			todo.Title = item.Title
+		}
+
+		if item.IsActive != nil {
+			todo.SetActive(*item.IsActive, req.UpdatedAt)
+		}
+
+		if item.Priority != constant.EmptyString {
+			todo.Priority = item.Priority
+		}
+
+		if item.Position != nil {
+			todo.Position = *item.Position
+		}
+
+		if item.DueAt.Set {
+			todo.DueAt = item.DueAt.Time
+		}
+
+		if item.RemindAt.Set {
+			todo.RemindAt = item.RemindAt.Time
+			todo.RemindedAt = nil
+			todo.RemindAttempts = int64(constant.ZeroValue)
+		}
+
+		if item.Recurrence.Set {
+			todo.Recurrence = item.Recurrence.Recurrence
+		}
+
+		if item.NextID != nil {
+			todo.NextID = item.NextID
+		}
+
+		todo.UpdatedAt = req.UpdatedAt
+		todo.Version++
+		todos.Set(item.ID, todo)
+	}
+
+	return
+}
+
+func (m *MemoryRepository) BulkDelete(ctx context.Context, req domain.TodoBulkDeleteRequest) (res domain.TodoBulkDeleteResponse, err error) {
+	now := time.Now().UTC()
+
+	defer m.DB.Write(ctx)()
+
+	todos := m.DB.Table(table)
+
+	for _, item := range req.Todos {
+		if row, ok := todos.Rows[item.ID]; !ok || row.(domain.Todo).Version != item.Version {
+			err = domain.ErrVersionConflict
+			return
+		}
+	}
+
+	for _, item := range req.Todos {
+		todo := todos.Rows[item.ID].(domain.Todo)
+		todo.DeletedAt = &now
+		todo.UpdatedAt = now
+		todo.Version++
+		todos.Set(item.ID, todo)
+	}
+
+	return
+}
+
+func (m *MemoryRepository) CountByActivity(ctx context.Context, req domain.TodoCountByActivityRequest) (res domain.TodoCountByActivityResponse, err error) {
+	defer m.DB.Read(ctx)()
+
+	for _, row := range m.DB.Table(table).Rows {
+		if todo := row.(domain.Todo); todo.ActivityGroupID == req.ActivityGroupID && (req.WithDeleted || todo.DeletedAt == nil) {
+			res.Count++
+		}
+	}
+
+	return
+}
+
+func (m *MemoryRepository) DeleteByActivity(ctx context.Context, req domain.TodoDeleteByActivityRequest) (res domain.TodoDeleteByActivityResponse, err error) {
+	defer m.DB.Write(ctx)()
+
+	todos := m.DB.Table(table)
+
+	for id, row := range todos.Rows {
+		if todo := row.(domain.Todo); todo.ActivityGroupID == req.ActivityGroupID && todo.DeletedAt == nil {
+			todo.DeletedAt = &req.DeletedAt
+			todo.UpdatedAt = req.DeletedAt
+			todo.Version++
+			todos.Set(id, todo)
+		}
+	}
+
+	return
+}
+
+func (m *MemoryRepository) RestoreByActivity(ctx context.Context, req domain.TodoRestoreByActivityRequest) (res domain.TodoRestoreByActivityResponse, err error) {
+	defer m.DB.Write(ctx)()
+
+	todos := m.DB.Table(table)
+
+	for id, row := range todos.Rows {
+		if todo := row.(domain.Todo); todo.ActivityGroupID == req.ActivityGroupID && todo.DeletedAt != nil && todo.DeletedAt.Equal(req.DeletedAt) {
+			todo.DeletedAt = nil
+			todo.UpdatedAt = req.UpdatedAt
+			todo.Version++
+			todos.Set(id, todo)
+		}
+	}
+
+	return
+}
+
+func (m *MemoryRepository) PurgeByActivity(ctx context.Context, req domain.TodoPurgeByActivityRequest) (res domain.TodoPurgeByActivityResponse, err error) {
+	defer m.DB.Write(ctx)()
+
+	todos := m.DB.Table(table)
+
+	purged := []int64{}
+	for id, row := range todos.Rows {
+		if row.(domain.Todo).ActivityGroupID == req.ActivityGroupID {
+			todos.Delete(id)
+			purged = append(purged, id)
+		}
+	}
+
+	purge(m.DB, purged...)
+	untag(m.DB, purged...)
+
+	return
+}
+
+func (m *MemoryRepository) Renumber(ctx context.Context, req domain.TodoRenumberRequest) (res domain.TodoRenumberResponse, err error) {
+	defer m.DB.Write(ctx)()
+
+	todos := m.DB.Table(table)
+
+	for _, item := range req.Todos {
+		if row, ok := todos.Rows[item.ID]; ok {
+			todo := row.(domain.Todo)
+			todo.Position = item.Position
+			todo.UpdatedAt = req.UpdatedAt
+			todo.Version++
+			todos.Set(item.ID, todo)
+		}
+	}
+
+	return
+}
+
+func (m *MemoryRepository) Touch(ctx context.Context, req domain.TodoTouchRequest) (res domain.TodoTouchResponse, err error) {
+	defer m.DB.Write(ctx)()
+
+	todos := m.DB.Table(table)
+
+	row, ok := todos.Rows[req.ID]
+	if !ok || row.(domain.Todo).Version != req.Version {
+		err = domain.ErrVersionConflict
+		return
+	}
+
+	todo := row.(domain.Todo)
+	todo.UpdatedAt = req.UpdatedAt
+	todo.Version++
+	todos.Set(req.ID, todo)
+
+	return
+}
+
+func (m *MemoryRepository) GroupCount(ctx context.Context, req domain.TodoGroupCountRequest) (res domain.TodoGroupCountResponse, err error) {
+	defer m.DB.Read(ctx)()
+
+	groups := map[domain.TodoGroupCount]int64{}
+	for _, row := range m.DB.Table(table).Rows {
+		if todo := row.(domain.Todo); todo.DeletedAt == nil && slice.Includes(req.ActivityGroupIDs, todo.ActivityGroupID) {
+			groups[domain.TodoGroupCount{
+				ActivityGroupID: todo.ActivityGroupID,
+				Priority: todo.Priority,
+				IsActive: todo.IsActive,
+			}]++
+		}
+	}
+
+	res.Groups = []domain.TodoGroupCount{}
+	for group, count := range groups {
+		group.Count = count
+		res.Groups = append(res.Groups, group)
+	}
+
+	return
+}
+
+func (m *MemoryRepository) Aggregate(ctx context.Context, req domain.TodoAggregateRequest) (res domain.TodoAggregateResponse, err error) {
+	defer m.DB.Read(ctx)()
+
+	groups := map[domain.TodoGroupCount]int64{}
+	res.Created = map[string]int64{}
+	res.Completed = map[string]int64{}
+
+	var (
+		seconds float64
+		completed int
+	)
+
+	inRange := func(t time.Time) bool {
+		return !t.Before(req.From) && t.Before(req.To)
+	}
+
+	for _, row := range m.DB.Table(table).Rows {
+		todo := row.(domain.Todo)
+		if todo.DeletedAt != nil || (req.ActivityGroupID != int64(constant.ZeroValue) && todo.ActivityGroupID != req.ActivityGroupID) {
+			continue
+		}
+
+		groups[domain.TodoGroupCount{
+			ActivityGroupID: todo.ActivityGroupID,
+			Priority: todo.Priority,
+			IsActive: todo.IsActive,
+		}]++
+
+		if inRange(todo.CreatedAt) {
+			res.Created[todo.CreatedAt.Format(time.DateOnly)]++
+		}
+
+		if todo.CompletedAt != nil && inRange(*todo.CompletedAt) {
+			res.Completed[todo.CompletedAt.Format(time.DateOnly)]++
+			seconds += todo.CompletedAt.Sub(todo.CreatedAt).Seconds()
+			completed++
+		}
+	}
+
+	res.Groups = []domain.TodoGroupCount{}
+	for group, count := range groups {
+		group.Count = count
+		res.Groups = append(res.Groups, group)
+	}
+
+	if completed != constant.ZeroValue {
+		avg := seconds / float64(completed)
+		res.AvgCompletionSeconds = &avg
+	}
+
+	return
+}
+
+func (m *MemoryRepository) LastPosition(ctx context.Context, req domain.TodoLastPositionRequest) (res domain.TodoLastPositionResponse, err error) {
+	defer m.DB.Read(ctx)()
+
+	// The trash counts, a restored todo keeps a place of its own
+	for _, row := range m.DB.Table(table).Rows {
+		if todo := row.(domain.Todo); todo.ActivityGroupID == req.ActivityGroupID && todo.Position > res.Position {
+			res.Position = todo.Position
+		}
+	}
+
+	return
+}
+
+func (m *MemoryRepository) Neighbour(ctx context.Context, req domain.TodoNeighbourRequest) (res domain.TodoNeighbourResponse, err error) {
+	defer m.DB.Read(ctx)()
+
+	// before tells whether a sits before b, todos sharing a position are ordered by ID
+	before := func(a, b domain.Todo) bool {
+		if a.Position != b.Position {
+			return a.Position < b.Position
+		}
+
+		return a.ID < b.ID
+	}
+
+	target := domain.Todo{ID: req.ID, Position: req.Position}
+
+	var found *domain.Todo
+	for _, row := range m.DB.Table(table).Rows {
+		todo := row.(domain.Todo)
+		if todo.ActivityGroupID != req.ActivityGroupID || todo.DeletedAt != nil || todo.ID == req.ExcludeID {
+			continue
+		}
+
+		if req.After {
+			if before(target, todo) && (found == nil || before(todo, *found)) {
+				found = &todo
+			}
+		} else if before(todo, target) && (found == nil || before(*found, todo)) {
+			found = &todo
+		}
+	}
+
+	if found != nil {
+		res.ID = found.ID
+		res.Position = found.Position
+	}
+
+	return
+}
+
+// claim is the reminder lease of a todo
+type claim struct {
+	Claim string
+	Until time.Time
+}
+
+func (m *MemoryRepository) ClaimReminders(ctx context.Context, req domain.TodoClaimRemindersRequest) (res domain.TodoClaimRemindersResponse, err error) {
+	defer m.DB.Write(ctx)()
+
+	leases := m.DB.Table(claims)
+
+	todos := m.DB.Table(table)
+
+	// As the SQL stores: the live, active todos whose reminder is due and neither sent, leased nor given up
+	due := []domain.Todo{}
+	for _, row := range todos.Rows {
+		todo := row.(domain.Todo)
+		if todo.DeletedAt != nil || !todo.IsActive || todo.RemindAt == nil || todo.RemindAt.After(req.Now) || todo.RemindedAt != nil ||
+			todo.RemindAttempts >= domain.RemindAttemptsMax {
+			continue
+		}
+
+		if lease, ok := leases.Rows[todo.ID]; ok && lease.(claim).Until.After(req.Now) {
+			continue
+		}
+
+		due = append(due, todo)
+	}
+
+	sort.Slice(due, func(i, j int) bool {
+		if !due[i].RemindAt.Equal(*due[j].RemindAt) {
+			return due[i].RemindAt.Before(*due[j].RemindAt)
+		}
+
+		return due[i].ID < due[j].ID
+	})
+
+	if len(due) > req.Limit {
+		due = due[:req.Limit]
+	}
+
+	for i := range due {
+		due[i].RemindAttempts++
+		todos.Set(due[i].ID, due[i])
+		leases.Set(due[i].ID, claim{Claim: req.Claim, Until: req.Until})
+	}
+
+	sort.Slice(due, func(i, j int) bool { return due[i].ID < due[j].ID })
+	res.Todos = due
+
+	return
+}
+
+func (m *MemoryRepository) RenewReminder(ctx context.Context, req domain.TodoRenewReminderRequest) (res domain.TodoRenewReminderResponse, err error) {
+	defer m.DB.Write(ctx)()
+
+	leases := m.DB.Table(claims)
+
+	lease, ok := leases.Rows[req.ID]
+	if !ok || lease.(claim).Claim != req.Claim {
+		return
+	}
+
+	leases.Set(req.ID, claim{Claim: req.Claim, Until: req.Until})
+	res.Renewed = true
+
+	return
+}
+
+func (m *MemoryRepository) MarkReminded(ctx context.Context, req domain.TodoMarkRemindedRequest) (res domain.TodoMarkRemindedResponse, err error) {
+	defer m.DB.Write(ctx)()
+
+	leases := m.DB.Table(claims)
+	todos := m.DB.Table(table)
+
+	lease, ok := leases.Rows[req.ID]
+	row, exists := todos.Rows[req.ID]
+	if !ok || !exists || lease.(claim).Claim != req.Claim {
+		return
+	}
+
+	todo := row.(domain.Todo)
+	todo.RemindedAt = &req.RemindedAt
+	todo.UpdatedAt = req.RemindedAt
+	todo.Version++
+	todos.Set(req.ID, todo)
+	leases.Delete(req.ID)
+
+	res.Marked = true
+
+	return
+}
+
+func (m *MemoryRepository) GetAll(ctx context.Context, req domain.TodoGetAllRequest) (res domain.TodoGetAllResponse, err error) {
+	defer m.DB.Read(ctx)()
+
+	todos := []domain.Todo{}
+	for _, row := range m.DB.Table(table).Rows {
+		if todo := withTags(m.DB, row.(domain.Todo)); (todo.DeletedAt != nil) == req.Trashed && match(req, todo) {
+			todo.SetChecklist(counts(m.DB, todo.ID))
+			todos = append(todos, todo)
+		}
+	}
+
+	res.Todos, res.Paging, err = pagination.Paginate(req.Request, todos,
+		func(todo domain.Todo) int64 { return todo.ID },
+		func(todo domain.Todo) string { return todo.SortValue(req.Sort) },
+		domain.ParseSortValue,
+	)
+
+	return
+}
+
+func (m *MemoryRepository) GetAllStamp(ctx context.Context, req domain.TodoGetAllRequest) (res domain.TodoGetAllStampResponse, err error) {
+	defer m.DB.Read(ctx)()
+
+	// Listed rows are counted, the filtered ones that left the list still date it
+	for _, row := range m.DB.Table(table).Rows {
+		todo := withTags(m.DB, row.(domain.Todo))
+		if !match(req, todo) {
+			continue
+		}
+
+		if todo.UpdatedAt.After(res.LastModified) {
+			res.LastModified = todo.UpdatedAt
+		}
+
+		if (todo.DeletedAt != nil) == req.Trashed {
+			res.Count++
+			res.Versions += todo.Version
+			if todo.ID > res.LastID {
+				res.LastID = todo.ID
+			}
+		}
+	}
+
+	return
+}
+
+func (m *MemoryRepository) GetOne(ctx context.Context, req domain.TodoGetOneRequest) (res domain.TodoGetOneResponse, err error) {
+	defer m.DB.Read(ctx)()
+
+	if row, ok := m.DB.Table(table).Rows[req.ID]; ok && (row.(domain.Todo).DeletedAt != nil) == req.Trashed {
+		res.Todo = withTags(m.DB, row.(domain.Todo))
+		res.SetChecklist(counts(m.DB, req.ID))
+	}
+
+	return
+}
+
+// series are the priority and the occurrence of a new todo, those of a series or the defaults
+func series(req domain.TodoCreateRequest) (priority string, occurrence int64) {
+	priority, occurrence = req.Priority, req.Occurrence
+
+	if priority == constant.EmptyString {
+		priority = domain.PriorityDefault
+	}
+
+	if occurrence == int64(constant.ZeroValue) {
+		occurrence = domain.OccurrenceDefault
+	}
+
+	return
+}
+
+// match is the WHERE clause of GetAll but for the trash condition
+func match(req domain.TodoGetAllRequest, todo domain.Todo) bool {
+	if len(req.IDs) != constant.ZeroValue && !slice.Includes(req.IDs, todo.ID) {
+		return false
+	}
+
+	if req.ActivityGroupID != int64(constant.ZeroValue) && todo.ActivityGroupID != req.ActivityGroupID {
+		return false
+	}
+
+	if len(req.ActivityGroupIDs) != constant.ZeroValue && !slice.Includes(req.ActivityGroupIDs, todo.ActivityGroupID) {
+		return false
+	}
+
+	if req.IsActive != nil && todo.IsActive != *req.IsActive {
+		return false
+	}
+
+	if len(req.Priorities) != constant.ZeroValue && !slice.Includes(req.Priorities, todo.Priority) {
+		return false
+	}
+
+	if req.Title != constant.EmptyString && !strings.Contains(strings.ToLower(todo.Title), strings.ToLower(req.Title)) {
+		return false
+	}
+
+	if len(req.Tags) != constant.ZeroValue && !tagged(todo, req.Tags) {
+		return false
+	}
+
+	if req.CreatedFrom != nil && todo.CreatedAt.Before(*req.CreatedFrom) {
+		return false
+	}
+
+	if req.CreatedTo != nil && !todo.CreatedAt.Before(*req.CreatedTo) {
+		return false
+	}
+
+	if req.UpdatedFrom != nil && todo.UpdatedAt.Before(*req.UpdatedFrom) {
+		return false
+	}
+
+	if req.UpdatedTo != nil && !todo.UpdatedAt.Before(*req.UpdatedTo) {
+		return false
+	}
+
+	if req.DueFrom != nil && (todo.DueAt == nil || todo.DueAt.Before(*req.DueFrom)) {
+		return false
+	}
+
+	if req.DueTo != nil && (todo.DueAt == nil || !todo.DueAt.Before(*req.DueTo)) {
+		return false
+	}
+
+	return true
+}
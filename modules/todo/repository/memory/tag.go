@@ -0,0 +1,237 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/fahmiaz411/devcode/config/database"
+	"github.com/fahmiaz411/devcode/helper/constant"
+	"github.com/fahmiaz411/devcode/helper/slice"
+	"github.com/fahmiaz411/devcode/modules/todo/domain"
+	"github.com/fahmiaz411/devcode/modules/todo/interfaces"
+)
+
+const (
+	// tags holds the tags by tag ID
+	tags = "tags"
+
+	// todoTags holds the IDs of the tags of each todo, by todo ID
+	todoTags = "todo_tags"
+)
+
+type MemoryTagRepository struct {
+	DB *database.MemoryDB
+}
+
+func NewMemoryTagRepository(DB *database.MemoryDB) interfaces.TagRepository {
+	return &MemoryTagRepository{
+		DB: DB,
+	}
+}
+
+func (m *MemoryTagRepository) Create(ctx context.Context, req domain.TagCreateRequest) (res domain.TagCreateResponse, err error) {
+	now := time.Now().UTC()
+
+	defer m.DB.Write(ctx)()
+
+	rows := m.DB.Table(tags)
+	if named(rows, req.Name, int64(constant.ZeroValue)) {
+		err = domain.ErrTagExists
+		return
+	}
+
+	res.ID = rows.NextID()
+	res.Name = req.Name
+	res.CreatedAt = now
+	res.UpdatedAt = now
+
+	rows.Set(res.ID, res.Tag)
+
+	return
+}
+
+func (m *MemoryTagRepository) Update(ctx context.Context, req domain.TagUpdateRequest) (res domain.TagUpdateResponse, err error) {
+	defer m.DB.Write(ctx)()
+
+	rows := m.DB.Table(tags)
+
+	row, ok := rows.Rows[req.ID]
+	if !ok {
+		return
+	}
+
+	if named(rows, req.Name, req.ID) {
+		err = domain.ErrTagExists
+		return
+	}
+
+	tag := row.(domain.Tag)
+	tag.Name = req.Name
+	tag.UpdatedAt = req.UpdatedAt
+	rows.Set(req.ID, tag)
+
+	m.touch(req.ID, req.UpdatedAt)
+
+	return
+}
+
+func (m *MemoryTagRepository) Delete(ctx context.Context, req domain.TagDeleteRequest) (res domain.TagDeleteResponse, err error) {
+	defer m.DB.Write(ctx)()
+
+	m.touch(req.ID, req.UpdatedAt)
+
+	links := m.DB.Table(todoTags)
+	for todoID, row := range links.Rows {
+		if ids := row.([]int64); slice.Includes(ids, req.ID) {
+			links.Set(todoID, without(ids, req.ID))
+		}
+	}
+
+	m.DB.Table(tags).Delete(req.ID)
+
+	return
+}
+
+// named tells whether a tag other than id has the name, as the unique key of the SQL stores
+func named(rows *database.MemoryTable, name string, id int64) bool {
+	for other, row := range rows.Rows {
+		if other != id && row.(domain.Tag).Name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// touch makes a new version of the todos carrying a tag
+func (m *MemoryTagRepository) touch(id int64, at time.Time) {
+	todos := m.DB.Table(table)
+
+	for todoID, row := range m.DB.Table(todoTags).Rows {
+		if !slice.Includes(row.([]int64), id) {
+			continue
+		}
+
+		if row, ok := todos.Rows[todoID]; ok {
+			todo := row.(domain.Todo)
+			todo.UpdatedAt = at
+			todo.Version++
+			todos.Set(todoID, todo)
+		}
+	}
+}
+
+func (m *MemoryTagRepository) GetAll(ctx context.Context, req domain.TagGetAllRequest) (res domain.TagGetAllResponse, err error) {
+	defer m.DB.Read(ctx)()
+
+	res.Tags = []domain.Tag{}
+	for _, row := range m.DB.Table(tags).Rows {
+		tag := row.(domain.Tag)
+		if len(req.Names) == constant.ZeroValue || slice.Includes(req.Names, tag.Name) {
+			res.Tags = append(res.Tags, tag)
+		}
+	}
+
+	sort.Slice(res.Tags, func(i, j int) bool {
+		return res.Tags[i].Name < res.Tags[j].Name
+	})
+
+	return
+}
+
+func (m *MemoryTagRepository) GetOne(ctx context.Context, req domain.TagGetOneRequest) (res domain.TagGetOneResponse, err error) {
+	defer m.DB.Read(ctx)()
+
+	if row, ok := m.DB.Table(tags).Rows[req.ID]; ok {
+		res.Tag = row.(domain.Tag)
+	}
+
+	return
+}
+
+func (m *MemoryTagRepository) Attach(ctx context.Context, req domain.TagAttachRequest) (res domain.TagAttachResponse, err error) {
+	defer m.DB.Write(ctx)()
+
+	// Tags the todo already carries are left as they are
+	ids := tagIDs(m.DB, req.TodoID)
+	for _, id := range req.TagIDs {
+		if !slice.Includes(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+
+	m.DB.Table(todoTags).Set(req.TodoID, ids)
+
+	return
+}
+
+func (m *MemoryTagRepository) Detach(ctx context.Context, req domain.TagDetachRequest) (res domain.TagDetachResponse, err error) {
+	defer m.DB.Write(ctx)()
+
+	ids := []int64{}
+	for _, id := range tagIDs(m.DB, req.TodoID) {
+		if !slice.Includes(req.TagIDs, id) {
+			ids = append(ids, id)
+		}
+	}
+
+	m.DB.Table(todoTags).Set(req.TodoID, ids)
+
+	return
+}
+
+// tagIDs are the IDs of the tags of a todo, a new slice the caller may append to
+func tagIDs(db *database.MemoryDB, todoID int64) []int64 {
+	ids, _ := db.Table(todoTags).Rows[todoID].([]int64)
+
+	return append([]int64{}, ids...)
+}
+
+// without is a copy of ids without id
+func without(ids []int64, id int64) (res []int64) {
+	res = []int64{}
+	for _, other := range ids {
+		if other != id {
+			res = append(res, other)
+		}
+	}
+
+	return
+}
+
+// withTags is the todo with the names of its tags in order, never nil
+func withTags(db *database.MemoryDB, todo domain.Todo) domain.Todo {
+	byID := db.Table(tags).Rows
+
+	todo.Tags = []string{}
+	for _, id := range tagIDs(db, todo.ID) {
+		if tag, ok := byID[id]; ok {
+			todo.Tags = append(todo.Tags, tag.(domain.Tag).Name)
+		}
+	}
+
+	sort.Strings(todo.Tags)
+
+	return todo
+}
+
+// tagged tells whether the todo carries any of the tags
+func tagged(todo domain.Todo, names []string) bool {
+	for _, name := range names {
+		if slice.Includes(todo.Tags, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// untag detaches the tags of the todos
+func untag(db *database.MemoryDB, todoIDs ...int64) {
+	links := db.Table(todoTags)
+
+	for _, id := range todoIDs {
+		links.Delete(id)
+	}
+}
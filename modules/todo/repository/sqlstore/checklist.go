@@ -0,0 +1,157 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fahmiaz411/devcode/config/database"
+	"github.com/fahmiaz411/devcode/helper/constant"
+	"github.com/fahmiaz411/devcode/modules/todo/domain"
+	"github.com/fahmiaz411/devcode/modules/todo/interfaces"
+)
+
+type SqlChecklistRepository struct {
+	Conn *sql.DB
+	Dialect database.Dialect
+}
+
+func NewSqlChecklistRepository(Conn *sql.DB, Dialect database.Dialect) interfaces.ChecklistRepository {
+	return &SqlChecklistRepository{
+		Conn: Conn,
+		Dialect: Dialect,
+	}
+}
+
+// conn is the transaction of the unit of work ctx runs in, if any
+func (m *SqlChecklistRepository) conn(ctx context.Context) database.Executor {
+	return m.Dialect.Conn(ctx, m.Conn)
+}
+
+func (m *SqlChecklistRepository) Create(ctx context.Context, req domain.ChecklistCreateRequest) (res domain.ChecklistCreateResponse, err error) {
+	now := time.Now().UTC()
+
+	res.ID, err = m.Dialect.Insert(ctx, m.conn(ctx), `
+		INSERT INTO todo_checklist_items (
+			todo_id,
+			title,
+			is_done,
+			position,
+			created_at,
+			updated_at
+		) VALUES (?, ?, ?, ?, ?, ?)
+	`, "item_id", req.TodoID, req.Title, req.IsDone, req.Position, now, now)
+	if err != nil {
+		return
+	}
+
+	res.TodoID = req.TodoID
+	res.Title = req.Title
+	res.IsDone = req.IsDone
+	res.Position = req.Position
+	res.CreatedAt = now
+	res.UpdatedAt = now
+
+	return
+}
+
+func (m *SqlChecklistRepository) Update(ctx context.Context, req domain.ChecklistUpdateRequest) (res domain.ChecklistUpdateResponse, err error) {
+	fields := []string{}
+	values := []any{}
+
+	if req.Title != constant.EmptyString {
+		fields = append(fields, "title = ?")
+		values = append(values, req.Title)
+	}
+
+	if req.IsDone != nil {
+		fields = append(fields, "is_done = ?")
+		values = append(values, *req.IsDone)
+	}
+
+	if req.Position != nil {
+		fields = append(fields, "position = ?")
+		values = append(values, *req.Position)
+	}
+
+	if len(fields) == constant.ZeroValue {
+		return
+	}
+
+	fields = append(fields, "updated_at = ?")
+	values = append(values, req.UpdatedAt, req.ID, req.TodoID)
+
+	var stmt *sql.Stmt
+	stmt, err = m.conn(ctx).PrepareContext(ctx, fmt.Sprintf(`
+		UPDATE todo_checklist_items SET %s WHERE item_id = ? AND todo_id = ?
+	`, strings.Join(fields, ", ")))
+	if err != nil {
+		return
+	}
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx, values...)
+
+	return
+}
+
+func (m *SqlChecklistRepository) Delete(ctx context.Context, req domain.ChecklistDeleteRequest) (res domain.ChecklistDeleteResponse, err error) {
+	var stmt *sql.Stmt
+	stmt, err = m.conn(ctx).PrepareContext(ctx, `
+		DELETE FROM todo_checklist_items WHERE item_id = ? AND todo_id = ?
+	`)
+	if err != nil {
+		return
+	}
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx, req.ID, req.TodoID)
+
+	return
+}
+
+func (m *SqlChecklistRepository) Check(ctx context.Context, req domain.ChecklistCheckRequest) (res domain.ChecklistCheckResponse, err error) {
+	var stmt *sql.Stmt
+	stmt, err = m.conn(ctx).PrepareContext(ctx, `
+		UPDATE todo_checklist_items SET is_done = TRUE, updated_at = ? WHERE todo_id = ? AND is_done = FALSE
+	`)
+	if err != nil {
+		return
+	}
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx, req.UpdatedAt, req.TodoID)
+
+	return
+}
+
+func (m *SqlChecklistRepository) GetAll(ctx context.Context, req domain.ChecklistGetAllRequest) (res domain.ChecklistGetAllResponse, err error) {
+	res.Items = []domain.ChecklistItem{}
+
+	var rows *sql.Rows
+	rows, err = m.conn(ctx).QueryContext(ctx, `
+		SELECT item_id, todo_id, title, is_done, position, created_at, updated_at
+		FROM todo_checklist_items
+		WHERE todo_id = ?
+		ORDER BY position, item_id
+	`, req.TodoID)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item domain.ChecklistItem
+		if err = rows.Scan(&item.ID, &item.TodoID, &item.Title, &item.IsDone, &item.Position, &item.CreatedAt, &item.UpdatedAt); err != nil {
+			return
+		}
+
+		res.Items = append(res.Items, item)
+	}
+
+	err = rows.Err()
+
+	return
+}
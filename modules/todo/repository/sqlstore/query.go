@@ -0,0 +1,235 @@
+package sqlstore
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fahmiaz411/devcode/config/database"
+	"github.com/fahmiaz411/devcode/helper/constant"
+	"github.com/fahmiaz411/devcode/modules/todo/domain"
+)
+
+func where(conditions []string) string {
+	if len(conditions) == 0 {
+		return ""
+	}
+
+	return "WHERE " + strings.Join(conditions, " AND ")
+}
+
+// trash keeps either the live or the deleted rows
+func trash(trashed bool) string {
+	if trashed {
+		return "deleted_at IS NOT NULL"
+	}
+
+	return "deleted_at IS NULL"
+}
+
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+// completed is the completed_at of a todo given its new is_active and the time of the write,
+// a completion is dated once and reopening the todo clears it
+const completed = "CASE WHEN ? THEN NULL ELSE COALESCE(completed_at, ?) END"
+
+// series are the priority and the occurrence of a new todo, those of a series or the defaults
+func series(req domain.TodoCreateRequest) (priority string, occurrence int64) {
+	priority, occurrence = req.Priority, req.Occurrence
+
+	if priority == constant.EmptyString {
+		priority = domain.PriorityDefault
+	}
+
+	if occurrence == int64(constant.ZeroValue) {
+		occurrence = domain.OccurrenceDefault
+	}
+
+	return
+}
+
+// rrule is the stored recurrence, NULL for a todo that does not recur
+func rrule(recurrence *domain.Recurrence) any {
+	if recurrence == nil {
+		return nil
+	}
+
+	return recurrence.String()
+}
+
+// recurrenceOf reads a stored recurrence back
+func recurrenceOf(rrule sql.NullString) (recurrence *domain.Recurrence, err error) {
+	if !rrule.Valid {
+		return
+	}
+
+	var parsed domain.Recurrence
+	if parsed, err = domain.ParseRecurrence(rrule.String); err != nil {
+		return
+	}
+
+	return &parsed, nil
+}
+
+// claimable are the live, active todos whose reminder is due and neither sent, leased nor given up,
+// it takes the time of the claim twice then domain.RemindAttemptsMax
+const claimable = `deleted_at IS NULL AND is_active = TRUE AND remind_at <= ? AND reminded_at IS NULL AND
+		(remind_claimed_until IS NULL OR remind_claimed_until <= ?) AND remind_attempts < ?`
+
+// filter is the WHERE clause of GetAll but for the trash condition
+func filter(dialect database.Dialect, req domain.TodoGetAllRequest) (conditions []string, values []any) {
+	if len(req.IDs) != constant.ZeroValue {
+		conditions = append(conditions, fmt.Sprintf("todo_id IN (%s)", placeholders(len(req.IDs))))
+		for _, id := range req.IDs {
+			values = append(values, id)
+		}
+	}
+
+	if req.ActivityGroupID != int64(constant.ZeroValue) {
+		conditions = append(conditions, "activity_group_id = ?")
+		values = append(values, req.ActivityGroupID)
+	}
+
+	if len(req.ActivityGroupIDs) != constant.ZeroValue {
+		conditions = append(conditions, fmt.Sprintf("activity_group_id IN (%s)", placeholders(len(req.ActivityGroupIDs))))
+		for _, id := range req.ActivityGroupIDs {
+			values = append(values, id)
+		}
+	}
+
+	if req.IsActive != nil {
+		// A todo created without is_active is read back as inactive
+		conditions = append(conditions, "COALESCE(is_active, FALSE) = ?")
+		values = append(values, *req.IsActive)
+	}
+
+	if len(req.Priorities) != constant.ZeroValue {
+		conditions = append(conditions, fmt.Sprintf("priority IN (%s)", placeholders(len(req.Priorities))))
+		for _, priority := range req.Priorities {
+			values = append(values, priority)
+		}
+	}
+
+	if req.Title != constant.EmptyString {
+		conditions = append(conditions, dialect.Like("title"))
+		values = append(values, database.Contains(req.Title))
+	}
+
+	// Any of the tags
+	if len(req.Tags) != constant.ZeroValue {
+		conditions = append(conditions, fmt.Sprintf(`todo_id IN (
+			SELECT todo_tags.todo_id FROM todo_tags JOIN tags ON tags.tag_id = todo_tags.tag_id WHERE tags.name IN (%s)
+		)`, placeholders(len(req.Tags))))
+		for _, tag := range req.Tags {
+			values = append(values, tag)
+		}
+	}
+
+	ranges := []struct {
+		condition string
+		value     *time.Time
+	}{
+		{"created_at >= ?", req.CreatedFrom},
+		{"created_at < ?", req.CreatedTo},
+		{"updated_at >= ?", req.UpdatedFrom},
+		{"updated_at < ?", req.UpdatedTo},
+		{"due_at >= ?", req.DueFrom},
+		{"due_at < ?", req.DueTo},
+	}
+	for _, r := range ranges {
+		if r.value != nil {
+			conditions = append(conditions, r.condition)
+			values = append(values, *r.value)
+		}
+	}
+
+	return
+}
+
+// bulkSet is the SET clause giving each todo of a batch its own values, a CASE on todo_id per column
+func bulkSet(req domain.TodoBulkUpdateRequest) (fields []string, values []any) {
+	columns := []struct {
+		name  string
+		value func(todo domain.TodoUpdateRequest) (value any, ok bool)
+	}{
+		{"activity_group_id", func(todo domain.TodoUpdateRequest) (any, bool) {
+			return todo.ActivityGroupID, todo.ActivityGroupID != int64(constant.ZeroValue)
+		}},
+		{"title", func(todo domain.TodoUpdateRequest) (any, bool) {
+			return todo.Title, todo.Title != constant.EmptyString
+		}},
+		{"is_active", func(todo domain.TodoUpdateRequest) (any, bool) {
+			if todo.IsActive == nil {
+				return nil, false
+			}
+			return *todo.IsActive, true
+		}},
+		{"priority", func(todo domain.TodoUpdateRequest) (any, bool) {
+			return todo.Priority, todo.Priority != constant.EmptyString
+		}},
+		{"position", func(todo domain.TodoUpdateRequest) (any, bool) {
+			if todo.Position == nil {
+				return nil, false
+			}
+			return *todo.Position, true
+		}},
+		{"due_at", func(todo domain.TodoUpdateRequest) (any, bool) {
+			return todo.DueAt.Time, todo.DueAt.Set
+		}},
+		{"remind_at", func(todo domain.TodoUpdateRequest) (any, bool) {
+			return todo.RemindAt.Time, todo.RemindAt.Set
+		}},
+		{"reminded_at", func(todo domain.TodoUpdateRequest) (any, bool) {
+			return nil, todo.RemindAt.Set
+		}},
+		{"remind_attempts", func(todo domain.TodoUpdateRequest) (any, bool) {
+			return constant.ZeroValue, todo.RemindAt.Set
+		}},
+		{"recurrence", func(todo domain.TodoUpdateRequest) (any, bool) {
+			return rrule(todo.Recurrence.Recurrence), todo.Recurrence.Set
+		}},
+		{"next_id", func(todo domain.TodoUpdateRequest) (any, bool) {
+			if todo.NextID == nil {
+				return nil, false
+			}
+			return *todo.NextID, true
+		}},
+	}
+
+	for _, column := range columns {
+		cases := []string{}
+		for _, todo := range req.Todos {
+			if value, ok := column.value(todo.TodoUpdateRequest); ok {
+				cases = append(cases, "WHEN ? THEN ?")
+				values = append(values, todo.ID, value)
+			}
+		}
+
+		if len(cases) != constant.ZeroValue {
+			fields = append(fields, fmt.Sprintf("%s = CASE todo_id %s ELSE %s END", column.name, strings.Join(cases, " "), column.name))
+		}
+	}
+
+	// Completed At, follows is_active
+	cases := []string{}
+	for _, todo := range req.Todos {
+		if todo.IsActive != nil {
+			cases = append(cases, "WHEN ? THEN "+completed)
+			values = append(values, todo.ID, *todo.IsActive, req.UpdatedAt)
+		}
+	}
+
+	if len(cases) != constant.ZeroValue {
+		fields = append(fields, fmt.Sprintf("completed_at = CASE todo_id %s ELSE completed_at END", strings.Join(cases, " ")))
+	}
+
+	return
+}
+
+// versioned is the WHERE clause of a batch write, each todo at its expected version
+func versioned(n int) string {
+	return strings.TrimSuffix(strings.Repeat("(todo_id = ? AND version = ?) OR ", n), " OR ")
+}
@@ -0,0 +1,40 @@
+package sqlstore
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fahmiaz411/devcode/config/database"
+	"github.com/fahmiaz411/devcode/modules/todo/domain"
+)
+
+var sortColumns = map[string]string{
+	domain.SortPosition:  "position",
+	domain.SortID:        "todo_id",
+	domain.SortPriority:  priorityRank(),
+	domain.SortCreatedAt: "created_at",
+	domain.SortUpdatedAt: "updated_at",
+}
+
+// sortColumn is the column a sort orders by, due_at being domain.DueNever for the todos without one
+// and titles compared byte by byte
+func sortColumn(dialect database.Dialect, sort string) string {
+	switch sort {
+	case domain.SortDueAt:
+		return fmt.Sprintf("COALESCE(due_at, %s)", dialect.Never())
+	case domain.SortTitle:
+		return dialect.Binary("title")
+	}
+
+	return sortColumns[sort]
+}
+
+// priorityRank is domain.PriorityRank in SQL
+func priorityRank() string {
+	cases := []string{}
+	for _, priority := range domain.PriorityAllList {
+		cases = append(cases, fmt.Sprintf("WHEN '%s' THEN %d", priority, domain.PriorityRank(priority)))
+	}
+
+	return fmt.Sprintf("(CASE priority %s ELSE 0 END)", strings.Join(cases, " "))
+}
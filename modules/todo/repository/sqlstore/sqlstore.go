@@ -0,0 +1,1087 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fahmiaz411/devcode/config/database"
+	"github.com/fahmiaz411/devcode/helper/constant"
+	"github.com/fahmiaz411/devcode/helper/pagination"
+	"github.com/fahmiaz411/devcode/modules/todo/domain"
+	"github.com/fahmiaz411/devcode/modules/todo/interfaces"
+)
+
+type SqlRepository struct {
+	Conn *sql.DB
+	Dialect database.Dialect
+}
+
+func NewSqlRepository(Conn *sql.DB, Dialect database.Dialect) interfaces.TodoRepository {
+	return &SqlRepository{
+		Conn: Conn,
+		Dialect: Dialect,
+	}
+}
+
+// conn is the transaction of the unit of work ctx runs in, if any
+func (m *SqlRepository) conn(ctx context.Context) database.Executor {
+	return m.Dialect.Conn(ctx, m.Conn)
+}
+
+func (m *SqlRepository) Create(ctx context.Context, req domain.TodoCreateRequest) (res domain.TodoCreateResponse, err error) {
+	res.Todo, err = m.insert(ctx, req, time.Now().UTC())
+
+	return
+}
+
+// insert writes a new todo at now and returns it as stored
+func (m *SqlRepository) insert(ctx context.Context, req domain.TodoCreateRequest, now time.Time) (todo domain.Todo, err error) {
+	// Stored explicitly, a NULL is_active would be read back as false
+	isActive := domain.IsActiveDefault
+	if req.IsActive != nil {
+		isActive = *req.IsActive
+	}
+
+	priority, occurrence := series(req)
+
+	values := []any{
+		req.Title,
+		req.ActivityGroupID,
+		isActive,
+		now,
+		now,
+		domain.CompletedAt(isActive, now),
+		req.Position,
+		req.DueAt,
+		req.RemindAt,
+		priority,
+		rrule(req.Recurrence),
+		occurrence,
+	}
+
+	todo.ID, err = m.Dialect.Insert(ctx, m.conn(ctx), `
+		INSERT INTO todos (
+			title,
+			activity_group_id,
+			is_active,
+			created_at,
+			updated_at,
+			completed_at,
+			position,
+			due_at,
+			remind_at,
+			priority,
+			recurrence,
+			occurrence
+		) VALUES (
+			?,
+			?,
+			?,
+			?,
+			?,
+			?,
+			?,
+			?,
+			?,
+			?,
+			?,
+			?
+		)
+	`, "todo_id", values...)
+	if err != nil {
+		return
+	}
+
+	todo.Title = req.Title
+	todo.ActivityGroupID = req.ActivityGroupID
+	todo.Priority = priority
+	todo.CreatedAt = now
+	todo.UpdatedAt = now
+	todo.Version = domain.VersionDefault
+	todo.IsActive = isActive
+	todo.CompletedAt = domain.CompletedAt(isActive, now)
+	todo.Position = req.Position
+	todo.DueAt = req.DueAt
+	todo.RemindAt = req.RemindAt
+	todo.Recurrence = req.Recurrence
+	todo.Occurrence = occurrence
+	todo.Tags = []string{}
+	
+	return
+}
+
+func (m *SqlRepository) Update(ctx context.Context, req domain.TodoUpdateRequest) (res domain.TodoUpdateResponse, err error) {
+	fields := []string{}
+	values := []any{}
+
+	if req.ActivityGroupID != int64(constant.ZeroValue) {
+		fields = append(fields, "activity_group_id")
+		values = append(values, req.ActivityGroupID)
+	}
+
+	if req.Title != constant.EmptyString {
+		fields = append(fields, "title")
+		values = append(values, req.Title)
+	}
+
+	if req.IsActive != nil {
+		fields = append(fields, "is_active")
+		values = append(values, *req.IsActive)
+	}
+
+	if req.Priority != constant.EmptyString {
+		fields = append(fields, "priority")
+		values = append(values, req.Priority)
+	}
+
+	if req.Position != nil {
+		fields = append(fields, "position")
+		values = append(values, *req.Position)
+	}
+
+	if req.DueAt.Set {
+		fields = append(fields, "due_at")
+		values = append(values, req.DueAt.Time)
+	}
+
+	// A new reminder time is reminded of again, with every attempt ahead of it
+	if req.RemindAt.Set {
+		fields = append(fields, "remind_at", "reminded_at", "remind_attempts")
+		values = append(values, req.RemindAt.Time, nil, constant.ZeroValue)
+	}
+
+	if req.Recurrence.Set {
+		fields = append(fields, "recurrence")
+		values = append(values, rrule(req.Recurrence.Recurrence))
+	}
+
+	if req.NextID != nil {
+		fields = append(fields, "next_id")
+		values = append(values, *req.NextID)
+	}
+
+	if len(fields) == constant.ZeroValue {
+		return
+	}
+
+	// Updated At
+	fields = append(fields, "updated_at")
+	values = append(values, req.UpdatedAt)
+
+	for key, field := range fields {
+		fields[key] = field + " = ?"
+	}
+
+	// Completed At, follows is_active
+	if req.IsActive != nil {
+		fields = append(fields, "completed_at = "+completed)
+		values = append(values, *req.IsActive, req.UpdatedAt)
+	}
+
+	fields = append(fields, "version = version + 1")
+
+	// Id and expected version
+	values = append(values, req.ID, req.Version)
+
+	var stmt *sql.Stmt
+	stmt, err = m.conn(ctx).PrepareContext(ctx, fmt.Sprintf(`
+		UPDATE todos 
+		SET
+			%s			
+		WHERE todo_id = ? AND version = ?
+	`, strings.Join(fields, ", ")))
+	if err != nil {
+		return
+	}
+	defer stmt.Close()
+
+	var result sql.Result
+	result, err = stmt.ExecContext(ctx, values...)
+	if err != nil {
+		return
+	}
+
+	if affected, _ := result.RowsAffected(); affected == int64(constant.ZeroValue) {
+		err = domain.ErrVersionConflict
+	}
+	
+	return
+}
+
+func (m *SqlRepository) Delete(ctx context.Context, req domain.TodoDeleteRequest) (res domain.TodoDeleteResponse, err error) {
+	var stmt *sql.Stmt
+	stmt, err = m.conn(ctx).PrepareContext(ctx, `
+		UPDATE todos SET deleted_at = ?, updated_at = ?, version = version + 1 WHERE todo_id = ? AND version = ?
+	`)
+	if err != nil {
+		return
+	}
+	defer stmt.Close()
+
+	now := time.Now().UTC()
+
+	var result sql.Result
+	result, err = stmt.ExecContext(ctx, now, now, req.ID, req.Version)
+	if err != nil {
+		return
+	}
+
+	if affected, _ := result.RowsAffected(); affected == int64(constant.ZeroValue) {
+		err = domain.ErrVersionConflict
+	}
+	
+	return
+}
+
+func (m *SqlRepository) Restore(ctx context.Context, req domain.TodoRestoreRequest) (res domain.TodoRestoreResponse, err error) {
+	var stmt *sql.Stmt
+	stmt, err = m.conn(ctx).PrepareContext(ctx, `
+		UPDATE todos SET deleted_at = NULL, updated_at = ?, version = version + 1 WHERE todo_id = ? AND version = ? AND deleted_at IS NOT NULL
+	`)
+	if err != nil {
+		return
+	}
+	defer stmt.Close()
+
+	var result sql.Result
+	result, err = stmt.ExecContext(ctx, req.UpdatedAt, req.ID, req.Version)
+	if err != nil {
+		return
+	}
+
+	if affected, _ := result.RowsAffected(); affected == int64(constant.ZeroValue) {
+		err = domain.ErrVersionConflict
+	}
+	
+	return
+}
+
+func (m *SqlRepository) Purge(ctx context.Context, req domain.TodoPurgeRequest) (res domain.TodoPurgeResponse, err error) {
+	// The checklist and the tags go along
+	if _, err = m.conn(ctx).ExecContext(ctx, `
+		DELETE FROM todo_checklist_items WHERE todo_id = ?
+	`, req.ID); err != nil {
+		return
+	}
+
+	if _, err = m.conn(ctx).ExecContext(ctx, `
+		DELETE FROM todo_tags WHERE todo_id = ?
+	`, req.ID); err != nil {
+		return
+	}
+
+	var stmt *sql.Stmt
+	stmt, err = m.conn(ctx).PrepareContext(ctx, `
+		DELETE FROM todos WHERE todo_id = ?
+	`)
+	if err != nil {
+		return
+	}
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx, req.ID)
+	
+	return
+}
+
+func (m *SqlRepository) BulkCreate(ctx context.Context, req domain.TodoBulkCreateRequest) (res domain.TodoBulkCreateResponse, err error) {
+	now := time.Now().UTC()
+
+	// A row at a time, in the unit of work of the batch: the IDs of a multi-row insert are not known on every driver
+	res.Todos = make([]domain.Todo, len(req.Todos))
+	for i, todo := range req.Todos {
+		if res.Todos[i], err = m.insert(ctx, todo, now); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+func (m *SqlRepository) BulkUpdate(ctx context.Context, req domain.TodoBulkUpdateRequest) (res domain.TodoBulkUpdateResponse, err error) {
+	fields, values := bulkSet(req)
+
+	// Updated At
+	fields = append(fields, "updated_at = ?", "version = version + 1")
+	values = append(values, req.UpdatedAt)
+
+	// Ids and expected versions
+	for _, todo := range req.Todos {
+		values = append(values, todo.ID, todo.Version)
+	}
+
+	var stmt *sql.Stmt
+	stmt, err = m.conn(ctx).PrepareContext(ctx, fmt.Sprintf(`
+		UPDATE todos 
+		SET
+			%s
+		WHERE %s
+	`, strings.Join(fields, ", "), versioned(len(req.Todos))))
+	if err != nil {
+		return
+	}
+	defer stmt.Close()
+
+	var result sql.Result
+	result, err = stmt.ExecContext(ctx, values...)
+	if err != nil {
+		return
+	}
+
+	if affected, _ := result.RowsAffected(); affected != int64(len(req.Todos)) {
+		err = domain.ErrVersionConflict
+	}
+	
+	return
+}
+
+func (m *SqlRepository) BulkDelete(ctx context.Context, req domain.TodoBulkDeleteRequest) (res domain.TodoBulkDeleteResponse, err error) {
+	now := time.Now().UTC()
+
+	values := []any{now, now}
+	for _, todo := range req.Todos {
+		values = append(values, todo.ID, todo.Version)
+	}
+
+	var stmt *sql.Stmt
+	stmt, err = m.conn(ctx).PrepareContext(ctx, fmt.Sprintf(`
+		UPDATE todos SET deleted_at = ?, updated_at = ?, version = version + 1 WHERE %s
+	`, versioned(len(req.Todos))))
+	if err != nil {
+		return
+	}
+	defer stmt.Close()
+
+	var result sql.Result
+	result, err = stmt.ExecContext(ctx, values...)
+	if err != nil {
+		return
+	}
+
+	if affected, _ := result.RowsAffected(); affected != int64(len(req.Todos)) {
+		err = domain.ErrVersionConflict
+	}
+	
+	return
+}
+
+func (m *SqlRepository) CountByActivity(ctx context.Context, req domain.TodoCountByActivityRequest) (res domain.TodoCountByActivityResponse, err error) {
+	conditions := []string{"activity_group_id = ?"}
+	if !req.WithDeleted {
+		conditions = append(conditions, trash(false))
+	}
+
+	err = m.conn(ctx).QueryRowContext(ctx, fmt.Sprintf(`
+		SELECT COUNT(*) FROM todos %s
+	`, where(conditions)), req.ActivityGroupID).Scan(&res.Count)
+	
+	return
+}
+
+func (m *SqlRepository) DeleteByActivity(ctx context.Context, req domain.TodoDeleteByActivityRequest) (res domain.TodoDeleteByActivityResponse, err error) {
+	var stmt *sql.Stmt
+	stmt, err = m.conn(ctx).PrepareContext(ctx, `
+		UPDATE todos SET deleted_at = ?, updated_at = ?, version = version + 1 WHERE activity_group_id = ? AND deleted_at IS NULL
+	`)
+	if err != nil {
+		return
+	}
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx, req.DeletedAt, req.DeletedAt, req.ActivityGroupID)
+	
+	return
+}
+
+func (m *SqlRepository) RestoreByActivity(ctx context.Context, req domain.TodoRestoreByActivityRequest) (res domain.TodoRestoreByActivityResponse, err error) {
+	var stmt *sql.Stmt
+	stmt, err = m.conn(ctx).PrepareContext(ctx, `
+		UPDATE todos SET deleted_at = NULL, updated_at = ?, version = version + 1 WHERE activity_group_id = ? AND deleted_at = ?
+	`)
+	if err != nil {
+		return
+	}
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx, req.UpdatedAt, req.ActivityGroupID, req.DeletedAt)
+	
+	return
+}
+
+func (m *SqlRepository) PurgeByActivity(ctx context.Context, req domain.TodoPurgeByActivityRequest) (res domain.TodoPurgeByActivityResponse, err error) {
+	// The checklists and the tags go along
+	if _, err = m.conn(ctx).ExecContext(ctx, `
+		DELETE FROM todo_checklist_items WHERE todo_id IN (SELECT todo_id FROM todos WHERE activity_group_id = ?)
+	`, req.ActivityGroupID); err != nil {
+		return
+	}
+
+	if _, err = m.conn(ctx).ExecContext(ctx, `
+		DELETE FROM todo_tags WHERE todo_id IN (SELECT todo_id FROM todos WHERE activity_group_id = ?)
+	`, req.ActivityGroupID); err != nil {
+		return
+	}
+
+	var stmt *sql.Stmt
+	stmt, err = m.conn(ctx).PrepareContext(ctx, `
+		DELETE FROM todos WHERE activity_group_id = ?
+	`)
+	if err != nil {
+		return
+	}
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx, req.ActivityGroupID)
+	
+	return
+}
+
+func (m *SqlRepository) Renumber(ctx context.Context, req domain.TodoRenumberRequest) (res domain.TodoRenumberResponse, err error) {
+	if len(req.Todos) == constant.ZeroValue {
+		return
+	}
+
+	cases := make([]string, len(req.Todos))
+	ids := make([]string, len(req.Todos))
+	values := []any{req.UpdatedAt}
+	for i, todo := range req.Todos {
+		cases[i] = "WHEN ? THEN ?"
+		values = append(values, todo.ID, todo.Position)
+	}
+
+	for i, todo := range req.Todos {
+		ids[i] = "?"
+		values = append(values, todo.ID)
+	}
+
+	var stmt *sql.Stmt
+	stmt, err = m.conn(ctx).PrepareContext(ctx, fmt.Sprintf(`
+		UPDATE todos SET updated_at = ?, version = version + 1, position = CASE todo_id %s END WHERE todo_id IN (%s)
+	`, strings.Join(cases, " "), strings.Join(ids, ", ")))
+	if err != nil {
+		return
+	}
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx, values...)
+
+	return
+}
+
+func (m *SqlRepository) Touch(ctx context.Context, req domain.TodoTouchRequest) (res domain.TodoTouchResponse, err error) {
+	var stmt *sql.Stmt
+	stmt, err = m.conn(ctx).PrepareContext(ctx, `
+		UPDATE todos SET updated_at = ?, version = version + 1 WHERE todo_id = ? AND version = ?
+	`)
+	if err != nil {
+		return
+	}
+	defer stmt.Close()
+
+	var result sql.Result
+	result, err = stmt.ExecContext(ctx, req.UpdatedAt, req.ID, req.Version)
+	if err != nil {
+		return
+	}
+
+	if affected, _ := result.RowsAffected(); affected == int64(constant.ZeroValue) {
+		err = domain.ErrVersionConflict
+	}
+
+	return
+}
+
+func (m *SqlRepository) GroupCount(ctx context.Context, req domain.TodoGroupCountRequest) (res domain.TodoGroupCountResponse, err error) {
+	res.Groups = []domain.TodoGroupCount{}
+
+	if len(req.ActivityGroupIDs) == constant.ZeroValue {
+		return
+	}
+
+	values := []any{}
+	for _, id := range req.ActivityGroupIDs {
+		values = append(values, id)
+	}
+
+	res.Groups, err = m.groupCount(ctx, []string{
+		fmt.Sprintf("activity_group_id IN (%s)", placeholders(len(req.ActivityGroupIDs))),
+		trash(false),
+	}, values)
+
+	return
+}
+
+func (m *SqlRepository) Aggregate(ctx context.Context, req domain.TodoAggregateRequest) (res domain.TodoAggregateResponse, err error) {
+	conditions := []string{trash(false)}
+	values := []any{}
+
+	if req.ActivityGroupID != int64(constant.ZeroValue) {
+		conditions = append(conditions, "activity_group_id = ?")
+		values = append(values, req.ActivityGroupID)
+	}
+
+	if res.Groups, err = m.groupCount(ctx, conditions, values); err != nil {
+		return
+	}
+
+	if res.Created, err = m.perDay(ctx, "created_at", conditions, values, req.From, req.To); err != nil {
+		return
+	}
+
+	if res.Completed, err = m.perDay(ctx, "completed_at", conditions, values, req.From, req.To); err != nil {
+		return
+	}
+
+	// Time to completion, of the todos completed in the range
+	var avg sql.NullFloat64
+	if err = m.conn(ctx).QueryRowContext(ctx, fmt.Sprintf(`
+		SELECT AVG(%s) FROM todos %s
+	`, m.Dialect.Seconds("created_at", "completed_at"), where(append([]string{"completed_at >= ?", "completed_at < ?"}, conditions...))), append([]any{req.From, req.To}, values...)...).Scan(&avg); err != nil {
+		return
+	}
+
+	if avg.Valid {
+		res.AvgCompletionSeconds = &avg.Float64
+	}
+
+	return
+}
+
+// groupCount counts the todos matching conditions by activity group, priority and state
+func (m *SqlRepository) groupCount(ctx context.Context, conditions []string, values []any) (groups []domain.TodoGroupCount, err error) {
+	groups = []domain.TodoGroupCount{}
+
+	var rows *sql.Rows
+	rows, err = m.conn(ctx).QueryContext(ctx, fmt.Sprintf(`
+		SELECT
+			activity_group_id,
+			priority,
+			COALESCE(is_active, FALSE),
+			COUNT(*)
+		FROM todos
+		%s
+		GROUP BY activity_group_id, priority, COALESCE(is_active, FALSE)
+	`, where(conditions)), values...)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var group domain.TodoGroupCount
+		if err = rows.Scan(&group.ActivityGroupID, &group.Priority, &group.IsActive, &group.Count); err != nil {
+			return
+		}
+
+		groups = append(groups, group)
+	}
+
+	err = rows.Err()
+	
+	return
+}
+
+// perDay counts the todos matching conditions by the UTC day of column, from included and to excluded
+func (m *SqlRepository) perDay(ctx context.Context, column string, conditions []string, values []any, from, to time.Time) (days map[string]int64, err error) {
+	days = map[string]int64{}
+
+	conditions = append([]string{column + " >= ?", column + " < ?"}, conditions...)
+	values = append([]any{from, to}, values...)
+
+	var rows *sql.Rows
+	rows, err = m.conn(ctx).QueryContext(ctx, fmt.Sprintf(`
+		SELECT %[1]s, COUNT(*) FROM todos %[2]s GROUP BY %[1]s
+	`, m.Dialect.Day(column), where(conditions)), values...)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			date string
+			count int64
+		)
+		if err = rows.Scan(&date, &count); err != nil {
+			return
+		}
+
+		days[date] = count
+	}
+
+	err = rows.Err()
+	
+	return
+}
+
+func (m *SqlRepository) LastPosition(ctx context.Context, req domain.TodoLastPositionRequest) (res domain.TodoLastPositionResponse, err error) {
+	// The trash counts, a restored todo keeps a place of its own
+	err = m.conn(ctx).QueryRowContext(ctx, `
+		SELECT COALESCE(MAX(position), 0) FROM todos WHERE activity_group_id = ?
+	`, req.ActivityGroupID).Scan(&res.Position)
+
+	return
+}
+
+func (m *SqlRepository) Neighbour(ctx context.Context, req domain.TodoNeighbourRequest) (res domain.TodoNeighbourResponse, err error) {
+	// Todos sharing a position are ordered by ID
+	compare, order := "<", "DESC"
+	if req.After {
+		compare, order = ">", "ASC"
+	}
+
+	var rows *sql.Rows
+	rows, err = m.conn(ctx).QueryContext(ctx, fmt.Sprintf(`
+		SELECT todo_id, position
+		FROM todos
+		WHERE activity_group_id = ? AND %[1]s AND todo_id <> ? AND (position %[2]s ? OR (position = ? AND todo_id %[2]s ?))
+		ORDER BY position %[3]s, todo_id %[3]s
+		LIMIT 1
+	`, trash(false), compare, order), req.ActivityGroupID, req.ExcludeID, req.Position, req.Position, req.ID)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		err = rows.Scan(&res.ID, &res.Position)
+	}
+
+	return
+}
+
+// ClaimReminders takes the due reminders one todo at a time, a todo is claimed by the dispatcher whose update matched it
+func (m *SqlRepository) ClaimReminders(ctx context.Context, req domain.TodoClaimRemindersRequest) (res domain.TodoClaimRemindersResponse, err error) {
+	var rows *sql.Rows
+	rows, err = m.conn(ctx).QueryContext(ctx, fmt.Sprintf(`
+		SELECT todo_id
+		FROM todos
+		WHERE %s
+		ORDER BY remind_at, todo_id
+		LIMIT ?
+	`, claimable), req.Now, req.Now, domain.RemindAttemptsMax, req.Limit)
+	if err != nil {
+		return
+	}
+
+	candidates := []int64{}
+	for rows.Next() {
+		var id int64
+		if err = rows.Scan(&id); err != nil {
+			rows.Close()
+			return
+		}
+		candidates = append(candidates, id)
+	}
+	rows.Close()
+
+	if err = rows.Err(); err != nil {
+		return
+	}
+
+	var stmt *sql.Stmt
+	stmt, err = m.conn(ctx).PrepareContext(ctx, fmt.Sprintf(`
+		UPDATE todos SET remind_claim = ?, remind_claimed_until = ?, remind_attempts = remind_attempts + 1 WHERE todo_id = ? AND %s
+	`, claimable))
+	if err != nil {
+		return
+	}
+	defer stmt.Close()
+
+	claimed := []int64{}
+	for _, id := range candidates {
+		var result sql.Result
+		result, err = stmt.ExecContext(ctx, req.Claim, req.Until, id, req.Now, req.Now, domain.RemindAttemptsMax)
+		if err != nil {
+			return
+		}
+
+		if affected, _ := result.RowsAffected(); affected != int64(constant.ZeroValue) {
+			claimed = append(claimed, id)
+		}
+	}
+
+	if len(claimed) == constant.ZeroValue {
+		return
+	}
+
+	var todos domain.TodoGetAllResponse
+	todos, err = m.GetAll(ctx, domain.TodoGetAllRequest{
+		IDs: claimed,
+		Request: pagination.Request{
+			Sort: domain.SortID,
+			Order: pagination.OrderAsc,
+		},
+	})
+	res.Todos = todos.Todos
+
+	return
+}
+
+func (m *SqlRepository) RenewReminder(ctx context.Context, req domain.TodoRenewReminderRequest) (res domain.TodoRenewReminderResponse, err error) {
+	var result sql.Result
+	result, err = m.conn(ctx).ExecContext(ctx, `
+		UPDATE todos SET remind_claimed_until = ? WHERE todo_id = ? AND remind_claim = ?
+	`, req.Until, req.ID, req.Claim)
+	if err != nil {
+		return
+	}
+
+	if affected, _ := result.RowsAffected(); affected != int64(constant.ZeroValue) {
+		res.Renewed = true
+		return
+	}
+
+	// MySQL counts the rows changed, a lease renewed to the second it already ends at is still held
+	var held int64
+	err = m.conn(ctx).QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM todos WHERE todo_id = ? AND remind_claim = ?
+	`, req.ID, req.Claim).Scan(&held)
+	res.Renewed = held != int64(constant.ZeroValue)
+
+	return
+}
+
+func (m *SqlRepository) MarkReminded(ctx context.Context, req domain.TodoMarkRemindedRequest) (res domain.TodoMarkRemindedResponse, err error) {
+	var result sql.Result
+	result, err = m.conn(ctx).ExecContext(ctx, `
+		UPDATE todos
+		SET reminded_at = ?, updated_at = ?, remind_claim = NULL, remind_claimed_until = NULL, version = version + 1
+		WHERE todo_id = ? AND remind_claim = ?
+	`, req.RemindedAt, req.RemindedAt, req.ID, req.Claim)
+	if err != nil {
+		return
+	}
+
+	affected, _ := result.RowsAffected()
+	res.Marked = affected != int64(constant.ZeroValue)
+
+	return
+}
+
+func (m *SqlRepository) GetAll(ctx context.Context, req domain.TodoGetAllRequest) (res domain.TodoGetAllResponse, err error) {
+	res.Todos = []domain.Todo{}
+	res.Paging.Limit = req.Limit
+	res.Paging.Offset = req.Offset
+
+	conditions, values := filter(m.Dialect, req)
+	conditions = append(conditions, trash(req.Trashed))
+
+	// Total
+	if err = m.conn(ctx).QueryRowContext(ctx, fmt.Sprintf(`
+		SELECT COUNT(*) FROM todos %s
+	`, where(conditions)), values...).Scan(&res.Paging.Total); err != nil {
+		return
+	}
+
+	column := sortColumn(m.Dialect, req.Sort)
+
+	// Keyset
+	if req.After != nil {
+		var after any
+		after, err = domain.ParseSortValue(req.Sort, req.After.Value)
+		if err != nil {
+			return
+		}
+
+		conditions = append(conditions, req.Keyset(column, "todo_id"))
+		values = append(values, after, after, req.After.ID)
+	}
+
+	var queryLimit string
+	if req.Limit != constant.ZeroValue {
+		// One more row tells whether there is a next page
+		queryLimit = "LIMIT ? OFFSET ?"
+		values = append(values, req.Limit+1, req.Offset)
+	}
+
+	var stmt *sql.Stmt
+	stmt, err = m.conn(ctx).PrepareContext(ctx, fmt.Sprintf(`
+		SELECT 
+			todo_id,
+			activity_group_id,
+			title,
+			is_active,
+			priority,
+			position,
+			created_at,
+			updated_at,
+			deleted_at,
+			completed_at,
+			due_at,
+			remind_at,
+			reminded_at,
+			remind_attempts,
+			recurrence,
+			occurrence,
+			next_id,
+			(SELECT COUNT(*) FROM todo_checklist_items c WHERE c.todo_id = todos.todo_id),
+			(SELECT COUNT(*) FROM todo_checklist_items c WHERE c.todo_id = todos.todo_id AND c.is_done = TRUE),
+			version
+		FROM todos
+		%s
+		ORDER BY %s
+		%s
+	`, where(conditions), req.OrderBy(column, "todo_id"), queryLimit))
+	if err != nil {
+		return
+	}
+	defer stmt.Close()
+
+	var rows *sql.Rows
+	rows, err = stmt.QueryContext(ctx, values...)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var todo domain.Todo
+
+		var (
+			isActive sql.NullBool
+			deletedAt sql.NullTime
+			completedAt sql.NullTime
+			dueAt sql.NullTime
+			remindAt sql.NullTime
+			remindedAt sql.NullTime
+			recurrence sql.NullString
+			nextID sql.NullInt64
+			checklistTotal int64
+			checklistDone int64
+		)
+
+		if err = rows.Scan(
+			&todo.ID,
+			&todo.ActivityGroupID,
+			&todo.Title,
+			&isActive,
+			&todo.Priority,
+			&todo.Position,
+			&todo.CreatedAt,
+			&todo.UpdatedAt,
+			&deletedAt,
+			&completedAt,
+			&dueAt,
+			&remindAt,
+			&remindedAt,
+			&todo.RemindAttempts,
+			&recurrence,
+			&todo.Occurrence,
+			&nextID,
+			&checklistTotal,
+			&checklistDone,
+			&todo.Version,
+		); err != nil {
+			return
+		}
+
+		if isActive.Valid {
+			todo.IsActive = isActive.Bool
+		}
+
+		if deletedAt.Valid {
+			todo.DeletedAt = &deletedAt.Time
+		}
+
+		if completedAt.Valid {
+			todo.CompletedAt = &completedAt.Time
+		}
+
+		if dueAt.Valid {
+			todo.DueAt = &dueAt.Time
+		}
+
+		if remindAt.Valid {
+			todo.RemindAt = &remindAt.Time
+		}
+
+		if remindedAt.Valid {
+			todo.RemindedAt = &remindedAt.Time
+		}
+
+		if nextID.Valid {
+			todo.NextID = &nextID.Int64
+		}
+
+		todo.SetChecklist(checklistTotal, checklistDone)
+
+		if todo.Recurrence, err = recurrenceOf(recurrence); err != nil {
+			return
+		}
+
+		res.Todos = append(res.Todos, todo)
+	}
+
+	if req.Limit != constant.ZeroValue && len(res.Todos) > req.Limit {
+		res.Todos = res.Todos[:req.Limit]
+
+		last := res.Todos[req.Limit-1]
+		res.Paging.NextCursor = req.Next(last.SortValue(req.Sort), last.ID)
+	}
+
+	// The tags of the page, once its rows are read
+	rows.Close()
+
+	todos := make([]*domain.Todo, len(res.Todos))
+	for i := range res.Todos {
+		todos[i] = &res.Todos[i]
+	}
+
+	err = tagNames(ctx, m.conn(ctx), todos...)
+
+	return
+}
+
+func (m *SqlRepository) GetAllStamp(ctx context.Context, req domain.TodoGetAllRequest) (res domain.TodoGetAllStampResponse, err error) {
+	conditions, values := filter(m.Dialect, req)
+
+	var lastModified database.NullTime
+
+	// Listed rows are counted, the filtered ones that left the list still date it
+	if err = m.conn(ctx).QueryRowContext(ctx, fmt.Sprintf(`
+		SELECT
+			COUNT(CASE WHEN %[1]s THEN 1 END),
+			COALESCE(SUM(CASE WHEN %[1]s THEN version END), 0),
+			COALESCE(MAX(CASE WHEN %[1]s THEN todo_id END), 0),
+			MAX(updated_at)
+		FROM todos
+		%[2]s
+	`, trash(req.Trashed), where(conditions)), values...).Scan(&res.Count, &res.Versions, &res.LastID, &lastModified); err != nil {
+		return
+	}
+
+	if lastModified.Valid {
+		res.LastModified = lastModified.Time
+	}
+
+	return
+}
+
+func (m *SqlRepository) GetOne(ctx context.Context, req domain.TodoGetOneRequest) (res domain.TodoGetOneResponse, err error) {
+	var stmt *sql.Stmt
+	stmt, err = m.conn(ctx).PrepareContext(ctx, fmt.Sprintf(`
+		SELECT 
+			todo_id,
+			activity_group_id,
+			title,
+			is_active,
+			priority,
+			position,
+			created_at,
+			updated_at,
+			deleted_at,
+			completed_at,
+			due_at,
+			remind_at,
+			reminded_at,
+			remind_attempts,
+			recurrence,
+			occurrence,
+			next_id,
+			(SELECT COUNT(*) FROM todo_checklist_items c WHERE c.todo_id = todos.todo_id),
+			(SELECT COUNT(*) FROM todo_checklist_items c WHERE c.todo_id = todos.todo_id AND c.is_done = TRUE),
+			version
+		FROM todos
+		WHERE todo_id = ? AND %s
+	`, trash(req.Trashed)))
+	if err != nil {
+		return
+	}
+	defer stmt.Close()
+
+	var rows *sql.Rows
+	rows, err = stmt.QueryContext(ctx, req.ID)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		var (
+			isActive sql.NullBool
+			deletedAt sql.NullTime
+			completedAt sql.NullTime
+			dueAt sql.NullTime
+			remindAt sql.NullTime
+			remindedAt sql.NullTime
+			recurrence sql.NullString
+			nextID sql.NullInt64
+			checklistTotal int64
+			checklistDone int64
+		)
+
+		if err = rows.Scan(
+			&res.ID,
+			&res.ActivityGroupID,
+			&res.Title,
+			&isActive,
+			&res.Priority,
+			&res.Position,
+			&res.CreatedAt,
+			&res.UpdatedAt,
+			&deletedAt,
+			&completedAt,
+			&dueAt,
+			&remindAt,
+			&remindedAt,
+			&res.RemindAttempts,
+			&recurrence,
+			&res.Occurrence,
+			&nextID,
+			&checklistTotal,
+			&checklistDone,
+			&res.Version,
+		); err != nil {
+			return
+		}
+
+		if isActive.Valid {
+			res.IsActive = isActive.Bool
+		}
+
+		if deletedAt.Valid {
+			res.DeletedAt = &deletedAt.Time
+		}
+
+		if completedAt.Valid {
+			res.CompletedAt = &completedAt.Time
+		}
+
+		if dueAt.Valid {
+			res.DueAt = &dueAt.Time
+		}
+
+		if remindAt.Valid {
+			res.RemindAt = &remindAt.Time
+		}
+
+		if remindedAt.Valid {
+			res.RemindedAt = &remindedAt.Time
+		}
+
+		if nextID.Valid {
+			res.NextID = &nextID.Int64
+		}
+
+		res.SetChecklist(checklistTotal, checklistDone)
+
+		if res.Recurrence, err = recurrenceOf(recurrence); err != nil {
+			return
+		}
+	}
+
+	if res.ID == int64(constant.ZeroValue) {
+		return
+	}
+
+	// The tags, once the row is read
+	rows.Close()
+
+	err = tagNames(ctx, m.conn(ctx), &res.Todo)
+
+	return
+}
\ No newline at end of file
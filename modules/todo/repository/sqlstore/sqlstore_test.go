@@ -0,0 +1,500 @@
+package sqlstore
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/fahmiaz411/devcode/config/database"
+	"github.com/fahmiaz411/devcode/config/migration"
+	"github.com/fahmiaz411/devcode/helper/pagination"
+	"github.com/fahmiaz411/devcode/modules/todo/domain"
+)
+
+// newStore is a todo store on a migrated in-memory SQLite database
+func newStore(t *testing.T) *SqlRepository {
+	t.Helper()
+
+	db := database.NewDatabase(database.Config{Driver: database.DriverSqlite, Sqlite: database.SqliteConfig{Path: ":memory:"}})
+	t.Cleanup(func() { db.Conn.Close() })
+
+	migrator, err := migration.NewMigrator(db.Conn, database.DriverSqlite)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = migrator.Up(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	return &SqlRepository{Conn: db.Conn, Dialect: database.DriverSqlite}
+}
+
+// create stores a todo in activity group 1 and fails the test otherwise
+func create(t *testing.T, m *SqlRepository, req domain.TodoCreateRequest) domain.Todo {
+	t.Helper()
+
+	if req.ActivityGroupID == 0 {
+		req.ActivityGroupID = 1
+	}
+
+	res, err := m.Create(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return res.Todo
+}
+
+func getOne(t *testing.T, m *SqlRepository, id int64, trashed bool) domain.Todo {
+	t.Helper()
+
+	res, err := m.GetOne(context.Background(), domain.TodoGetOneRequest{ID: id, Trashed: trashed})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return res.Todo
+}
+
+func TestCreateGetOne(t *testing.T) {
+	m := newStore(t)
+
+	due := time.Date(2030, time.January, 31, 9, 0, 0, 0, time.UTC)
+	remindAt := due.Add(-time.Hour)
+	done := false
+
+	created := create(t, m, domain.TodoCreateRequest{
+		Title:      "Pay rent",
+		IsActive:   &done,
+		DueAt:      &due,
+		RemindAt:   &remindAt,
+		Recurrence: &domain.Recurrence{Frequency: domain.FrequencyMonthly, Interval: 1, MonthDay: 31},
+		Position:   domain.PositionGap,
+	})
+
+	got := getOne(t, m, created.ID, false)
+	if got.ID == 0 {
+		t.Fatalf("todo %d not found", created.ID)
+	}
+
+	if got.Title != "Pay rent" || got.ActivityGroupID != 1 || got.IsActive || got.Position != domain.PositionGap {
+		t.Errorf("todo = %+v", got)
+	}
+
+	if got.Priority != domain.PriorityDefault || got.Occurrence != domain.OccurrenceDefault || got.Version != domain.VersionDefault {
+		t.Errorf("defaults = %s, %d, %d", got.Priority, got.Occurrence, got.Version)
+	}
+
+	if got.CompletedAt == nil || got.DueAt == nil || !got.DueAt.Equal(due) || got.RemindAt == nil || !got.RemindAt.Equal(remindAt) {
+		t.Errorf("times = completed %v, due %v, remind %v", got.CompletedAt, got.DueAt, got.RemindAt)
+	}
+
+	if got.Recurrence == nil || got.Recurrence.String() != "FREQ=MONTHLY;INTERVAL=1;BYMONTHDAY=31" {
+		t.Errorf("recurrence = %v", got.Recurrence)
+	}
+
+	if got.Tags == nil || len(got.Tags) != 0 {
+		t.Errorf("tags = %#v, want none", got.Tags)
+	}
+
+	if missing := getOne(t, m, created.ID+1, false); missing.ID != 0 {
+		t.Errorf("found todo %d, want none", missing.ID)
+	}
+}
+
+func TestBulkCreate(t *testing.T) {
+	ctx := context.Background()
+	m := newStore(t)
+
+	// IDs already taken, the batch does not start from 1
+	create(t, m, domain.TodoCreateRequest{Title: "first"})
+
+	titles := []string{"a", "b", "c"}
+	req := domain.TodoBulkCreateRequest{}
+	for i, title := range titles {
+		req.Todos = append(req.Todos, domain.TodoCreateRequest{Title: title, ActivityGroupID: int64(i%2 + 1)})
+	}
+
+	var res domain.TodoBulkCreateResponse
+	err := database.NewTransactor(&database.Database{Driver: database.DriverSqlite, Conn: m.Conn}).WithinTransaction(ctx, func(ctx context.Context) (err error) {
+		res, err = m.BulkCreate(ctx, req)
+		return
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Each todo comes back with the ID it was stored under
+	for i, todo := range res.Todos {
+		if got := getOne(t, m, todo.ID, false); got.Title != titles[i] || got.ActivityGroupID != todo.ActivityGroupID {
+			t.Errorf("todo %d = %q in %d, want %q in %d", todo.ID, got.Title, got.ActivityGroupID, titles[i], todo.ActivityGroupID)
+		}
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	ctx := context.Background()
+	m := newStore(t)
+	todo := create(t, m, domain.TodoCreateRequest{Title: "a"})
+
+	done := false
+	at := time.Now().UTC().Add(time.Minute).Truncate(time.Second)
+	if _, err := m.Update(ctx, domain.TodoUpdateRequest{ID: todo.ID, Title: "b", IsActive: &done, UpdatedAt: at, Version: todo.Version}); err != nil {
+		t.Fatal(err)
+	}
+
+	got := getOne(t, m, todo.ID, false)
+	if got.Title != "b" || got.IsActive || got.Version != todo.Version+1 || !got.UpdatedAt.Equal(at) {
+		t.Errorf("todo = %+v", got)
+	}
+
+	if got.CompletedAt == nil || !got.CompletedAt.Equal(at) {
+		t.Errorf("completedAt = %v, want %s", got.CompletedAt, at)
+	}
+
+	// Written against the version it read, a stale write changes nothing
+	_, err := m.Update(ctx, domain.TodoUpdateRequest{ID: todo.ID, Title: "c", UpdatedAt: at, Version: todo.Version})
+	if !errors.Is(err, domain.ErrVersionConflict) {
+		t.Fatalf("error = %v, want a version conflict", err)
+	}
+
+	if got := getOne(t, m, todo.ID, false); got.Title != "b" {
+		t.Errorf("title = %q after a stale write", got.Title)
+	}
+}
+
+func TestDelete(t *testing.T) {
+	ctx := context.Background()
+	m := newStore(t)
+	todo := create(t, m, domain.TodoCreateRequest{Title: "a"})
+
+	if _, err := m.Delete(ctx, domain.TodoDeleteRequest{ID: todo.ID, Version: todo.Version + 1}); !errors.Is(err, domain.ErrVersionConflict) {
+		t.Fatalf("error = %v, want a version conflict", err)
+	}
+
+	if _, err := m.Delete(ctx, domain.TodoDeleteRequest{ID: todo.ID, Version: todo.Version}); err != nil {
+		t.Fatal(err)
+	}
+
+	// In the trash, out of the live todos
+	if got := getOne(t, m, todo.ID, false); got.ID != 0 {
+		t.Errorf("deleted todo %d still live", got.ID)
+	}
+
+	trashed := getOne(t, m, todo.ID, true)
+	if trashed.DeletedAt == nil || trashed.Version != todo.Version+1 {
+		t.Errorf("trashed todo = %+v", trashed)
+	}
+}
+
+func TestGetAll(t *testing.T) {
+	ctx := context.Background()
+	m := newStore(t)
+
+	for _, title := range []string{"b", "a", "B", "c"} {
+		create(t, m, domain.TodoCreateRequest{Title: title})
+	}
+	other := create(t, m, domain.TodoCreateRequest{Title: "other", ActivityGroupID: 2})
+
+	// list runs req validated like the usecase does
+	list := func(req domain.TodoGetAllRequest) (titles []string, paging pagination.Paging) {
+		t.Helper()
+
+		if err := req.Validate(domain.SortAllList); err != nil {
+			t.Fatal(err)
+		}
+
+		res, err := m.GetAll(ctx, req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, todo := range res.Todos {
+			titles = append(titles, todo.Title)
+		}
+
+		return titles, res.Paging
+	}
+
+	// By title byte by byte, a page at a time
+	req := domain.TodoGetAllRequest{ActivityGroupID: 1, Request: pagination.Request{Limit: 3, Sort: domain.SortTitle, Order: pagination.OrderAsc}}
+	titles, paging := list(req)
+	if !reflect.DeepEqual(titles, []string{"B", "a", "b"}) || paging.Total != 4 || paging.NextCursor == "" {
+		t.Fatalf("first page = %v, %+v", titles, paging)
+	}
+
+	req.Cursor = paging.NextCursor
+	if titles, paging = list(req); !reflect.DeepEqual(titles, []string{"c"}) || paging.NextCursor != "" {
+		t.Errorf("second page = %v, %+v", titles, paging)
+	}
+
+	// The trash apart from the live todos
+	if _, err := m.Delete(ctx, domain.TodoDeleteRequest{ID: other.ID, Version: other.Version}); err != nil {
+		t.Fatal(err)
+	}
+
+	if titles, _ = list(domain.TodoGetAllRequest{ActivityGroupID: 2}); len(titles) != 0 {
+		t.Errorf("live todos of group 2 = %v, want none", titles)
+	}
+
+	if titles, _ = list(domain.TodoGetAllRequest{Trashed: true}); !reflect.DeepEqual(titles, []string{"other"}) {
+		t.Errorf("trash = %v, want other", titles)
+	}
+}
+
+func TestRestore(t *testing.T) {
+	ctx := context.Background()
+	m := newStore(t)
+	todo := create(t, m, domain.TodoCreateRequest{Title: "a"})
+	at := time.Now().UTC()
+
+	// A live todo is not restored
+	if _, err := m.Restore(ctx, domain.TodoRestoreRequest{ID: todo.ID, UpdatedAt: at, Version: todo.Version}); !errors.Is(err, domain.ErrVersionConflict) {
+		t.Fatalf("live: error = %v, want a version conflict", err)
+	}
+
+	if _, err := m.Delete(ctx, domain.TodoDeleteRequest{ID: todo.ID, Version: todo.Version}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Changed since the trash was read, the restore writes nothing
+	if _, err := m.Restore(ctx, domain.TodoRestoreRequest{ID: todo.ID, UpdatedAt: at, Version: todo.Version}); !errors.Is(err, domain.ErrVersionConflict) {
+		t.Fatalf("stale: error = %v, want a version conflict", err)
+	}
+
+	if _, err := m.Restore(ctx, domain.TodoRestoreRequest{ID: todo.ID, UpdatedAt: at, Version: todo.Version + 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := getOne(t, m, todo.ID, false); got.DeletedAt != nil || got.Version != todo.Version+2 {
+		t.Errorf("restored todo = %+v", got)
+	}
+}
+
+func TestGetAllDue(t *testing.T) {
+	ctx := context.Background()
+	m := newStore(t)
+	day := func(d int) *time.Time {
+		at := time.Date(2030, time.January, d, 0, 0, 0, 0, time.UTC)
+		return &at
+	}
+
+	for _, req := range []domain.TodoCreateRequest{
+		{Title: "undated"},
+		{Title: "14th", DueAt: day(14)},
+		{Title: "7th", DueAt: day(7)},
+		{Title: "13th", DueAt: day(13)},
+	} {
+		create(t, m, req)
+	}
+
+	list := func(req domain.TodoGetAllRequest) (titles []string, paging pagination.Paging) {
+		t.Helper()
+
+		if err := req.Validate(domain.SortAllList); err != nil {
+			t.Fatal(err)
+		}
+
+		res, err := m.GetAll(ctx, req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, todo := range res.Todos {
+			titles = append(titles, todo.Title)
+		}
+
+		return titles, res.Paging
+	}
+
+	// By due date a page at a time, the undated todos after every dated one
+	req := domain.TodoGetAllRequest{Request: pagination.Request{Limit: 3, Sort: domain.SortDueAt, Order: pagination.OrderAsc}}
+	titles, paging := list(req)
+	if !reflect.DeepEqual(titles, []string{"7th", "13th", "14th"}) || paging.NextCursor == "" {
+		t.Fatalf("first page = %v, %+v", titles, paging)
+	}
+
+	req.Cursor = paging.NextCursor
+	if titles, _ = list(req); !reflect.DeepEqual(titles, []string{"undated"}) {
+		t.Errorf("second page = %v, want undated", titles)
+	}
+
+	req = domain.TodoGetAllRequest{Request: pagination.Request{Limit: 2, Sort: domain.SortDueAt, Order: pagination.OrderDesc}}
+	if titles, _ = list(req); !reflect.DeepEqual(titles, []string{"undated", "14th"}) {
+		t.Errorf("descending = %v, want undated, 14th", titles)
+	}
+
+	// From the start of a day up to the start of the next, undated todos left out
+	if titles, _ = list(domain.TodoGetAllRequest{DueFrom: day(8), DueTo: day(14)}); !reflect.DeepEqual(titles, []string{"13th"}) {
+		t.Errorf("due from the 8th to the 14th = %v, want 13th", titles)
+	}
+}
+
+func TestClaimReminders(t *testing.T) {
+	ctx := context.Background()
+	m := newStore(t)
+	now := time.Now().UTC().Truncate(time.Second)
+
+	remindAt := now.Add(-time.Minute)
+	todo := create(t, m, domain.TodoCreateRequest{Title: "a", RemindAt: &remindAt})
+	tomorrow := now.AddDate(0, 0, 1)
+	create(t, m, domain.TodoCreateRequest{Title: "tomorrow", RemindAt: &tomorrow})
+
+	// claim leases the due reminders to worker until the time given
+	claim := func(worker string, at, until time.Time) []domain.Todo {
+		t.Helper()
+
+		res, err := m.ClaimReminders(ctx, domain.TodoClaimRemindersRequest{Claim: worker, Limit: 10, Now: at, Until: until})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return res.Todos
+	}
+
+	claimed := claim("first", now, now.Add(time.Minute))
+	if len(claimed) != 1 || claimed[0].ID != todo.ID || claimed[0].RemindAttempts != 1 {
+		t.Fatalf("claimed = %+v, want todo %d at its first attempt", claimed, todo.ID)
+	}
+
+	// Leased, then renewed by its worker only
+	if again := claim("second", now, now.Add(time.Minute)); len(again) != 0 {
+		t.Fatalf("claimed %d leased todos", len(again))
+	}
+
+	renew := func(worker string, until time.Time) bool {
+		t.Helper()
+
+		res, err := m.RenewReminder(ctx, domain.TodoRenewReminderRequest{ID: todo.ID, Claim: worker, Until: until})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return res.Renewed
+	}
+
+	if renew("second", now.Add(time.Hour)) || !renew("first", now.Add(time.Hour)) {
+		t.Fatal("renewed by the wrong worker")
+	}
+
+	if again := claim("second", now.Add(30*time.Minute), now.Add(time.Hour)); len(again) != 0 {
+		t.Fatalf("claimed %d todos within a renewed lease", len(again))
+	}
+
+	// Leases running out, the reminder is taken again until the last attempt
+	at := now.Add(time.Hour)
+	for i := 2; i <= domain.RemindAttemptsMax; i++ {
+		if claimed = claim("second", at, at); len(claimed) != 1 || claimed[0].RemindAttempts != int64(i) {
+			t.Fatalf("attempt %d: claimed = %+v", i, claimed)
+		}
+	}
+
+	if claimed = claim("second", at, at); len(claimed) != 0 {
+		t.Fatalf("claimed %d todos after the last attempt", len(claimed))
+	}
+
+	// A new reminder time starts over, a sent reminder is a new version of a later update
+	if _, err := m.Update(ctx, domain.TodoUpdateRequest{ID: todo.ID, RemindAt: domain.NullTime{Set: true, Time: &remindAt}, UpdatedAt: now, Version: todo.Version}); err != nil {
+		t.Fatal(err)
+	}
+
+	if claimed = claim("third", at, at.Add(time.Minute)); len(claimed) != 1 || claimed[0].RemindAttempts != 1 {
+		t.Fatalf("claimed = %+v after a new reminder time", claimed)
+	}
+
+	if res, err := m.MarkReminded(ctx, domain.TodoMarkRemindedRequest{ID: todo.ID, Claim: "third", RemindedAt: at}); err != nil || !res.Marked {
+		t.Fatalf("marked = %t, %v", res.Marked, err)
+	}
+
+	got := getOne(t, m, todo.ID, false)
+	if got.RemindedAt == nil || !got.RemindedAt.Equal(at) || !got.UpdatedAt.Equal(at) || got.Version != todo.Version+2 {
+		t.Errorf("todo = version %d at %s, reminded at %v", got.Version, got.UpdatedAt, got.RemindedAt)
+	}
+}
+
+func TestTags(t *testing.T) {
+	ctx := context.Background()
+	m := newStore(t)
+	tags := &SqlTagRepository{Conn: m.Conn, Dialect: m.Dialect}
+
+	a := create(t, m, domain.TodoCreateRequest{Title: "a"})
+	b := create(t, m, domain.TodoCreateRequest{Title: "b"})
+
+	work, err := tags.Create(ctx, domain.TagCreateRequest{Name: "work"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	home, err := tags.Create(ctx, domain.TagCreateRequest{Name: "home"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The unique key refuses a name taken meanwhile
+	if _, err = tags.Create(ctx, domain.TagCreateRequest{Name: "work"}); !errors.Is(err, domain.ErrTagExists) {
+		t.Fatalf("create: error = %v, want the tag to exist", err)
+	}
+
+	if _, err = tags.Update(ctx, domain.TagUpdateRequest{ID: home.ID, Name: "work", UpdatedAt: time.Now().UTC()}); !errors.Is(err, domain.ErrTagExists) {
+		t.Fatalf("update: error = %v, want the tag to exist", err)
+	}
+
+	// Attached twice, a tag is carried once
+	for _, ids := range [][]int64{{work.ID}, {work.ID, home.ID}} {
+		if _, err = tags.Attach(ctx, domain.TagAttachRequest{TodoID: a.ID, TagIDs: ids}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if got := getOne(t, m, a.ID, false); !reflect.DeepEqual(got.Tags, []string{"home", "work"}) {
+		t.Errorf("tags = %v, want home, work", got.Tags)
+	}
+
+	req := domain.TodoGetAllRequest{Tags: []string{"home", "errands"}}
+	if err = req.Validate(domain.SortAllList); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := m.GetAll(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(res.Todos) != 1 || res.Todos[0].ID != a.ID {
+		t.Errorf("tagged home or errands = %+v, want a", res.Todos)
+	}
+
+	// Renamed or deleted, the tag makes a new version of the todos carrying it only
+	at := time.Now().UTC().Add(time.Minute).Truncate(time.Second)
+	if _, err = tags.Update(ctx, domain.TagUpdateRequest{ID: work.ID, Name: "office", UpdatedAt: at}); err != nil {
+		t.Fatal(err)
+	}
+
+	got := getOne(t, m, a.ID, false)
+	if !reflect.DeepEqual(got.Tags, []string{"home", "office"}) || got.Version != a.Version+1 || !got.UpdatedAt.Equal(at) {
+		t.Errorf("renamed: todo = tags %v at version %d, %s", got.Tags, got.Version, got.UpdatedAt)
+	}
+
+	if _, err = tags.Delete(ctx, domain.TagDeleteRequest{ID: home.ID, UpdatedAt: at}); err != nil {
+		t.Fatal(err)
+	}
+
+	if got = getOne(t, m, a.ID, false); !reflect.DeepEqual(got.Tags, []string{"office"}) || got.Version != a.Version+2 {
+		t.Errorf("deleted: todo = tags %v at version %d", got.Tags, got.Version)
+	}
+
+	if got = getOne(t, m, b.ID, false); got.Version != b.Version {
+		t.Errorf("untagged todo at version %d, want %d", got.Version, b.Version)
+	}
+
+	if _, err = tags.Detach(ctx, domain.TagDetachRequest{TodoID: a.ID, TagIDs: []int64{work.ID}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if got = getOne(t, m, a.ID, false); len(got.Tags) != 0 {
+		t.Errorf("detached: tags = %v, want none", got.Tags)
+	}
+}
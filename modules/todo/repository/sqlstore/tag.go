@@ -0,0 +1,233 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fahmiaz411/devcode/config/database"
+	"github.com/fahmiaz411/devcode/helper/constant"
+	"github.com/fahmiaz411/devcode/modules/todo/domain"
+	"github.com/fahmiaz411/devcode/modules/todo/interfaces"
+)
+
+type SqlTagRepository struct {
+	Conn *sql.DB
+	Dialect database.Dialect
+}
+
+func NewSqlTagRepository(Conn *sql.DB, Dialect database.Dialect) interfaces.TagRepository {
+	return &SqlTagRepository{
+		Conn: Conn,
+		Dialect: Dialect,
+	}
+}
+
+// conn is the transaction of the unit of work ctx runs in, if any
+func (m *SqlTagRepository) conn(ctx context.Context) database.Executor {
+	return m.Dialect.Conn(ctx, m.Conn)
+}
+
+func (m *SqlTagRepository) Create(ctx context.Context, req domain.TagCreateRequest) (res domain.TagCreateResponse, err error) {
+	now := time.Now().UTC()
+
+	res.ID, err = m.Dialect.Insert(ctx, m.conn(ctx), `
+		INSERT INTO tags (name, created_at, updated_at) VALUES (?, ?, ?)
+	`, "tag_id", req.Name, now, now)
+	if m.Dialect.Duplicate(err) {
+		err = domain.ErrTagExists
+	}
+	if err != nil {
+		return
+	}
+
+	res.Name = req.Name
+	res.CreatedAt = now
+	res.UpdatedAt = now
+
+	return
+}
+
+func (m *SqlTagRepository) Update(ctx context.Context, req domain.TagUpdateRequest) (res domain.TagUpdateResponse, err error) {
+	if _, err = m.conn(ctx).ExecContext(ctx, `
+		UPDATE tags SET name = ?, updated_at = ? WHERE tag_id = ?
+	`, req.Name, req.UpdatedAt, req.ID); err != nil {
+		if m.Dialect.Duplicate(err) {
+			err = domain.ErrTagExists
+		}
+		return
+	}
+
+	err = m.touch(ctx, req.ID, req.UpdatedAt)
+
+	return
+}
+
+func (m *SqlTagRepository) Delete(ctx context.Context, req domain.TagDeleteRequest) (res domain.TagDeleteResponse, err error) {
+	if err = m.touch(ctx, req.ID, req.UpdatedAt); err != nil {
+		return
+	}
+
+	if _, err = m.conn(ctx).ExecContext(ctx, `
+		DELETE FROM todo_tags WHERE tag_id = ?
+	`, req.ID); err != nil {
+		return
+	}
+
+	_, err = m.conn(ctx).ExecContext(ctx, `
+		DELETE FROM tags WHERE tag_id = ?
+	`, req.ID)
+
+	return
+}
+
+// touch makes a new version of the todos carrying a tag
+func (m *SqlTagRepository) touch(ctx context.Context, id int64, at time.Time) (err error) {
+	_, err = m.conn(ctx).ExecContext(ctx, `
+		UPDATE todos SET updated_at = ?, version = version + 1 WHERE todo_id IN (SELECT todo_id FROM todo_tags WHERE tag_id = ?)
+	`, at, id)
+
+	return
+}
+
+func (m *SqlTagRepository) GetAll(ctx context.Context, req domain.TagGetAllRequest) (res domain.TagGetAllResponse, err error) {
+	res.Tags = []domain.Tag{}
+
+	var conditions []string
+	var values []any
+	if len(req.Names) != constant.ZeroValue {
+		conditions = append(conditions, fmt.Sprintf("name IN (%s)", placeholders(len(req.Names))))
+		for _, name := range req.Names {
+			values = append(values, name)
+		}
+	}
+
+	var rows *sql.Rows
+	rows, err = m.conn(ctx).QueryContext(ctx, fmt.Sprintf(`
+		SELECT tag_id, name, created_at, updated_at FROM tags %s ORDER BY name
+	`, where(conditions)), values...)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var tag domain.Tag
+		if err = rows.Scan(&tag.ID, &tag.Name, &tag.CreatedAt, &tag.UpdatedAt); err != nil {
+			return
+		}
+
+		res.Tags = append(res.Tags, tag)
+	}
+
+	err = rows.Err()
+
+	return
+}
+
+func (m *SqlTagRepository) GetOne(ctx context.Context, req domain.TagGetOneRequest) (res domain.TagGetOneResponse, err error) {
+	var rows *sql.Rows
+	rows, err = m.conn(ctx).QueryContext(ctx, `
+		SELECT tag_id, name, created_at, updated_at FROM tags WHERE tag_id = ?
+	`, req.ID)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		err = rows.Scan(&res.ID, &res.Name, &res.CreatedAt, &res.UpdatedAt)
+	}
+
+	return
+}
+
+func (m *SqlTagRepository) Attach(ctx context.Context, req domain.TagAttachRequest) (res domain.TagAttachResponse, err error) {
+	if len(req.TagIDs) == constant.ZeroValue {
+		return
+	}
+
+	// Tags the todo already carries are left as they are
+	if _, err = m.Detach(ctx, domain.TagDetachRequest{
+		TodoID: req.TodoID,
+		TagIDs: req.TagIDs,
+	}); err != nil {
+		return
+	}
+
+	tuples := make([]string, len(req.TagIDs))
+	values := []any{}
+	for i, id := range req.TagIDs {
+		tuples[i] = "(?, ?)"
+		values = append(values, req.TodoID, id)
+	}
+
+	_, err = m.conn(ctx).ExecContext(ctx, fmt.Sprintf(`
+		INSERT INTO todo_tags (todo_id, tag_id) VALUES %s
+	`, strings.Join(tuples, ", ")), values...)
+
+	return
+}
+
+func (m *SqlTagRepository) Detach(ctx context.Context, req domain.TagDetachRequest) (res domain.TagDetachResponse, err error) {
+	if len(req.TagIDs) == constant.ZeroValue {
+		return
+	}
+
+	values := []any{req.TodoID}
+	for _, id := range req.TagIDs {
+		values = append(values, id)
+	}
+
+	_, err = m.conn(ctx).ExecContext(ctx, fmt.Sprintf(`
+		DELETE FROM todo_tags WHERE todo_id = ? AND tag_id IN (%s)
+	`, placeholders(len(req.TagIDs))), values...)
+
+	return
+}
+
+// tagNames gives the todos the names of their tags, in order
+func tagNames(ctx context.Context, conn database.Executor, todos ...*domain.Todo) (err error) {
+	byID := map[int64]*domain.Todo{}
+	values := []any{}
+	for _, todo := range todos {
+		todo.Tags = []string{}
+		byID[todo.ID] = todo
+		values = append(values, todo.ID)
+	}
+
+	if len(todos) == constant.ZeroValue {
+		return
+	}
+
+	var rows *sql.Rows
+	rows, err = conn.QueryContext(ctx, fmt.Sprintf(`
+		SELECT todo_tags.todo_id, tags.name
+		FROM todo_tags
+		JOIN tags ON tags.tag_id = todo_tags.tag_id
+		WHERE todo_tags.todo_id IN (%s)
+		ORDER BY tags.name
+	`, placeholders(len(todos))), values...)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			todoID int64
+			name string
+		)
+		if err = rows.Scan(&todoID, &name); err != nil {
+			return
+		}
+
+		byID[todoID].Tags = append(byID[todoID].Tags, name)
+	}
+
+	err = rows.Err()
+
+	return
+}
@@ -0,0 +1,364 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/fahmiaz411/devcode/helper/constant"
+	"github.com/fahmiaz411/devcode/helper/failure"
+	"github.com/fahmiaz411/devcode/helper/field"
+	"github.com/fahmiaz411/devcode/helper/message"
+	"github.com/fahmiaz411/devcode/modules/todo/domain"
+)
+
+// GetChecklist lists the items of a live todo in their order
+func (u *Usecase) GetChecklist(ctx context.Context, req domain.ChecklistGetAllRequest) (res domain.ChecklistGetAllResponse, err error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contentTimeout)
+	defer cancel()
+
+	if _, err = u.GetOne(ctx, domain.TodoGetOneRequest{ID: req.TodoID}); err != nil {
+		return
+	}
+
+	res, err = u.repo.Checklist.GetAll(ctx, req)
+	if err != nil {
+		err = failure.Internal(err)
+	}
+
+	return
+}
+
+// CreateChecklistItem adds an item at the end of the checklist of a todo
+func (u *Usecase) CreateChecklistItem(ctx context.Context, req domain.ChecklistCreateRequest) (res domain.ChecklistCreateResponse, err error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contentTimeout)
+	defer cancel()
+
+	if req.Title == constant.EmptyString {
+		err = failure.Validation(message.CanotNull(field.Title))
+		return
+	}
+
+	err = u.repo.Transactor.WithinTransaction(ctx, func(ctx context.Context) (err error) {
+		var todo domain.TodoGetOneResponse
+		todo, err = u.GetOne(ctx, domain.TodoGetOneRequest{
+			ID: req.TodoID,
+		})
+		if err != nil {
+			return
+		}
+
+		var checklist domain.ChecklistGetAllResponse
+		if checklist, err = u.repo.Checklist.GetAll(ctx, domain.ChecklistGetAllRequest{TodoID: req.TodoID}); err != nil {
+			return
+		} else if len(checklist.Items) >= domain.ChecklistLimit {
+			err = failure.Validation(message.TooMany(field.Checklist, domain.ChecklistLimit))
+			return
+		}
+
+		req.Position = domain.PositionGap
+		if n := len(checklist.Items); n != constant.ZeroValue {
+			req.Position += checklist.Items[n-1].Position
+		}
+
+		if res, err = u.repo.Checklist.Create(ctx, req); err != nil {
+			return
+		}
+
+		return u.checklistChanged(ctx, todo.Todo, !req.IsDone)
+	})
+	if err != nil {
+		err = failure.Internal(err)
+	}
+
+	return
+}
+
+// UpdateChecklistItem renames an item or toggles it
+func (u *Usecase) UpdateChecklistItem(ctx context.Context, req domain.ChecklistUpdateRequest) (res domain.ChecklistUpdateResponse, err error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contentTimeout)
+	defer cancel()
+
+	if req.Title == constant.EmptyString && req.IsDone == nil {
+		err = failure.Validation(message.InvalidRequestBody)
+		return
+	}
+
+	err = u.repo.Transactor.WithinTransaction(ctx, func(ctx context.Context) (err error) {
+		var todo domain.TodoGetOneResponse
+		todo, err = u.GetOne(ctx, domain.TodoGetOneRequest{
+			ID: req.TodoID,
+		})
+		if err != nil {
+			return
+		}
+
+		var item domain.ChecklistItem
+		if item, _, err = u.checklistItem(ctx, req.TodoID, req.ID); err != nil {
+			return
+		}
+
+		req.UpdatedAt = time.Now().UTC()
+
+		if _, err = u.repo.Checklist.Update(ctx, req); err != nil {
+			return
+		}
+
+		if req.Title != constant.EmptyString {
+			item.Title = req.Title
+		}
+
+		if req.IsDone != nil {
+			item.IsDone = *req.IsDone
+		}
+
+		item.UpdatedAt = req.UpdatedAt
+		res.ChecklistItem = item
+
+		return u.checklistChanged(ctx, todo.Todo, !item.IsDone)
+	})
+	if err != nil {
+		err = failure.Internal(err)
+	}
+
+	return
+}
+
+// MoveChecklistItem puts an item right before or right after another of the checklist
+func (u *Usecase) MoveChecklistItem(ctx context.Context, req domain.ChecklistMoveRequest) (res domain.ChecklistMoveResponse, err error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contentTimeout)
+	defer cancel()
+
+	if err = validateChecklistMove(req); err != nil {
+		return
+	}
+
+	after := req.After != nil
+	targetID := req.Before
+	if after {
+		targetID = req.After
+	}
+
+	err = u.repo.Transactor.WithinTransaction(ctx, func(ctx context.Context) (err error) {
+		var todo domain.TodoGetOneResponse
+		todo, err = u.GetOne(ctx, domain.TodoGetOneRequest{
+			ID: req.TodoID,
+		})
+		if err != nil {
+			return
+		}
+
+		var (
+			item domain.ChecklistItem
+			items []domain.ChecklistItem
+		)
+		if item, items, err = u.checklistItem(ctx, req.TodoID, req.ID); err != nil {
+			return
+		} else if _, _, err = u.checklistItem(ctx, req.TodoID, *targetID); err != nil {
+			return
+		}
+
+		// The checklist in its new order
+		order := []domain.ChecklistItem{}
+		for _, other := range items {
+			if other.ID == item.ID {
+				continue
+			}
+
+			if other.ID == *targetID && !after {
+				order = append(order, item)
+			}
+
+			order = append(order, other)
+
+			if other.ID == *targetID && after {
+				order = append(order, item)
+			}
+		}
+
+		now := time.Now().UTC()
+
+		// Halfway between its new neighbours, the first or the last a gap away from the one next to it
+		var at int
+		for i := range order {
+			if order[i].ID == item.ID {
+				at = i
+			}
+		}
+
+		var low, high int64
+		switch {
+		case at == 0:
+			low, high = order[1].Position-2*domain.PositionGap, order[1].Position
+		case at == len(order)-1:
+			low, high = order[at-1].Position, order[at-1].Position+2*domain.PositionGap
+		default:
+			low, high = order[at-1].Position, order[at+1].Position
+		}
+
+		if high-low >= 2 {
+			position := low + (high-low)/2
+			if _, err = u.repo.Checklist.Update(ctx, domain.ChecklistUpdateRequest{
+				ID: item.ID,
+				TodoID: req.TodoID,
+				Position: &position,
+				UpdatedAt: now,
+			}); err != nil {
+				return
+			}
+
+			item.Position = position
+		} else {
+			// No position left there, the checklist is spread evenly again
+			for i := range order {
+				position := int64(i+1) * domain.PositionGap
+				if order[i].Position == position {
+					continue
+				}
+
+				if _, err = u.repo.Checklist.Update(ctx, domain.ChecklistUpdateRequest{
+					ID: order[i].ID,
+					TodoID: req.TodoID,
+					Position: &position,
+					UpdatedAt: now,
+				}); err != nil {
+					return
+				}
+			}
+
+			item.Position = int64(at+1) * domain.PositionGap
+		}
+
+		item.UpdatedAt = now
+		res.ChecklistItem = item
+
+		return u.checklistChanged(ctx, todo.Todo, false)
+	})
+	if err != nil {
+		err = failure.Internal(err)
+	}
+
+	return
+}
+
+func validateChecklistMove(req domain.ChecklistMoveRequest) (err error) {
+	if (req.Before == nil) == (req.After == nil) {
+		err = failure.Validation(message.ExactlyOne(field.Before, field.After))
+		return
+	}
+
+	target := req.Before
+	if req.After != nil {
+		target = req.After
+	}
+
+	if *target <= int64(constant.ZeroValue) {
+		err = failure.Validation(message.InvalidId(domain.ChecklistModel))
+		return
+	} else if *target == req.ID {
+		err = failure.Validation(message.MoveItself(domain.ChecklistModel))
+		return
+	}
+
+	return
+}
+
+// DeleteChecklistItem removes an item from the checklist for good
+func (u *Usecase) DeleteChecklistItem(ctx context.Context, req domain.ChecklistDeleteRequest) (res domain.ChecklistDeleteResponse, err error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contentTimeout)
+	defer cancel()
+
+	err = u.repo.Transactor.WithinTransaction(ctx, func(ctx context.Context) (err error) {
+		var todo domain.TodoGetOneResponse
+		todo, err = u.GetOne(ctx, domain.TodoGetOneRequest{
+			ID: req.TodoID,
+		})
+		if err != nil {
+			return
+		}
+
+		if _, _, err = u.checklistItem(ctx, req.TodoID, req.ID); err != nil {
+			return
+		}
+
+		if res, err = u.repo.Checklist.Delete(ctx, req); err != nil {
+			return
+		}
+
+		return u.checklistChanged(ctx, todo.Todo, false)
+	})
+	if err != nil {
+		err = failure.Internal(err)
+	}
+
+	return
+}
+
+// checklistItem finds an item of the checklist of a todo, along with the whole checklist
+func (u *Usecase) checklistItem(ctx context.Context, todoID, id int64) (item domain.ChecklistItem, items []domain.ChecklistItem, err error) {
+	var checklist domain.ChecklistGetAllResponse
+	if checklist, err = u.repo.Checklist.GetAll(ctx, domain.ChecklistGetAllRequest{TodoID: todoID}); err != nil {
+		return
+	}
+
+	items = checklist.Items
+	for _, item = range items {
+		if item.ID == id {
+			return
+		}
+	}
+
+	err = failure.NotFound(message.NotFound(domain.ChecklistModel, "ID", fmt.Sprint(id)))
+
+	return
+}
+
+// checklistChanged writes a new version of a todo whose checklist changed,
+// an open item reopens a completed todo
+func (u *Usecase) checklistChanged(ctx context.Context, todo domain.Todo, open bool) (err error) {
+	now := time.Now().UTC()
+
+	if open && !todo.IsActive {
+		isActive := true
+		_, err = u.repo.Store.Update(ctx, domain.TodoUpdateRequest{
+			ID: todo.ID,
+			IsActive: &isActive,
+			UpdatedAt: now,
+			Version: todo.Version,
+		})
+	} else {
+		_, err = u.repo.Store.Touch(ctx, domain.TodoTouchRequest{
+			ID: todo.ID,
+			UpdatedAt: now,
+			Version: todo.Version,
+		})
+	}
+
+	if errors.Is(err, domain.ErrVersionConflict) {
+		err = versionConflict(todo.ID, false)
+	}
+
+	return
+}
+
+// complete checks off the checklist of a todo a change completes, and creates the next occurrence of a recurring one
+func (u *Usecase) complete(ctx context.Context, todo domain.Todo, req domain.TodoUpdateRequest, at time.Time, last map[int64]int64) (nextID *int64, err error) {
+	after := todo.With(req)
+	if !todo.IsActive || after.IsActive {
+		return
+	}
+
+	if _, err = u.repo.Checklist.Check(ctx, domain.ChecklistCheckRequest{
+		TodoID: todo.ID,
+		UpdatedAt: at,
+	}); err != nil {
+		return
+	}
+
+	if after.Recurrence == nil || todo.NextID != nil {
+		return
+	}
+
+	return u.recur(ctx, after, at, last)
+}
@@ -0,0 +1,233 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"unicode/utf8"
+
+	"github.com/fahmiaz411/devcode/helper/constant"
+	"github.com/fahmiaz411/devcode/helper/failure"
+	"github.com/fahmiaz411/devcode/helper/field"
+	"github.com/fahmiaz411/devcode/helper/message"
+	"github.com/fahmiaz411/devcode/modules/todo/domain"
+)
+
+// GetAllTags lists the tags by name
+func (u *Usecase) GetAllTags(ctx context.Context, req domain.TagGetAllRequest) (res domain.TagGetAllResponse, err error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contentTimeout)
+	defer cancel()
+
+	req.Names = domain.TagNames(req.Names)
+
+	res, err = u.repo.Tags.GetAll(ctx, req)
+	if err != nil {
+		err = failure.Internal(err)
+	}
+
+	return
+}
+
+func (u *Usecase) GetOneTag(ctx context.Context, req domain.TagGetOneRequest) (res domain.TagGetOneResponse, err error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contentTimeout)
+	defer cancel()
+
+	res, err = u.repo.Tags.GetOne(ctx, req)
+	if err != nil {
+		err = failure.Internal(err)
+		return
+	}
+
+	if res.ID == int64(constant.ZeroValue) {
+		err = failure.NotFound(message.NotFound(domain.TagModel, "ID", fmt.Sprint(req.ID)))
+		return
+	}
+
+	return
+}
+
+// CreateTag adds a tag, names are unique once trimmed and lower cased
+func (u *Usecase) CreateTag(ctx context.Context, req domain.TagCreateRequest) (res domain.TagCreateResponse, err error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contentTimeout)
+	defer cancel()
+
+	req.Name = domain.TagName(req.Name)
+
+	if err = validateTagName(req.Name); err != nil {
+		return
+	}
+
+	// The unique key on the name decides between two requests creating the same tag
+	res, err = u.repo.Tags.Create(ctx, req)
+	if errors.Is(err, domain.ErrTagExists) {
+		err = tagExists(req.Name)
+		return
+	}
+	if err != nil {
+		err = failure.Internal(err)
+	}
+
+	return
+}
+
+// UpdateTag renames a tag, its todos list the new name
+func (u *Usecase) UpdateTag(ctx context.Context, req domain.TagUpdateRequest) (res domain.TagUpdateResponse, err error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contentTimeout)
+	defer cancel()
+
+	req.Name = domain.TagName(req.Name)
+
+	if err = validateTagName(req.Name); err != nil {
+		return
+	}
+
+	err = u.repo.Transactor.WithinTransaction(ctx, func(ctx context.Context) (err error) {
+		var tag domain.TagGetOneResponse
+		tag, err = u.GetOneTag(ctx, domain.TagGetOneRequest{
+			ID: req.ID,
+		})
+		if err != nil {
+			return
+		}
+
+		res.Tag = tag.Tag
+		if req.Name == tag.Name {
+			return
+		}
+
+		req.UpdatedAt = time.Now().UTC()
+
+		// Renamed along with the todos carrying it, unless another tag has the name
+		if _, err = u.repo.Tags.Update(ctx, req); err != nil {
+			if errors.Is(err, domain.ErrTagExists) {
+				err = tagExists(req.Name)
+			}
+			return
+		}
+
+		res.Name = req.Name
+		res.UpdatedAt = req.UpdatedAt
+
+		return
+	})
+	if err != nil {
+		err = failure.Internal(err)
+	}
+
+	return
+}
+
+// DeleteTag removes a tag for good, detaching it from its todos
+func (u *Usecase) DeleteTag(ctx context.Context, req domain.TagDeleteRequest) (res domain.TagDeleteResponse, err error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contentTimeout)
+	defer cancel()
+
+	err = u.repo.Transactor.WithinTransaction(ctx, func(ctx context.Context) (err error) {
+		if _, err = u.GetOneTag(ctx, domain.TagGetOneRequest{ID: req.ID}); err != nil {
+			return
+		}
+
+		req.UpdatedAt = time.Now().UTC()
+
+		res, err = u.repo.Tags.Delete(ctx, req)
+
+		return
+	})
+	if err != nil {
+		err = failure.Internal(err)
+	}
+
+	return
+}
+
+func validateTagName(name string) (err error) {
+	if name == constant.EmptyString {
+		err = failure.Validation(message.CanotNull(field.Name))
+		return
+	}
+
+	if utf8.RuneCountInString(name) > domain.TagNameMax {
+		err = failure.Validation(message.TooLong(field.Name, domain.TagNameMax))
+		return
+	}
+
+	return
+}
+
+// validateTagChange holds the rules of the tags a todo change attaches and detaches
+func validateTagChange(req domain.TodoUpdateRequest) (err error) {
+	for _, name := range req.AttachTags {
+		if err = validateTagName(name); err != nil {
+			return
+		}
+	}
+
+	for _, name := range req.DetachTags {
+		if err = validateTagName(name); err != nil {
+			return
+		}
+	}
+
+	for _, name := range req.AttachTags {
+		for _, other := range req.DetachTags {
+			if name == other {
+				err = failure.Validation(message.CannotCombine(field.AttachTags+"="+name, field.DetachTags+"="+name))
+				return
+			}
+		}
+	}
+
+	return
+}
+
+// tagExists is the conflict of a name another tag already has
+func tagExists(name string) error {
+	return failure.Conflict(message.AlreadyExists(domain.TagModel, "name", name))
+}
+
+// tagIDs resolves the names of existing tags, a missing one is NotFound
+func (u *Usecase) tagIDs(ctx context.Context, names []string) (ids []int64, err error) {
+	if len(names) == constant.ZeroValue {
+		return
+	}
+
+	var tags domain.TagGetAllResponse
+	if tags, err = u.repo.Tags.GetAll(ctx, domain.TagGetAllRequest{Names: names}); err != nil {
+		return
+	}
+
+	byName := map[string]int64{}
+	for _, tag := range tags.Tags {
+		byName[tag.Name] = tag.ID
+	}
+
+	for _, name := range names {
+		id, ok := byName[name]
+		if !ok {
+			err = failure.NotFound(message.NotFound(domain.TagModel, "name", name))
+			return
+		}
+
+		ids = append(ids, id)
+	}
+
+	return
+}
+
+// retag attaches and detaches the tags of a todo a change names
+func (u *Usecase) retag(ctx context.Context, todoID int64, attach, detach []int64) (err error) {
+	if _, err = u.repo.Tags.Detach(ctx, domain.TagDetachRequest{
+		TodoID: todoID,
+		TagIDs: detach,
+	}); err != nil {
+		return
+	}
+
+	_, err = u.repo.Tags.Attach(ctx, domain.TagAttachRequest{
+		TodoID: todoID,
+		TagIDs: attach,
+	})
+
+	return
+}
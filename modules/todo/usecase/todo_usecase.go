@@ -2,164 +2,1298 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"net/http"
 	"time"
 
 	"github.com/fahmiaz411/devcode/helper/constant"
+	"github.com/fahmiaz411/devcode/helper/failure"
+	"github.com/fahmiaz411/devcode/helper/field"
 	"github.com/fahmiaz411/devcode/helper/message"
-	"github.com/fahmiaz411/devcode/helper/web"
+	"github.com/fahmiaz411/devcode/helper/pagination"
+	"github.com/fahmiaz411/devcode/helper/slice"
 	"github.com/fahmiaz411/devcode/modules/todo/domain"
 	"github.com/fahmiaz411/devcode/modules/todo/interfaces"
 	"github.com/fahmiaz411/devcode/modules/todo/repository"
-
-	"github.com/gofiber/fiber/v2"
 )
 
 type Usecase struct {
 	repo           *repository.Repository
+	activities     interfaces.ActivityChecker
 	contentTimeout time.Duration
 }
 
-func NewUsecase(repo *repository.Repository, timeout time.Duration) interfaces.TodoUsecase {
+func NewUsecase(repo *repository.Repository, timeout time.Duration, activities interfaces.ActivityChecker) interfaces.TodoUsecase {
 	return &Usecase{
 		repo:           repo,
+		activities:     activities,
 		contentTimeout: timeout,
 	}
 }
 
+// versionConflict is a failed If-Match, or a concurrent write when the client sent none
+func versionConflict(id int64, conditional bool) error {
+	msg := message.Modified(domain.Model, "ID", fmt.Sprint(id))
+	if conditional {
+		return failure.Precondition(msg)
+	}
 
-func (u *Usecase) Create(c *fiber.Ctx, req domain.TodoCreateRequest) (res domain.TodoCreateResponse, err error) {
-	ctx, cancel := context.WithTimeout(c.UserContext(), u.contentTimeout)
-	defer cancel()
+	return failure.Conflict(msg)
+}
 
-	res, err = u.repo.MySQL.Create(ctx, req)
+// checkActivity rejects a todo pointing at an activity group that is missing or deleted
+func (u *Usecase) checkActivity(ctx context.Context, id int64) (err error) {
+	var exists bool
+	exists, err = u.activities.Exists(ctx, id)
 	if err != nil {
-		c.Status(http.StatusInternalServerError).JSON(web.BaseResponse{
-			Status: http.StatusText(http.StatusInternalServerError),
-			Message: err.Error(),
-			Data: struct{}{},
-		})
+		err = failure.Internal(err)
+		return
+	} else if !exists {
+		err = failure.NotFound(message.NotFound(domain.ActivityModel, "ID", fmt.Sprint(id)))
 		return
 	}
 
 	return
 }
 
-func (u *Usecase) Update(c *fiber.Ctx, req domain.TodoUpdateRequest) (res domain.TodoUpdateResponse, err error) {
-	ctx, cancel := context.WithTimeout(c.UserContext(), u.contentTimeout)
+// checkActivityField is checkActivity for the activity_group_id of a body, a missing group there is invalid input
+func (u *Usecase) checkActivityField(ctx context.Context, id int64) (err error) {
+	if err = u.checkActivity(ctx, id); failure.KindOf(err) == failure.KindNotFound {
+		err = failure.Validation(err.Error())
+	}
+
+	return
+}
+
+
+func (u *Usecase) Create(ctx context.Context, req domain.TodoCreateRequest) (res domain.TodoCreateResponse, err error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contentTimeout)
 	defer cancel()
 
-	var todo domain.TodoGetOneResponse
-	todo, err = u.GetOne(c, domain.TodoGetOneRequest{
-		ID: req.ID,
+	req.DueAt, req.RemindAt = domain.Deadline(req.DueAt), domain.Deadline(req.RemindAt)
+
+	if err = validateCreate(req); err != nil {
+		return
+	}
+
+	check := u.checkActivityField
+	if req.CheckActivity {
+		check = u.checkActivity
+	}
+
+	// The check locks the activity group, it cannot be deleted before the todo is in
+	err = u.repo.Transactor.WithinTransaction(ctx, func(ctx context.Context) (err error) {
+		if err = check(ctx, req.ActivityGroupID); err != nil {
+			return
+		}
+
+		req.Position, err = u.nextPosition(ctx, req.ActivityGroupID, map[int64]int64{})
+		if err != nil {
+			return
+		}
+
+		res, err = u.repo.Store.Create(ctx, req)
+
+		return
 	})
 	if err != nil {
+		err = failure.Internal(err)
 		return
 	}
 
-	req.UpdatedAt = time.Now().UTC()
+	return
+}
 
-	res, err = u.repo.MySQL.Update(ctx, req)
-	if err != nil {
-		c.Status(http.StatusInternalServerError).JSON(web.BaseResponse{
-			Status: http.StatusText(http.StatusInternalServerError),
-			Message: err.Error(),
-			Data: struct{}{},
+// validateCreate holds the rules of a new todo, for Create and BulkCreate
+func validateCreate(req domain.TodoCreateRequest) (err error) {
+	if req.Title == constant.EmptyString {
+		err = failure.Validation(message.CanotNull(field.Title))
+		return
+	} else if req.ActivityGroupID == int64(constant.ZeroValue) {
+		err = failure.Validation(message.CanotNull(field.ActivityGroupID))
+		return
+	}
+
+	if err = validateDeadlines(req.DueAt, req.RemindAt); err != nil {
+		return
+	}
+
+	return validateRecurrence(req.Recurrence, req.DueAt)
+}
+
+// validateDeadlines keeps the reminder of a todo from coming after its due date
+func validateDeadlines(dueAt, remindAt *time.Time) (err error) {
+	if dueAt != nil && remindAt != nil && remindAt.After(*dueAt) {
+		err = failure.Validation(message.MustBefore(field.RemindAt, field.DueAt))
+		return
+	}
+
+	return
+}
+
+// validateRecurrence holds the rules of a schedule and fills its defaults,
+// a monthly recurrence keeps the day of the due date
+func validateRecurrence(recurrence *domain.Recurrence, dueAt *time.Time) (err error) {
+	if recurrence == nil {
+		return
+	}
+
+	if !slice.Includes(domain.FrequencyAllList, recurrence.Frequency) {
+		err = failure.Validation(message.ShoudMatchEnum(field.Frequency, domain.FrequencyAllList))
+		return
+	}
+
+	if recurrence.Interval == constant.ZeroValue {
+		recurrence.Interval = 1
+	} else if recurrence.Interval < 1 || recurrence.Interval > domain.IntervalMax {
+		err = failure.Validation(message.Between(field.Interval, 1, domain.IntervalMax))
+		return
+	}
+
+	if len(recurrence.Weekdays) != constant.ZeroValue && recurrence.Frequency != domain.FrequencyWeekly {
+		err = failure.Validation(message.CannotCombine(field.ByWeekday, field.Frequency+"="+recurrence.Frequency))
+		return
+	}
+
+	for _, weekday := range recurrence.Weekdays {
+		if !slice.Includes(domain.WeekdayAllList, weekday) {
+			err = failure.Validation(message.ShoudMatchEnum(field.ByWeekday, domain.WeekdayAllList))
+			return
+		}
+	}
+
+	if recurrence.MonthDay != constant.ZeroValue && recurrence.Frequency != domain.FrequencyMonthly {
+		err = failure.Validation(message.CannotCombine(field.ByMonthDay, field.Frequency+"="+recurrence.Frequency))
+		return
+	} else if recurrence.MonthDay < 0 || recurrence.MonthDay > 31 {
+		err = failure.Validation(message.Between(field.ByMonthDay, 1, 31))
+		return
+	}
+
+	if recurrence.MonthDay == constant.ZeroValue && recurrence.Frequency == domain.FrequencyMonthly && dueAt != nil {
+		recurrence.MonthDay = dueAt.Day()
+	}
+
+	if recurrence.Count < 0 {
+		err = failure.Validation(message.CannotNegative(field.Count))
+		return
+	} else if recurrence.Count != constant.ZeroValue && recurrence.Until != nil {
+		err = failure.Validation(message.CannotCombine(field.Count, field.Until))
+		return
+	}
+
+	recurrence.Until = domain.Deadline(recurrence.Until)
+
+	return
+}
+
+// validateChange holds the rules of a todo change that depend on the todo as read, for Update and BulkUpdate
+func validateChange(todo domain.Todo, req domain.TodoUpdateRequest) (err error) {
+	after := todo.With(req)
+
+	if err = validateDeadlines(after.DueAt, after.RemindAt); err != nil {
+		return
+	}
+
+	if req.Recurrence.Set {
+		return validateRecurrence(req.Recurrence.Recurrence, after.DueAt)
+	}
+
+	return
+}
+
+// recur creates the next occurrence of a recurring todo completed at at, at the end of its activity group.
+// Its due date follows the schedule, the reminder moves along and the checklist starts over, nextID is nil once the series ended.
+func (u *Usecase) recur(ctx context.Context, todo domain.Todo, at time.Time, last map[int64]int64) (nextID *int64, err error) {
+	// Without a due date, the schedule goes on from the completion
+	anchor := at
+	if todo.DueAt != nil {
+		anchor = *todo.DueAt
+	}
+
+	next, ok := todo.Recurrence.Next(anchor, todo.Occurrence)
+	if !ok {
+		return
+	}
+
+	req := domain.TodoCreateRequest{
+		Title: todo.Title,
+		ActivityGroupID: todo.ActivityGroupID,
+		Recurrence: todo.Recurrence,
+		Priority: todo.Priority,
+		Occurrence: todo.Occurrence + 1,
+	}
+
+	if todo.DueAt != nil {
+		req.DueAt = &next
+	}
+
+	if todo.RemindAt != nil {
+		remindAt := todo.RemindAt.Add(next.Sub(anchor))
+		req.RemindAt = &remindAt
+	}
+
+	if req.Position, err = u.nextPosition(ctx, todo.ActivityGroupID, last); err != nil {
+		return
+	}
+
+	var created domain.TodoCreateResponse
+	if created, err = u.repo.Store.Create(ctx, req); err != nil {
+		return
+	}
+
+	nextID = &created.ID
+
+	var checklist domain.ChecklistGetAllResponse
+	if checklist, err = u.repo.Checklist.GetAll(ctx, domain.ChecklistGetAllRequest{TodoID: todo.ID}); err != nil {
+		return
+	}
+
+	for _, item := range checklist.Items {
+		if _, err = u.repo.Checklist.Create(ctx, domain.ChecklistCreateRequest{
+			TodoID: created.ID,
+			Title: item.Title,
+			Position: item.Position,
+		}); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+func (u *Usecase) Update(ctx context.Context, req domain.TodoUpdateRequest) (res domain.TodoUpdateResponse, err error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contentTimeout)
+	defer cancel()
+
+	req.DueAt.Time, req.RemindAt.Time = domain.Deadline(req.DueAt.Time), domain.Deadline(req.RemindAt.Time)
+	req.AttachTags, req.DetachTags = domain.TagNames(req.AttachTags), domain.TagNames(req.DetachTags)
+
+	if err = validateUpdate(req); err != nil {
+		return
+	}
+
+	// Without If-Match the version read below still keeps a concurrent write from being overwritten
+	conditional := req.Version != int64(constant.ZeroValue)
+
+	err = u.repo.Transactor.WithinTransaction(ctx, func(ctx context.Context) (err error) {
+		var todo domain.TodoGetOneResponse
+		todo, err = u.GetOne(ctx, domain.TodoGetOneRequest{
+			ID: req.ID,
+		})
+		if err != nil {
+			return
+		}
+
+		if !conditional {
+			req.Version = todo.Version
+		} else if req.Version != todo.Version {
+			err = versionConflict(req.ID, conditional)
+			return
+		}
+
+		if err = validateChange(todo.Todo, req); err != nil {
+			return
+		}
+
+		var attach, detach []int64
+		if attach, err = u.tagIDs(ctx, req.AttachTags); err != nil {
+			return
+		} else if detach, err = u.tagIDs(ctx, req.DetachTags); err != nil {
+			return
+		}
+
+		// Moving to another activity group, at its end
+		last := map[int64]int64{}
+		if req.ActivityGroupID != int64(constant.ZeroValue) && req.ActivityGroupID != todo.ActivityGroupID {
+			if err = u.checkActivityField(ctx, req.ActivityGroupID); err != nil {
+				return
+			}
+
+			var position int64
+			position, err = u.nextPosition(ctx, req.ActivityGroupID, last)
+			if err != nil {
+				return
+			}
+			req.Position = &position
+		}
+
+		req.UpdatedAt = time.Now().UTC()
+
+		// Completing a todo checks its checklist off, a recurring one comes back
+		if req.NextID, err = u.complete(ctx, todo.Todo, req, req.UpdatedAt, last); err != nil {
+			return
+		}
+
+		// A change of the tags alone still makes a new version of the todo
+		if req.Columns() {
+			_, err = u.repo.Store.Update(ctx, req)
+		} else {
+			_, err = u.repo.Store.Touch(ctx, domain.TodoTouchRequest{
+				ID: req.ID,
+				UpdatedAt: req.UpdatedAt,
+				Version: req.Version,
+			})
+		}
+
+		if errors.Is(err, domain.ErrVersionConflict) {
+			err = versionConflict(req.ID, conditional)
+			return
+		} else if err != nil {
+			return
+		}
+
+		if err = u.retag(ctx, req.ID, attach, detach); err != nil {
+			return
+		}
+
+		// The todo as written, in the same unit of work
+		todo, err = u.GetOne(ctx, domain.TodoGetOneRequest{
+			ID: req.ID,
 		})
+		res.Todo = todo.Todo
+
+		return
+	})
+	if err != nil {
+		err = failure.Internal(err)
 		return
 	}
 
-	res.ID = todo.ID
-	res.ActivityGroupID = todo.ActivityGroupID
-	res.CreatedAt = todo.CreatedAt
-	res.UpdatedAt = req.UpdatedAt
+	return 
+}
 
-	// Title
-	if req.Title != constant.EmptyString {
-		res.Title = req.Title
-	} else {
-		res.Title = todo.Title
+// validateUpdate holds the rules of a todo change, for Update and BulkUpdate
+func validateUpdate(req domain.TodoUpdateRequest) (err error) {
+	if !req.Columns() && len(req.AttachTags) == constant.ZeroValue && len(req.DetachTags) == constant.ZeroValue {
+		err = failure.Validation(message.InvalidRequestBody)
+		return
 	}
 
-	// Is Active
-	if req.IsActive != nil {
-		res.IsActive = *req.IsActive
-	} else {
-		res.IsActive = todo.IsActive
+	if err = validateTagChange(req); err != nil {
+		return
 	}
 
-	// Priority
 	if req.Priority != constant.EmptyString {
-		res.Priority = req.Priority
-	} else {
-		res.Priority = todo.Priority
+		if !slice.Includes(domain.PriorityAllList, req.Priority) {
+			err = failure.Validation(message.ShoudMatchEnum(field.Priority, domain.PriorityAllList))
+			return
+		}
+	}
+
+	return
+}
+
+func (u *Usecase) Delete(ctx context.Context, req domain.TodoDeleteRequest) (res domain.TodoDeleteResponse, err error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contentTimeout)
+	defer cancel()
+
+	conditional := req.Version != int64(constant.ZeroValue)
+
+	err = u.repo.Transactor.WithinTransaction(ctx, func(ctx context.Context) (err error) {
+		var todo domain.TodoGetOneResponse
+		todo, err = u.GetOne(ctx, domain.TodoGetOneRequest{
+			ID: req.ID,
+		})
+		if err != nil {
+			return
+		}
+
+		if !conditional {
+			req.Version = todo.Version
+		} else if req.Version != todo.Version {
+			err = versionConflict(req.ID, conditional)
+			return
+		}
+
+		res, err = u.repo.Store.Delete(ctx, req)
+		if errors.Is(err, domain.ErrVersionConflict) {
+			err = versionConflict(req.ID, conditional)
+		}
+
+		return
+	})
+	if err != nil {
+		err = failure.Internal(err)
 	}
 
 	return 
 }
 
-func (u *Usecase) Delete(c *fiber.Ctx, req domain.TodoDeleteRequest) (res domain.TodoDeleteResponse, err error) {
-	ctx, cancel := context.WithTimeout(c.UserContext(), u.contentTimeout)
+// Restore takes a todo out of the trash, back into its activity group
+func (u *Usecase) Restore(ctx context.Context, req domain.TodoRestoreRequest) (res domain.TodoRestoreResponse, err error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contentTimeout)
 	defer cancel()
 
-	_, err = u.GetOne(c, domain.TodoGetOneRequest{
-		ID: req.ID,
+	err = u.repo.Transactor.WithinTransaction(ctx, func(ctx context.Context) (err error) {
+		var todo domain.TodoGetOneResponse
+		todo, err = u.GetOne(ctx, domain.TodoGetOneRequest{
+			ID: req.ID,
+			Trashed: true,
+		})
+		if err != nil {
+			return
+		}
+
+		if err = u.checkActivity(ctx, todo.ActivityGroupID); err != nil {
+			return
+		}
+
+		req.UpdatedAt = time.Now().UTC()
+		req.Version = todo.Version
+
+		// Restored or changed since it was read
+		if _, err = u.repo.Store.Restore(ctx, req); errors.Is(err, domain.ErrVersionConflict) {
+			err = versionConflict(req.ID, false)
+			return
+		} else if err != nil {
+			return
+		}
+
+		res.Todo = todo.Todo
+		res.UpdatedAt = req.UpdatedAt
+		res.DeletedAt = nil
+		res.Version = todo.Version + 1
+
+		return
 	})
 	if err != nil {
+		err = failure.Internal(err)
 		return
 	}
-	
-	res, err = u.repo.MySQL.Delete(ctx, req)
-	if err != nil {
-		c.Status(http.StatusInternalServerError).JSON(web.BaseResponse{
-			Status: http.StatusText(http.StatusInternalServerError),
-			Message: err.Error(),
-			Data: struct{}{},
+
+	return
+}
+
+// Purge permanently deletes a todo, only from the trash
+func (u *Usecase) Purge(ctx context.Context, req domain.TodoPurgeRequest) (res domain.TodoPurgeResponse, err error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contentTimeout)
+	defer cancel()
+
+	err = u.repo.Transactor.WithinTransaction(ctx, func(ctx context.Context) (err error) {
+		_, err = u.GetOne(ctx, domain.TodoGetOneRequest{
+			ID: req.ID,
+			Trashed: true,
 		})
+		if err != nil {
+			return
+		}
+
+		res, err = u.repo.Store.Purge(ctx, req)
+
+		return
+	})
+	if err != nil {
+		err = failure.Internal(err)
 	}
 
-	return 
+	return
 }
 
-func (u *Usecase) GetAll(c *fiber.Ctx, req domain.TodoGetAllRequest) (res domain.TodoGetAllResponse, err error) {
-	ctx, cancel := context.WithTimeout(c.UserContext(), u.contentTimeout)
+// BulkCreate creates a batch of todos, all of them or none
+func (u *Usecase) BulkCreate(ctx context.Context, req domain.TodoBulkCreateRequest) (res domain.TodoBulkResponse, err error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contentTimeout)
 	defer cancel()
 
-	res, err = u.repo.MySQL.GetAll(ctx, req)
+	if err = validateBulk(len(req.Todos)); err != nil {
+		return
+	}
+
+	res.Results = make([]domain.TodoBulkResult, len(req.Todos))
+	for i := range req.Todos {
+		todo := &req.Todos[i]
+		todo.DueAt, todo.RemindAt = domain.Deadline(todo.DueAt), domain.Deadline(todo.RemindAt)
+		res.Results[i].Err = validateCreate(*todo)
+	}
+
+	if res.Rejected() != constant.ZeroValue {
+		return
+	}
+
+	err = u.repo.Transactor.WithinTransaction(ctx, func(ctx context.Context) (err error) {
+		checked := map[int64]error{}
+		for i, todo := range req.Todos {
+			res.Results[i].Err = u.checkActivityOnce(ctx, todo.ActivityGroupID, checked)
+		}
+
+		if res.Rejected() != constant.ZeroValue {
+			return
+		}
+
+		// The todos follow each other at the end of their activity group, in the order of the batch
+		last := map[int64]int64{}
+		for i := range req.Todos {
+			req.Todos[i].Position, err = u.nextPosition(ctx, req.Todos[i].ActivityGroupID, last)
+			if err != nil {
+				return
+			}
+		}
+
+		var created domain.TodoBulkCreateResponse
+		created, err = u.repo.Store.BulkCreate(ctx, req)
+		if err != nil {
+			return
+		}
+
+		for i := range created.Todos {
+			res.Results[i].Todo = &created.Todos[i]
+		}
+
+		return
+	})
+	if err != nil {
+		err = failure.Internal(err)
+		return
+	}
+
+	return
+}
+
+// BulkUpdate changes a batch of todos, all of them or none
+func (u *Usecase) BulkUpdate(ctx context.Context, req domain.TodoBulkUpdateRequest) (res domain.TodoBulkResponse, err error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contentTimeout)
+	defer cancel()
+
+	if err = validateBulk(len(req.Todos)); err != nil {
+		return
+	}
+
+	res.Results = make([]domain.TodoBulkResult, len(req.Todos))
+	ids := make([]int64, len(req.Todos))
+	seen := map[int64]bool{}
+	for i := range req.Todos {
+		todo := &req.Todos[i]
+		todo.DueAt.Time, todo.RemindAt.Time = domain.Deadline(todo.DueAt.Time), domain.Deadline(todo.RemindAt.Time)
+		todo.AttachTags, todo.DetachTags = domain.TagNames(todo.AttachTags), domain.TagNames(todo.DetachTags)
+
+		ids[i] = todo.ID
+		if res.Results[i].Err = validateBulkID(todo.ID, seen); res.Results[i].Err == nil {
+			res.Results[i].Err = validateUpdate(todo.TodoUpdateRequest)
+		}
+	}
+
+	if res.Rejected() != constant.ZeroValue {
+		return
+	}
+
+	err = u.repo.Transactor.WithinTransaction(ctx, func(ctx context.Context) (err error) {
+		var current map[int64]domain.Todo
+		current, err = u.getMany(ctx, ids)
+		if err != nil {
+			return
+		}
+
+		checked := map[int64]error{}
+		attach, detach := make([][]int64, len(req.Todos)), make([][]int64, len(req.Todos))
+		for i := range req.Todos {
+			todo := &req.Todos[i]
+
+			read, ok := current[todo.ID]
+			if !ok {
+				res.Results[i].Err = failure.NotFound(message.NotFound(domain.Model, "ID", fmt.Sprint(todo.ID)))
+				continue
+			}
+
+			// A version in the item is its If-Match, without one the version read here is expected
+			if todo.Version == int64(constant.ZeroValue) {
+				todo.Version = read.Version
+			} else if todo.Version != read.Version {
+				res.Results[i].Err = versionConflict(todo.ID, true)
+				continue
+			}
+
+			if res.Results[i].Err = validateChange(read, todo.TodoUpdateRequest); res.Results[i].Err != nil {
+				continue
+			}
+
+			if attach[i], res.Results[i].Err = u.tagIDs(ctx, todo.AttachTags); res.Results[i].Err != nil {
+				continue
+			} else if detach[i], res.Results[i].Err = u.tagIDs(ctx, todo.DetachTags); res.Results[i].Err != nil {
+				continue
+			}
+
+			// Moving to another activity group
+			if todo.ActivityGroupID != int64(constant.ZeroValue) && todo.ActivityGroupID != read.ActivityGroupID {
+				res.Results[i].Err = u.checkActivityOnce(ctx, todo.ActivityGroupID, checked)
+			}
+		}
+
+		if res.Rejected() != constant.ZeroValue {
+			return
+		}
+
+		req.UpdatedAt = time.Now().UTC()
+
+		// The todos moving to another activity group go to its end, in the order of the batch,
+		// followed by the next occurrences of the recurring todos the batch completes
+		last := map[int64]int64{}
+		for i := range req.Todos {
+			todo := &req.Todos[i]
+			if todo.ActivityGroupID != int64(constant.ZeroValue) && todo.ActivityGroupID != current[todo.ID].ActivityGroupID {
+				var position int64
+				position, err = u.nextPosition(ctx, todo.ActivityGroupID, last)
+				if err != nil {
+					return
+				}
+				todo.Position = &position
+			}
+		}
+
+		for i := range req.Todos {
+			todo := &req.Todos[i]
+			if todo.NextID, err = u.complete(ctx, current[todo.ID], todo.TodoUpdateRequest, req.UpdatedAt, last); err != nil {
+				return
+			}
+		}
+
+		_, err = u.repo.Store.BulkUpdate(ctx, req)
+		if errors.Is(err, domain.ErrVersionConflict) {
+			err = failure.Conflict(message.BulkModified)
+			return
+		} else if err != nil {
+			return
+		}
+
+		for i, todo := range req.Todos {
+			if err = u.retag(ctx, todo.ID, attach[i], detach[i]); err != nil {
+				return
+			}
+		}
+
+		// The todos as written, in the same unit of work
+		current, err = u.getMany(ctx, ids)
+		if err != nil {
+			return
+		}
+
+		for i, todo := range req.Todos {
+			written := current[todo.ID]
+			res.Results[i].Todo = &written
+		}
+
+		return
+	})
 	if err != nil {
-		c.Status(http.StatusInternalServerError).JSON(web.BaseResponse{
-			Status: http.StatusText(http.StatusInternalServerError),
-			Message: err.Error(),
-			Data: struct{}{},
+		err = failure.Internal(err)
+		return
+	}
+
+	return
+}
+
+// BulkDelete moves a batch of todos to the trash, all of them or none
+func (u *Usecase) BulkDelete(ctx context.Context, req domain.TodoBulkDeleteRequest) (res domain.TodoBulkResponse, err error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contentTimeout)
+	defer cancel()
+
+	if err = validateBulk(len(req.Todos)); err != nil {
+		return
+	}
+
+	res.Results = make([]domain.TodoBulkResult, len(req.Todos))
+	ids := make([]int64, len(req.Todos))
+	seen := map[int64]bool{}
+	for i, todo := range req.Todos {
+		ids[i] = todo.ID
+		res.Results[i].Err = validateBulkID(todo.ID, seen)
+	}
+
+	if res.Rejected() != constant.ZeroValue {
+		return
+	}
+
+	err = u.repo.Transactor.WithinTransaction(ctx, func(ctx context.Context) (err error) {
+		var current map[int64]domain.Todo
+		current, err = u.getMany(ctx, ids)
+		if err != nil {
+			return
+		}
+
+		for i := range req.Todos {
+			todo := &req.Todos[i]
+
+			read, ok := current[todo.ID]
+			if !ok {
+				res.Results[i].Err = failure.NotFound(message.NotFound(domain.Model, "ID", fmt.Sprint(todo.ID)))
+				continue
+			}
+
+			if todo.Version == int64(constant.ZeroValue) {
+				todo.Version = read.Version
+			} else if todo.Version != read.Version {
+				res.Results[i].Err = versionConflict(todo.ID, true)
+			}
+		}
+
+		if res.Rejected() != constant.ZeroValue {
+			return
+		}
+
+		_, err = u.repo.Store.BulkDelete(ctx, req)
+		if errors.Is(err, domain.ErrVersionConflict) {
+			err = failure.Conflict(message.BulkModified)
+		}
+
+		return
+	})
+	if err != nil {
+		err = failure.Internal(err)
+		return
+	}
+
+	return
+}
+
+// Move puts a todo right before or right after another of its activity group.
+// It takes the middle of the gap to the neighbour, the group is renumbered only once the gap runs out.
+func (u *Usecase) Move(ctx context.Context, req domain.TodoMoveRequest) (res domain.TodoMoveResponse, err error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contentTimeout)
+	defer cancel()
+
+	if err = validateMove(req); err != nil {
+		return
+	}
+
+	after := req.After != nil
+	targetID := req.Before
+	if after {
+		targetID = req.After
+	}
+
+	conditional := req.Version != int64(constant.ZeroValue)
+
+	err = u.repo.Transactor.WithinTransaction(ctx, func(ctx context.Context) (err error) {
+		var todo domain.TodoGetOneResponse
+		todo, err = u.GetOne(ctx, domain.TodoGetOneRequest{
+			ID: req.ID,
 		})
+		if err != nil {
+			return
+		}
+
+		if conditional && req.Version != todo.Version {
+			err = versionConflict(req.ID, conditional)
+			return
+		}
+
+		var target domain.TodoGetOneResponse
+		target, err = u.GetOne(ctx, domain.TodoGetOneRequest{
+			ID: *targetID,
+		})
+		if err != nil {
+			return
+		} else if target.ActivityGroupID != todo.ActivityGroupID {
+			err = failure.Validation(message.OtherGroup(domain.Model, "ID", fmt.Sprint(target.ID)))
+			return
+		}
+
+		var (
+			position int64
+			ok bool
+		)
+		position, ok, err = u.between(ctx, todo.ID, target.Todo, after)
+		if err != nil {
+			return
+		}
+
+		if !ok {
+			if err = u.renumber(ctx, todo.ActivityGroupID); err != nil {
+				return
+			}
+
+			// Both were renumbered, so read again
+			if todo, err = u.GetOne(ctx, domain.TodoGetOneRequest{ID: req.ID}); err != nil {
+				return
+			}
+
+			if target, err = u.GetOne(ctx, domain.TodoGetOneRequest{ID: target.ID}); err != nil {
+				return
+			}
+
+			if position, _, err = u.between(ctx, todo.ID, target.Todo, after); err != nil {
+				return
+			}
+		}
+
+		_, err = u.repo.Store.Update(ctx, domain.TodoUpdateRequest{
+			ID: req.ID,
+			Position: &position,
+			UpdatedAt: time.Now().UTC(),
+			Version: todo.Version,
+		})
+		if errors.Is(err, domain.ErrVersionConflict) {
+			err = versionConflict(req.ID, conditional)
+			return
+		} else if err != nil {
+			return
+		}
+
+		// The todo as written, in the same unit of work
+		todo, err = u.GetOne(ctx, domain.TodoGetOneRequest{
+			ID: req.ID,
+		})
+		res.Todo = todo.Todo
+
+		return
+	})
+	if err != nil {
+		err = failure.Internal(err)
 		return
 	}
 
 	return
 }
 
-func (u *Usecase) GetOne(c *fiber.Ctx, req domain.TodoGetOneRequest) (res domain.TodoGetOneResponse, err error) {
-	ctx, cancel := context.WithTimeout(c.UserContext(), u.contentTimeout)
+func validateMove(req domain.TodoMoveRequest) (err error) {
+	if (req.Before == nil) == (req.After == nil) {
+		err = failure.Validation(message.ExactlyOne(field.Before, field.After))
+		return
+	}
+
+	target := req.Before
+	if req.After != nil {
+		target = req.After
+	}
+
+	if *target <= int64(constant.ZeroValue) {
+		err = failure.Validation(message.InvalidId(domain.Model))
+		return
+	} else if *target == req.ID {
+		err = failure.Validation(message.MoveItself(domain.Model))
+		return
+	}
+
+	return
+}
+
+// between is the position halfway from the target to its neighbour on the side of the move,
+// ok is false when no position is left there
+func (u *Usecase) between(ctx context.Context, id int64, target domain.Todo, after bool) (position int64, ok bool, err error) {
+	var neighbour domain.TodoNeighbourResponse
+	neighbour, err = u.repo.Store.Neighbour(ctx, domain.TodoNeighbourRequest{
+		ActivityGroupID: target.ActivityGroupID,
+		Position: target.Position,
+		ID: target.ID,
+		After: after,
+		ExcludeID: id,
+	})
+	if err != nil {
+		return
+	}
+
+	// The first, or the last, of the group
+	bound := target.Position - domain.PositionGap
+	if after {
+		bound = target.Position + domain.PositionGap
+	}
+
+	if neighbour.ID != int64(constant.ZeroValue) {
+		bound = neighbour.Position
+	}
+
+	gap := bound - target.Position
+	if gap < 0 {
+		gap = -gap
+	}
+
+	if gap < 2 {
+		return
+	}
+
+	position, ok = target.Position + (bound - target.Position) / 2, true
+
+	return
+}
+
+// renumber spreads the live todos of an activity group evenly again, keeping their order.
+// Each gets a new version, the lists and the todos cached by a client are stale once their positions change.
+func (u *Usecase) renumber(ctx context.Context, activityGroupID int64) (err error) {
+	var todos domain.TodoGetAllResponse
+	todos, err = u.repo.Store.GetAll(ctx, domain.TodoGetAllRequest{
+		ActivityGroupID: activityGroupID,
+		Request: pagination.Request{
+			Sort: domain.SortPosition,
+			Order: pagination.OrderAsc,
+		},
+	})
+	if err != nil {
+		return
+	}
+
+	now := time.Now().UTC()
+
+	// In batches, as a statement holds a bounded number of placeholders
+	for start := 0; start < len(todos.Todos); start += domain.BulkLimit {
+		end := start + domain.BulkLimit
+		if end > len(todos.Todos) {
+			end = len(todos.Todos)
+		}
+
+		req := domain.TodoRenumberRequest{
+			Todos: make([]domain.TodoRenumberItem, end - start),
+			UpdatedAt: now,
+		}
+
+		for i, todo := range todos.Todos[start:end] {
+			req.Todos[i].ID = todo.ID
+			req.Todos[i].Position = int64(start + i + 1) * domain.PositionGap
+		}
+
+		if _, err = u.repo.Store.Renumber(ctx, req); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// nextPosition is the end of an activity group, last keeps the ends already given within a batch
+func (u *Usecase) nextPosition(ctx context.Context, activityGroupID int64, last map[int64]int64) (position int64, err error) {
+	if _, ok := last[activityGroupID]; !ok {
+		var res domain.TodoLastPositionResponse
+		res, err = u.repo.Store.LastPosition(ctx, domain.TodoLastPositionRequest{
+			ActivityGroupID: activityGroupID,
+		})
+		if err != nil {
+			return
+		}
+
+		last[activityGroupID] = res.Position
+	}
+
+	last[activityGroupID] += domain.PositionGap
+	position = last[activityGroupID]
+
+	return
+}
+
+func validateBulk(n int) (err error) {
+	if n == constant.ZeroValue {
+		err = failure.Validation(message.CannotEmpty("todo items"))
+		return
+	} else if n > domain.BulkLimit {
+		err = failure.Validation(message.TooMany("todo items", domain.BulkLimit))
+		return
+	}
+
+	return
+}
+
+// validateBulkID rejects a missing ID, or one an earlier item of the batch already gave
+func validateBulkID(id int64, seen map[int64]bool) (err error) {
+	if id <= int64(constant.ZeroValue) {
+		err = failure.Validation(message.InvalidId(domain.Model))
+		return
+	} else if seen[id] {
+		err = failure.Validation(message.Duplicate(domain.Model, "ID", fmt.Sprint(id)))
+		return
+	}
+
+	seen[id] = true
+
+	return
+}
+
+// checkActivityOnce is checkActivityField for a batch, each activity group is checked once
+func (u *Usecase) checkActivityOnce(ctx context.Context, id int64, checked map[int64]error) (err error) {
+	err, ok := checked[id]
+	if !ok {
+		err = u.checkActivityField(ctx, id)
+		checked[id] = err
+	}
+
+	return
+}
+
+// getMany reads the live todos of a batch by ID
+func (u *Usecase) getMany(ctx context.Context, ids []int64) (todos map[int64]domain.Todo, err error) {
+	var res domain.TodoGetAllResponse
+	res, err = u.repo.Store.GetAll(ctx, domain.TodoGetAllRequest{
+		IDs: ids,
+		Request: pagination.Request{
+			Sort: domain.SortID,
+			Order: pagination.OrderAsc,
+		},
+	})
+	if err != nil {
+		return
+	}
+
+	todos = make(map[int64]domain.Todo, len(res.Todos))
+	for _, todo := range res.Todos {
+		todos[todo.ID] = todo
+	}
+
+	return
+}
+
+func (u *Usecase) GetAll(ctx context.Context, req domain.TodoGetAllRequest) (res domain.TodoGetAllResponse, err error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contentTimeout)
 	defer cancel()
 
-	res, err = u.repo.MySQL.GetOne(ctx, req)
+	if err = u.validateGetAll(ctx, &req); err != nil {
+		return
+	}
+
+	res, err = u.repo.Store.GetAll(ctx, req)
+	if err != nil {
+		err = failure.Internal(err)
+		return
+	}
+
+	return
+}
+
+// GetAllStamp tells cheaply whether the result of GetAll changed
+func (u *Usecase) GetAllStamp(ctx context.Context, req domain.TodoGetAllRequest) (res domain.TodoGetAllStampResponse, err error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contentTimeout)
+	defer cancel()
+
+	if err = u.validateGetAll(ctx, &req); err != nil {
+		return
+	}
+
+	res, err = u.repo.Store.GetAllStamp(ctx, req)
+	if err != nil {
+		err = failure.Internal(err)
+		return
+	}
+
+	return
+}
+
+// Stats sums up the live todos of an activity group, or of all of them, with what was created and completed per day
+func (u *Usecase) Stats(ctx context.Context, req domain.TodoStatsRequest) (res domain.TodoStatsResponse, err error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contentTimeout)
+	defer cancel()
+
+	// Whole UTC days, the range ends with today unless told otherwise
+	const day = 24 * time.Hour
+
+	to := time.Now().UTC().Truncate(day).AddDate(0, 0, 1)
+	if req.To != nil {
+		if to = req.To.UTC().Truncate(day); to.Before(*req.To) {
+			to = to.AddDate(0, 0, 1)
+		}
+	}
+
+	from := to.AddDate(0, 0, -domain.StatsDaysDefault)
+	if req.From != nil {
+		from = req.From.UTC().Truncate(day)
+	}
+
+	if !from.Before(to) {
+		err = failure.Validation(message.MustBefore(field.From, field.To))
+		return
+	} else if to.Sub(from) > domain.StatsDaysMax*day {
+		err = failure.Validation(message.RangeTooLong(field.From, field.To, domain.StatsDaysMax))
+		return
+	}
+
+	if req.CheckActivity {
+		if err = u.checkActivity(ctx, req.ActivityGroupID); err != nil {
+			return
+		}
+	}
+
+	var agg domain.TodoAggregateResponse
+	agg, err = u.repo.Store.Aggregate(ctx, domain.TodoAggregateRequest{
+		ActivityGroupID: req.ActivityGroupID,
+		From: from,
+		To: to,
+	})
 	if err != nil {
-		c.Status(http.StatusInternalServerError).JSON(web.BaseResponse{
-			Status: http.StatusText(http.StatusInternalServerError),
-			Message: err.Error(),
-			Data: struct{}{},
+		err = failure.Internal(err)
+		return
+	}
+
+	counts := domain.NewTodoCounts()
+	for _, group := range agg.Groups {
+		counts.Add(group)
+	}
+
+	res.TodoCounts = *counts
+	res.Completed = counts.Total - counts.Active
+	res.AvgCompletionSeconds = agg.AvgCompletionSeconds
+
+	res.From = from.Format(time.DateOnly)
+	res.To = to.AddDate(0, 0, -1).Format(time.DateOnly)
+
+	res.Days = []domain.TodoStatsDay{}
+	for date := from; date.Before(to); date = date.AddDate(0, 0, 1) {
+		key := date.Format(time.DateOnly)
+		res.Days = append(res.Days, domain.TodoStatsDay{
+			Date: key,
+			Created: agg.Created[key],
+			Completed: agg.Completed[key],
 		})
+	}
+
+	return
+}
+
+func (u *Usecase) validateGetAll(ctx context.Context, req *domain.TodoGetAllRequest) (err error) {
+	req.Tags = domain.TagNames(req.Tags)
+
+	for _, priority := range req.Priorities {
+		if !slice.Includes(domain.PriorityAllList, priority) {
+			err = failure.Validation(message.ShoudMatchEnum(field.Priority, domain.PriorityAllList))
+			return
+		}
+	}
+
+	if req.Due != constant.EmptyString {
+		if err = narrowDue(req, time.Now().UTC()); err != nil {
+			return
+		}
+	}
+
+	if err = req.Validate(domain.SortAllList); err != nil {
+		err = failure.Validation(err.Error())
+		return
+	}
+
+	if req.CheckActivity {
+		if err = u.checkActivity(ctx, req.ActivityGroupID); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// narrowDue turns the due view of a list into due bounds, whole UTC days for today and the week from Monday.
+// Overdue are the todos still active past their due date.
+func narrowDue(req *domain.TodoGetAllRequest, now time.Time) (err error) {
+	const day = 24 * time.Hour
+
+	today := now.Truncate(day)
+
+	var from, to *time.Time
+	switch req.Due {
+	case domain.DueOverdue:
+		if req.IsActive != nil && !*req.IsActive {
+			err = failure.Validation(message.CannotCombine(field.Due+"="+domain.DueOverdue, field.IsActive+"=false"))
+			return
+		}
+
+		isActive := true
+		req.IsActive = &isActive
+		to = &now
+	case domain.DueToday:
+		tomorrow := today.AddDate(0, 0, 1)
+		from, to = &today, &tomorrow
+	case domain.DueWeek:
+		monday := today.AddDate(0, 0, -(int(today.Weekday()) + 6) % 7)
+		next := monday.AddDate(0, 0, 7)
+		from, to = &monday, &next
+	default:
+		err = failure.Validation(message.ShoudMatchEnum(field.Due, domain.DueAllList))
+		return
+	}
+
+	// Bounds given along with the view are kept, the tighter ones apply
+	if from != nil && (req.DueFrom == nil || from.After(*req.DueFrom)) {
+		req.DueFrom = from
+	}
+
+	if to != nil && (req.DueTo == nil || to.Before(*req.DueTo)) {
+		req.DueTo = to
+	}
+
+	return
+}
+
+// ClaimReminders leases the due reminders to a dispatcher, along with the email of their activity groups.
+// The claims and the lookup of the emails are one unit of work, a failure leaves no todo leased without being sent.
+// Another replica claiming the same todo meanwhile waits for it, then finds the todo taken.
+func (u *Usecase) ClaimReminders(ctx context.Context, req domain.TodoClaimRemindersRequest) (res domain.TodoRemindersResponse, err error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contentTimeout)
+	defer cancel()
+
+	req.Now = time.Now().UTC()
+	req.Until = req.Now.Add(req.Lease)
+
+	err = u.repo.Transactor.WithinTransaction(ctx, func(ctx context.Context) (err error) {
+		var claimed domain.TodoClaimRemindersResponse
+		claimed, err = u.repo.Store.ClaimReminders(ctx, req)
+		if err != nil {
+			return
+		}
+
+		res.Reminders = make([]domain.Reminder, len(claimed.Todos))
+		emails := map[int64]string{}
+		for i, todo := range claimed.Todos {
+			email, ok := emails[todo.ActivityGroupID]
+			if !ok {
+				if email, err = u.activities.Email(ctx, todo.ActivityGroupID); err != nil {
+					return
+				}
+				emails[todo.ActivityGroupID] = email
+			}
+
+			res.Reminders[i] = domain.Reminder{
+				Todo: todo,
+				Email: email,
+			}
+		}
+
+		return
+	})
+	if err != nil {
+		res = domain.TodoRemindersResponse{}
+		err = failure.Internal(err)
+		return
+	}
+
+	return
+}
+
+// RenewReminder extends the lease of a claimed todo, a dispatcher renews it before each send so that
+// a batch outlasting the lease it was claimed for does not hand its last todos to another replica
+func (u *Usecase) RenewReminder(ctx context.Context, req domain.TodoRenewReminderRequest) (res domain.TodoRenewReminderResponse, err error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contentTimeout)
+	defer cancel()
+
+	req.Until = time.Now().UTC().Add(req.Lease)
+
+	res, err = u.repo.Store.RenewReminder(ctx, req)
+	if err != nil {
+		err = failure.Internal(err)
+		return
+	}
+
+	return
+}
+
+// MarkReminded records a sent reminder, as long as the dispatcher still holds its claim
+func (u *Usecase) MarkReminded(ctx context.Context, req domain.TodoMarkRemindedRequest) (res domain.TodoMarkRemindedResponse, err error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contentTimeout)
+	defer cancel()
+
+	req.RemindedAt = time.Now().UTC()
+
+	res, err = u.repo.Store.MarkReminded(ctx, req)
+	if err != nil {
+		err = failure.Internal(err)
+		return
+	}
+
+	return
+}
+
+func (u *Usecase) GetOne(ctx context.Context, req domain.TodoGetOneRequest) (res domain.TodoGetOneResponse, err error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contentTimeout)
+	defer cancel()
+
+	res, err = u.repo.Store.GetOne(ctx, req)
+	if err != nil {
+		err = failure.Internal(err)
 		return
 	} else if res.ID == int64(constant.ZeroValue) {
-		c.Status(http.StatusNotFound).JSON(web.BaseResponse{
-			Status: http.StatusText(http.StatusNotFound),
-			Message: message.NotFound("Todo", "ID", fmt.Sprint(req.ID)),
-			Data: struct{}{},
-		})
-		err = fmt.Errorf(http.StatusText(http.StatusNotFound))
+		err = failure.NotFound(message.NotFound(domain.Model, "ID", fmt.Sprint(req.ID)))
 		return
 	}
 
 	return 
-}
\ No newline at end of file
+}
@@ -0,0 +1,1207 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/fahmiaz411/devcode/config/database"
+	"github.com/fahmiaz411/devcode/helper/failure"
+	"github.com/fahmiaz411/devcode/helper/field"
+	"github.com/fahmiaz411/devcode/helper/message"
+	"github.com/fahmiaz411/devcode/helper/pagination"
+	"github.com/fahmiaz411/devcode/modules/todo/domain"
+	"github.com/fahmiaz411/devcode/modules/todo/interfaces"
+	"github.com/fahmiaz411/devcode/modules/todo/repository"
+)
+
+// activities are the activity groups the todos of a test can be put in, by ID
+type activities map[int64]string
+
+func (a activities) Exists(ctx context.Context, id int64) (exists bool, err error) {
+	_, exists = a[id]
+	return
+}
+
+func (a activities) Email(ctx context.Context, id int64) (email string, err error) {
+	return a[id], nil
+}
+
+// newUsecase is a todo usecase on the memory driver, with activity groups 1 and 2
+func newUsecase() interfaces.TodoUsecase {
+	db := database.NewDatabase(database.Config{Driver: database.DriverMemory})
+
+	return NewUsecase(repository.NewRepository(db), time.Second, activities{1: "", 2: ""})
+}
+
+// checkErr fails unless err is a failure of kind with msg, or nil when kind is empty
+func checkErr(t *testing.T, name string, err error, kind failure.Kind, msg string) {
+	t.Helper()
+
+	if kind == "" {
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", name, err)
+		}
+		return
+	}
+
+	var e *failure.Error
+	if !errors.As(err, &e) {
+		t.Errorf("%s: error = %v, want %s %q", name, err, kind, msg)
+		return
+	}
+
+	if e.Kind != kind || e.Message != msg {
+		t.Errorf("%s: error = %s %q, want %s %q", name, e.Kind, e.Message, kind, msg)
+	}
+}
+
+// count is the number of live todos
+func count(t *testing.T, u interfaces.TodoUsecase) int {
+	t.Helper()
+
+	res, err := u.GetAll(context.Background(), domain.TodoGetAllRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return len(res.Todos)
+}
+
+// result is the expected outcome of one item of a batch
+type result struct {
+	kind failure.Kind
+	msg  string
+}
+
+func checkResults(t *testing.T, res domain.TodoBulkResponse, want []result) {
+	t.Helper()
+
+	if len(res.Results) != len(want) {
+		t.Fatalf("results = %d, want %d", len(res.Results), len(want))
+	}
+
+	for i, result := range res.Results {
+		checkErr(t, fmt.Sprintf("item %d", i), result.Err, want[i].kind, want[i].msg)
+	}
+}
+
+func TestBulkCreate(t *testing.T) {
+	ctx := context.Background()
+	due := time.Now().UTC().Add(time.Hour)
+	late := due.Add(time.Minute)
+
+	tests := []struct {
+		name    string
+		todos   []domain.TodoCreateRequest
+		err     result
+		results []result
+		created int
+	}{
+		{
+			name: "empty",
+			err:  result{failure.KindValidation, message.CannotEmpty("todo items")},
+		},
+		{
+			name:  "too many",
+			todos: make([]domain.TodoCreateRequest, domain.BulkLimit+1),
+			err:   result{failure.KindValidation, message.TooMany("todo items", domain.BulkLimit)},
+		},
+		{
+			name: "invalid items",
+			todos: []domain.TodoCreateRequest{
+				{Title: "a", ActivityGroupID: 1},
+				{ActivityGroupID: 1},
+				{Title: "c"},
+				{Title: "d", ActivityGroupID: 1, DueAt: &due, RemindAt: &late},
+			},
+			results: []result{
+				{},
+				{failure.KindValidation, message.CanotNull(field.Title)},
+				{failure.KindValidation, message.CanotNull(field.ActivityGroupID)},
+				{failure.KindValidation, message.MustBefore(field.RemindAt, field.DueAt)},
+			},
+		},
+		{
+			name: "missing activity group",
+			todos: []domain.TodoCreateRequest{
+				{Title: "a", ActivityGroupID: 1},
+				{Title: "b", ActivityGroupID: 9},
+				{Title: "c", ActivityGroupID: 9},
+			},
+			results: []result{
+				{},
+				{failure.KindValidation, message.NotFound(domain.ActivityModel, "ID", "9")},
+				{failure.KindValidation, message.NotFound(domain.ActivityModel, "ID", "9")},
+			},
+		},
+		{
+			name: "valid",
+			todos: []domain.TodoCreateRequest{
+				{Title: "a", ActivityGroupID: 1},
+				{Title: "b", ActivityGroupID: 2},
+				{Title: "c", ActivityGroupID: 1},
+			},
+			results: []result{{}, {}, {}},
+			created: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := newUsecase()
+
+			res, err := u.BulkCreate(ctx, domain.TodoBulkCreateRequest{Todos: tt.todos})
+			checkErr(t, "batch", err, tt.err.kind, tt.err.msg)
+			checkResults(t, res, tt.results)
+
+			// A single rejected item leaves the whole batch unwritten
+			if got := count(t, u); got != tt.created {
+				t.Errorf("created %d todos, want %d", got, tt.created)
+			}
+		})
+	}
+}
+
+// An activity group given in the body that does not exist is invalid input, not a missing resource
+func TestMissingActivityGroup(t *testing.T) {
+	ctx := context.Background()
+	u := newUsecase()
+	todo := seed(t, u, "a")[0]
+	want := message.NotFound(domain.ActivityModel, "ID", "9")
+
+	_, err := u.Create(ctx, domain.TodoCreateRequest{Title: "b", ActivityGroupID: 9})
+	checkErr(t, "create", err, failure.KindValidation, want)
+
+	// Named by the path, it is a missing resource
+	_, err = u.Create(ctx, domain.TodoCreateRequest{Title: "b", ActivityGroupID: 9, CheckActivity: true})
+	checkErr(t, "create in the path", err, failure.KindNotFound, want)
+
+	_, err = u.Update(ctx, domain.TodoUpdateRequest{ID: todo.ID, ActivityGroupID: 9})
+	checkErr(t, "update", err, failure.KindValidation, want)
+
+	// A missing todo is still not found
+	_, err = u.Update(ctx, domain.TodoUpdateRequest{ID: 99, ActivityGroupID: 9})
+	checkErr(t, "missing todo", err, failure.KindNotFound, message.NotFound(domain.Model, "ID", "99"))
+}
+
+func TestBulkCreatePositions(t *testing.T) {
+	u := newUsecase()
+
+	res, err := u.BulkCreate(context.Background(), domain.TodoBulkCreateRequest{Todos: []domain.TodoCreateRequest{
+		{Title: "a", ActivityGroupID: 1},
+		{Title: "b", ActivityGroupID: 2},
+		{Title: "c", ActivityGroupID: 1},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The todos follow each other at the end of their activity group, in the order of the batch
+	want := []int64{domain.PositionGap, domain.PositionGap, 2 * domain.PositionGap}
+	for i, result := range res.Results {
+		if result.Todo == nil {
+			t.Fatalf("item %d: no todo", i)
+		}
+
+		if result.Todo.Position != want[i] {
+			t.Errorf("item %d: position = %d, want %d", i, result.Todo.Position, want[i])
+		}
+	}
+}
+
+// seed creates todos named by title in activity group 1 and returns them in order
+func seed(t *testing.T, u interfaces.TodoUsecase, titles ...string) (todos []domain.Todo) {
+	t.Helper()
+
+	for _, title := range titles {
+		res, err := u.Create(context.Background(), domain.TodoCreateRequest{Title: title, ActivityGroupID: 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		todos = append(todos, res.Todo)
+	}
+
+	return
+}
+
+func TestBulkUpdate(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		todos   func(seeded []domain.Todo) []domain.TodoBulkUpdateItem
+		err     result
+		results []result
+		titles  []string
+	}{
+		{
+			name: "empty",
+			todos: func(seeded []domain.Todo) []domain.TodoBulkUpdateItem {
+				return nil
+			},
+			err:    result{failure.KindValidation, message.CannotEmpty("todo items")},
+			titles: []string{"a", "b"},
+		},
+		{
+			name: "missing and repeated IDs",
+			todos: func(seeded []domain.Todo) []domain.TodoBulkUpdateItem {
+				return []domain.TodoBulkUpdateItem{
+					{ID: seeded[0].ID, TodoUpdateRequest: domain.TodoUpdateRequest{Title: "x"}},
+					{TodoUpdateRequest: domain.TodoUpdateRequest{Title: "y"}},
+					{ID: seeded[0].ID, TodoUpdateRequest: domain.TodoUpdateRequest{Title: "z"}},
+				}
+			},
+			results: []result{
+				{},
+				{failure.KindValidation, message.InvalidId(domain.Model)},
+				{failure.KindValidation, message.Duplicate(domain.Model, "ID", fmt.Sprint(1))},
+			},
+			titles: []string{"a", "b"},
+		},
+		{
+			name: "invalid change",
+			todos: func(seeded []domain.Todo) []domain.TodoBulkUpdateItem {
+				return []domain.TodoBulkUpdateItem{
+					{ID: seeded[0].ID, TodoUpdateRequest: domain.TodoUpdateRequest{Title: "x"}},
+					{ID: seeded[1].ID, TodoUpdateRequest: domain.TodoUpdateRequest{AttachTags: []string{"t"}, DetachTags: []string{"t"}}},
+				}
+			},
+			results: []result{
+				{},
+				{failure.KindValidation, message.CannotCombine(field.AttachTags+"=t", field.DetachTags+"=t")},
+			},
+			titles: []string{"a", "b"},
+		},
+		{
+			name: "unknown todo, stale version and missing activity group",
+			todos: func(seeded []domain.Todo) []domain.TodoBulkUpdateItem {
+				return []domain.TodoBulkUpdateItem{
+					{ID: 99, TodoUpdateRequest: domain.TodoUpdateRequest{Title: "x"}},
+					{ID: seeded[0].ID, Version: seeded[0].Version + 1, TodoUpdateRequest: domain.TodoUpdateRequest{Title: "y"}},
+					{ID: seeded[1].ID, TodoUpdateRequest: domain.TodoUpdateRequest{ActivityGroupID: 9}},
+				}
+			},
+			results: []result{
+				{failure.KindNotFound, message.NotFound(domain.Model, "ID", "99")},
+				{failure.KindPrecondition, message.Modified(domain.Model, "ID", fmt.Sprint(1))},
+				{failure.KindValidation, message.NotFound(domain.ActivityModel, "ID", "9")},
+			},
+			titles: []string{"a", "b"},
+		},
+		{
+			name: "valid",
+			todos: func(seeded []domain.Todo) []domain.TodoBulkUpdateItem {
+				return []domain.TodoBulkUpdateItem{
+					{ID: seeded[1].ID, Version: seeded[1].Version, TodoUpdateRequest: domain.TodoUpdateRequest{Title: "y"}},
+					{ID: seeded[0].ID, TodoUpdateRequest: domain.TodoUpdateRequest{Title: "x"}},
+				}
+			},
+			results: []result{{}, {}},
+			titles:  []string{"x", "y"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := newUsecase()
+			seeded := seed(t, u, "a", "b")
+
+			res, err := u.BulkUpdate(ctx, domain.TodoBulkUpdateRequest{Todos: tt.todos(seeded)})
+			checkErr(t, "batch", err, tt.err.kind, tt.err.msg)
+			checkResults(t, res, tt.results)
+
+			for i, todo := range seeded {
+				got, err := u.GetOne(ctx, domain.TodoGetOneRequest{ID: todo.ID})
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if got.Title != tt.titles[i] {
+					t.Errorf("todo %d: title = %q, want %q", todo.ID, got.Title, tt.titles[i])
+				}
+			}
+		})
+	}
+}
+
+func TestBulkDelete(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		todos   func(seeded []domain.Todo) []domain.TodoBulkDeleteItem
+		err     result
+		results []result
+		left    int
+	}{
+		{
+			name: "too many",
+			todos: func(seeded []domain.Todo) []domain.TodoBulkDeleteItem {
+				return make([]domain.TodoBulkDeleteItem, domain.BulkLimit+1)
+			},
+			err:  result{failure.KindValidation, message.TooMany("todo items", domain.BulkLimit)},
+			left: 2,
+		},
+		{
+			name: "repeated ID",
+			todos: func(seeded []domain.Todo) []domain.TodoBulkDeleteItem {
+				return []domain.TodoBulkDeleteItem{{ID: seeded[1].ID}, {ID: seeded[1].ID}}
+			},
+			results: []result{
+				{},
+				{failure.KindValidation, message.Duplicate(domain.Model, "ID", fmt.Sprint(2))},
+			},
+			left: 2,
+		},
+		{
+			name: "stale version",
+			todos: func(seeded []domain.Todo) []domain.TodoBulkDeleteItem {
+				return []domain.TodoBulkDeleteItem{{ID: seeded[0].ID}, {ID: seeded[1].ID, Version: seeded[1].Version + 1}}
+			},
+			results: []result{
+				{},
+				{failure.KindPrecondition, message.Modified(domain.Model, "ID", fmt.Sprint(2))},
+			},
+			left: 2,
+		},
+		{
+			name: "valid",
+			todos: func(seeded []domain.Todo) []domain.TodoBulkDeleteItem {
+				return []domain.TodoBulkDeleteItem{{ID: seeded[0].ID, Version: seeded[0].Version}, {ID: seeded[1].ID}}
+			},
+			results: []result{{}, {}},
+			left:    0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := newUsecase()
+			seeded := seed(t, u, "a", "b")
+
+			res, err := u.BulkDelete(ctx, domain.TodoBulkDeleteRequest{Todos: tt.todos(seeded)})
+			checkErr(t, "batch", err, tt.err.kind, tt.err.msg)
+			checkResults(t, res, tt.results)
+
+			if got := count(t, u); got != tt.left {
+				t.Errorf("%d todos left, want %d", got, tt.left)
+			}
+		})
+	}
+}
+
+func TestMoveRenumber(t *testing.T) {
+	ctx := context.Background()
+	u := newUsecase()
+	seeded := seed(t, u, "a", "b", "c")
+	a := seeded[0]
+
+	// Moving b and c in turn right after a halves the gap each time, until the group is renumbered
+	moved := []int64{seeded[1].ID, seeded[2].ID}
+	for i := 0; i < 12; i++ {
+		id := moved[i%2]
+		if _, err := u.Move(ctx, domain.TodoMoveRequest{ID: id, After: &a.ID}); err != nil {
+			t.Fatalf("move %d: %v", i, err)
+		}
+	}
+
+	res, err := u.GetAll(ctx, domain.TodoGetAllRequest{ActivityGroupID: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	positions := map[int64]int64{}
+	for _, todo := range res.Todos {
+		positions[todo.ID] = todo.Position
+
+		// A todo the renumbering only shifted has a new version all the same
+		if todo.ID == a.ID && (todo.Version != a.Version+1 || !todo.UpdatedAt.After(a.UpdatedAt)) {
+			t.Errorf("todo %d: version = %d at %s, want %d after %s", todo.ID, todo.Version, todo.UpdatedAt, a.Version+1, a.UpdatedAt)
+		}
+	}
+
+	// The last move put c right after a, with b following
+	if !(positions[a.ID] < positions[moved[1]] && positions[moved[1]] < positions[moved[0]]) {
+		t.Errorf("positions = %v, want a < c < b", positions)
+	}
+
+	// Renumbered, the gap is back
+	if gap := positions[moved[1]] - positions[a.ID]; gap != domain.PositionGap/2 {
+		t.Errorf("gap after a = %d, want %d", gap, domain.PositionGap/2)
+	}
+}
+
+// unreachable fails every lookup of the emails of the activity groups
+type unreachable struct {
+	activities
+}
+
+func (unreachable) Email(ctx context.Context, id int64) (email string, err error) {
+	return "", errors.New("unreachable")
+}
+
+func TestClaimRemindersRollback(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewRepository(database.NewDatabase(database.Config{Driver: database.DriverMemory}))
+	u := NewUsecase(repo, time.Second, activities{1: "me@example.com"})
+
+	remindAt := time.Now().UTC().Add(-time.Minute)
+	if _, err := u.Create(ctx, domain.TodoCreateRequest{Title: "a", ActivityGroupID: 1, RemindAt: &remindAt}); err != nil {
+		t.Fatal(err)
+	}
+
+	req := domain.TodoClaimRemindersRequest{Claim: "first", Lease: time.Hour, Limit: 10}
+	failing := NewUsecase(repo, time.Second, unreachable{activities{1: ""}})
+	if _, err := failing.ClaimReminders(ctx, req); failure.KindOf(err) != failure.KindInternal {
+		t.Fatalf("error = %v, want an internal one", err)
+	}
+
+	// The failed claim left no lease behind, nor an attempt
+	req.Claim = "second"
+	res, err := u.ClaimReminders(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(res.Reminders) != 1 || res.Reminders[0].Email != "me@example.com" || res.Reminders[0].RemindAttempts != 1 {
+		t.Errorf("reminders = %+v, want the todo with the email of its group", res.Reminders)
+	}
+}
+
+func TestRenewReminder(t *testing.T) {
+	ctx := context.Background()
+	u := newUsecase()
+
+	remindAt := time.Now().UTC().Add(-time.Minute)
+	created, err := u.Create(ctx, domain.TodoCreateRequest{Title: "a", ActivityGroupID: 1, RemindAt: &remindAt})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = u.ClaimReminders(ctx, domain.TodoClaimRemindersRequest{Claim: "first", Lease: time.Hour, Limit: 10}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Only the worker holding the claim renews it
+	for _, tt := range []struct {
+		claim   string
+		renewed bool
+	}{
+		{claim: "second"},
+		{claim: "first", renewed: true},
+	} {
+		res, err := u.RenewReminder(ctx, domain.TodoRenewReminderRequest{ID: created.ID, Claim: tt.claim, Lease: time.Hour})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if res.Renewed != tt.renewed {
+			t.Errorf("%s: renewed = %t, want %t", tt.claim, res.Renewed, tt.renewed)
+		}
+	}
+
+	marked, err := u.MarkReminded(ctx, domain.TodoMarkRemindedRequest{ID: created.ID, Claim: "first"})
+	if err != nil || !marked.Marked {
+		t.Fatalf("marked = %t, %v", marked.Marked, err)
+	}
+
+	// A sent reminder is a new version of the todo, of a later update
+	todo, err := u.GetOne(ctx, domain.TodoGetOneRequest{ID: created.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if todo.RemindedAt == nil || todo.Version != created.Version+1 || !todo.UpdatedAt.Equal(*todo.RemindedAt) {
+		t.Errorf("todo = version %d at %s, reminded at %v", todo.Version, todo.UpdatedAt, todo.RemindedAt)
+	}
+
+	// Nothing left to renew once sent
+	if res, _ := u.RenewReminder(ctx, domain.TodoRenewReminderRequest{ID: created.ID, Claim: "first", Lease: time.Hour}); res.Renewed {
+		t.Error("renewed a sent reminder")
+	}
+}
+
+func TestReminderAttempts(t *testing.T) {
+	ctx := context.Background()
+	u := newUsecase()
+
+	remindAt := time.Now().UTC().Add(-time.Minute)
+	created, err := u.Create(ctx, domain.TodoCreateRequest{Title: "a", ActivityGroupID: 1, RemindAt: &remindAt})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// claim takes the reminder for a lease run out right away, as if every send failed
+	claim := func() []domain.Reminder {
+		t.Helper()
+
+		res, err := u.ClaimReminders(ctx, domain.TodoClaimRemindersRequest{Claim: "worker", Lease: -time.Second, Limit: 10})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return res.Reminders
+	}
+
+	for i := 1; i <= domain.RemindAttemptsMax; i++ {
+		reminders := claim()
+		if len(reminders) != 1 || reminders[0].RemindAttempts != int64(i) {
+			t.Fatalf("attempt %d: reminders = %+v", i, reminders)
+		}
+	}
+
+	// Given up past the last attempt
+	if reminders := claim(); len(reminders) != 0 {
+		t.Fatalf("claimed %d reminders after the last attempt", len(reminders))
+	}
+
+	// A new reminder time has every attempt ahead of it
+	if _, err = u.Update(ctx, domain.TodoUpdateRequest{ID: created.ID, RemindAt: domain.NullTime{Set: true, Time: &remindAt}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if reminders := claim(); len(reminders) != 1 || reminders[0].RemindAttempts != 1 {
+		t.Errorf("reminders after a new reminder time = %+v", reminders)
+	}
+}
+
+func TestCreateRecurrence(t *testing.T) {
+	ctx := context.Background()
+	due := time.Date(2030, time.January, 31, 9, 0, 0, 0, time.UTC)
+	until := due.AddDate(0, 6, 0)
+
+	tests := []struct {
+		name       string
+		recurrence domain.Recurrence
+		kind       failure.Kind
+		msg        string
+		want       domain.Recurrence
+	}{
+		{name: "unknown frequency", recurrence: domain.Recurrence{Frequency: "yearly"}, kind: failure.KindValidation, msg: message.ShoudMatchEnum(field.Frequency, domain.FrequencyAllList)},
+		{name: "negative interval", recurrence: domain.Recurrence{Frequency: domain.FrequencyDaily, Interval: -1}, kind: failure.KindValidation, msg: message.Between(field.Interval, 1, domain.IntervalMax)},
+		{name: "interval too long", recurrence: domain.Recurrence{Frequency: domain.FrequencyDaily, Interval: domain.IntervalMax + 1}, kind: failure.KindValidation, msg: message.Between(field.Interval, 1, domain.IntervalMax)},
+		{name: "weekdays of a daily recurrence", recurrence: domain.Recurrence{Frequency: domain.FrequencyDaily, Weekdays: []string{"MO"}}, kind: failure.KindValidation, msg: message.CannotCombine(field.ByWeekday, field.Frequency+"="+domain.FrequencyDaily)},
+		{name: "unknown weekday", recurrence: domain.Recurrence{Frequency: domain.FrequencyWeekly, Weekdays: []string{"MO", "XX"}}, kind: failure.KindValidation, msg: message.ShoudMatchEnum(field.ByWeekday, domain.WeekdayAllList)},
+		{name: "month day of a weekly recurrence", recurrence: domain.Recurrence{Frequency: domain.FrequencyWeekly, MonthDay: 3}, kind: failure.KindValidation, msg: message.CannotCombine(field.ByMonthDay, field.Frequency+"="+domain.FrequencyWeekly)},
+		{name: "month day out of range", recurrence: domain.Recurrence{Frequency: domain.FrequencyMonthly, MonthDay: 32}, kind: failure.KindValidation, msg: message.Between(field.ByMonthDay, 1, 31)},
+		{name: "negative count", recurrence: domain.Recurrence{Frequency: domain.FrequencyDaily, Count: -1}, kind: failure.KindValidation, msg: message.CannotNegative(field.Count)},
+		{name: "count and until", recurrence: domain.Recurrence{Frequency: domain.FrequencyDaily, Count: 2, Until: &until}, kind: failure.KindValidation, msg: message.CannotCombine(field.Count, field.Until)},
+		{name: "interval defaults to 1", recurrence: domain.Recurrence{Frequency: domain.FrequencyWeekly, Weekdays: []string{"MO"}}, want: domain.Recurrence{Frequency: domain.FrequencyWeekly, Interval: 1, Weekdays: []string{"MO"}}},
+		{name: "monthly on the day of the due date", recurrence: domain.Recurrence{Frequency: domain.FrequencyMonthly, Interval: 2, Count: 4}, want: domain.Recurrence{Frequency: domain.FrequencyMonthly, Interval: 2, MonthDay: 31, Count: 4}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := newUsecase()
+
+			recurrence := tt.recurrence
+			created, err := u.Create(ctx, domain.TodoCreateRequest{Title: "a", ActivityGroupID: 1, DueAt: &due, Recurrence: &recurrence})
+			checkErr(t, tt.name, err, tt.kind, tt.msg)
+			if tt.kind != "" {
+				if n := count(t, u); n != 0 {
+					t.Errorf("%d todos created, want none", n)
+				}
+				return
+			}
+
+			todo, err := u.GetOne(ctx, domain.TodoGetOneRequest{ID: created.ID})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if todo.Recurrence == nil || todo.Recurrence.String() != tt.want.String() {
+				t.Errorf("recurrence = %v, want %s", todo.Recurrence, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompleteRecurring(t *testing.T) {
+	ctx := context.Background()
+	u := newUsecase()
+	due := time.Date(2030, time.January, 31, 9, 0, 0, 0, time.UTC)
+	remindAt := due.Add(-time.Hour)
+
+	created, err := u.Create(ctx, domain.TodoCreateRequest{
+		Title:           "Pay rent",
+		ActivityGroupID: 1,
+		DueAt:           &due,
+		RemindAt:        &remindAt,
+		Recurrence:      &domain.Recurrence{Frequency: domain.FrequencyMonthly, Count: 2},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = u.CreateChecklistItem(ctx, domain.ChecklistCreateRequest{TodoID: created.ID, Title: "Transfer"}); err != nil {
+		t.Fatal(err)
+	}
+
+	done := false
+	completed, err := u.Update(ctx, domain.TodoUpdateRequest{ID: created.ID, IsActive: &done})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if completed.NextID == nil {
+		t.Fatal("completing the first occurrence created no next one")
+	}
+
+	next, err := u.GetOne(ctx, domain.TodoGetOneRequest{ID: *completed.NextID})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The due date follows the schedule into the shorter month, the reminder moves along
+	wantDue, wantRemind := time.Date(2030, time.February, 28, 9, 0, 0, 0, time.UTC), time.Date(2030, time.February, 28, 8, 0, 0, 0, time.UTC)
+	if next.DueAt == nil || !next.DueAt.Equal(wantDue) {
+		t.Errorf("dueAt = %v, want %s", next.DueAt, wantDue)
+	}
+
+	if next.RemindAt == nil || !next.RemindAt.Equal(wantRemind) {
+		t.Errorf("remindAt = %v, want %s", next.RemindAt, wantRemind)
+	}
+
+	if !next.IsActive || next.Occurrence != 2 || next.Title != "Pay rent" {
+		t.Errorf("next = %+v, want the active second occurrence of Pay rent", next.Todo)
+	}
+
+	// The checklist starts over, the completed one is checked off
+	for id, want := range map[int64]bool{created.ID: true, next.ID: false} {
+		checklist, err := u.GetChecklist(ctx, domain.ChecklistGetAllRequest{TodoID: id})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(checklist.Items) != 1 || checklist.Items[0].IsDone != want {
+			t.Errorf("todo %d: checklist = %+v, want one item done %t", id, checklist.Items, want)
+		}
+	}
+
+	// Reopened and completed again, the first occurrence does not come back twice
+	open := true
+	if _, err = u.Update(ctx, domain.TodoUpdateRequest{ID: created.ID, IsActive: &open}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = u.Update(ctx, domain.TodoUpdateRequest{ID: created.ID, IsActive: &done}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The second occurrence is the last of the count
+	last, err := u.Update(ctx, domain.TodoUpdateRequest{ID: next.ID, IsActive: &done})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if last.NextID != nil {
+		t.Errorf("nextId = %d, want none past the count", *last.NextID)
+	}
+
+	res, err := u.GetAll(ctx, domain.TodoGetAllRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(res.Todos) != 2 {
+		t.Errorf("todos = %d, want the 2 occurrences", len(res.Todos))
+	}
+}
+
+func TestMoveChecklistItem(t *testing.T) {
+	ctx := context.Background()
+	u := newUsecase()
+	todo := seed(t, u, "a")[0]
+
+	items := []int64{}
+	for _, title := range []string{"first", "second"} {
+		item, err := u.CreateChecklistItem(ctx, domain.ChecklistCreateRequest{TodoID: todo.ID, Title: title, IsDone: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		items = append(items, item.ID)
+	}
+
+	done := false
+	before, err := u.Update(ctx, domain.TodoUpdateRequest{ID: todo.ID, IsActive: &done})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = u.MoveChecklistItem(ctx, domain.ChecklistMoveRequest{TodoID: todo.ID, ID: items[1], Before: &items[0]}); err != nil {
+		t.Fatal(err)
+	}
+
+	checklist, err := u.GetChecklist(ctx, domain.ChecklistGetAllRequest{TodoID: todo.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(checklist.Items) != 2 || checklist.Items[0].ID != items[1] {
+		t.Errorf("checklist = %+v, want second first", checklist.Items)
+	}
+
+	// The move makes a new version of the todo, still completed
+	after, err := u.GetOne(ctx, domain.TodoGetOneRequest{ID: todo.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if after.Version != before.Version+1 {
+		t.Errorf("version = %d, want %d", after.Version, before.Version+1)
+	}
+
+	if after.UpdatedAt.Before(before.UpdatedAt) {
+		t.Errorf("updatedAt = %s, before %s", after.UpdatedAt, before.UpdatedAt)
+	}
+
+	if after.IsActive {
+		t.Error("the move reopened the todo")
+	}
+}
+
+func TestTrash(t *testing.T) {
+	ctx := context.Background()
+	u := newUsecase()
+	todos := seed(t, u, "a", "b")
+	notFound := message.NotFound(domain.Model, "ID", "1")
+
+	trash := func() (ids []int64) {
+		t.Helper()
+
+		res, err := u.GetAll(ctx, domain.TodoGetAllRequest{Trashed: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, todo := range res.Todos {
+			ids = append(ids, todo.ID)
+		}
+
+		return
+	}
+
+	// Only a todo in the trash is restored or purged
+	_, err := u.Restore(ctx, domain.TodoRestoreRequest{ID: todos[0].ID})
+	checkErr(t, "restore live", err, failure.KindNotFound, notFound)
+
+	_, err = u.Purge(ctx, domain.TodoPurgeRequest{ID: todos[0].ID})
+	checkErr(t, "purge live", err, failure.KindNotFound, notFound)
+
+	for _, todo := range todos {
+		if _, err = u.Delete(ctx, domain.TodoDeleteRequest{ID: todo.ID}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if ids := trash(); len(ids) != 2 || count(t, u) != 0 {
+		t.Fatalf("trash = %v, live = %d", ids, count(t, u))
+	}
+
+	res, err := u.Restore(ctx, domain.TodoRestoreRequest{ID: todos[0].ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res.DeletedAt != nil || res.Version != todos[0].Version+2 {
+		t.Errorf("restored todo = %+v", res.Todo)
+	}
+
+	_, err = u.Restore(ctx, domain.TodoRestoreRequest{ID: todos[0].ID})
+	checkErr(t, "restore again", err, failure.KindNotFound, notFound)
+
+	if _, err = u.Purge(ctx, domain.TodoPurgeRequest{ID: todos[1].ID}); err != nil {
+		t.Fatal(err)
+	}
+
+	if ids := trash(); len(ids) != 0 || count(t, u) != 1 {
+		t.Errorf("trash = %v, live = %d, want none and 1", ids, count(t, u))
+	}
+}
+
+// A list, count or create under an activity group named by the path is NotFound when the group is missing
+func TestActivityScope(t *testing.T) {
+	ctx := context.Background()
+	u := newUsecase()
+	seed(t, u, "a", "b")
+	if _, err := u.Create(ctx, domain.TodoCreateRequest{Title: "c", ActivityGroupID: 2, CheckActivity: true}); err != nil {
+		t.Fatal(err)
+	}
+	notFound := message.NotFound(domain.ActivityModel, "ID", "9")
+
+	res, err := u.GetAll(ctx, domain.TodoGetAllRequest{ActivityGroupID: 2, CheckActivity: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(res.Todos) != 1 || res.Todos[0].Title != "c" {
+		t.Errorf("todos of group 2 = %+v, want c", res.Todos)
+	}
+
+	stamp, err := u.GetAllStamp(ctx, domain.TodoGetAllRequest{ActivityGroupID: 1, CheckActivity: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if stamp.Count != 2 {
+		t.Errorf("count of group 1 = %d, want 2", stamp.Count)
+	}
+
+	_, err = u.GetAll(ctx, domain.TodoGetAllRequest{ActivityGroupID: 9, CheckActivity: true})
+	checkErr(t, "list", err, failure.KindNotFound, notFound)
+
+	_, err = u.GetAllStamp(ctx, domain.TodoGetAllRequest{ActivityGroupID: 9, CheckActivity: true})
+	checkErr(t, "count", err, failure.KindNotFound, notFound)
+
+	_, err = u.Create(ctx, domain.TodoCreateRequest{Title: "d", ActivityGroupID: 9, CheckActivity: true})
+	checkErr(t, "create", err, failure.KindNotFound, notFound)
+
+	// Filtered by the query instead, the list is just empty
+	if res, err = u.GetAll(ctx, domain.TodoGetAllRequest{ActivityGroupID: 9}); err != nil || len(res.Todos) != 0 {
+		t.Errorf("filtered by group 9 = %d todos, %v", len(res.Todos), err)
+	}
+}
+
+func TestStats(t *testing.T) {
+	ctx := context.Background()
+	today := time.Now().UTC().Format(time.DateOnly)
+
+	tests := []struct {
+		name string
+		// seed puts the todos of the test in
+		seed            func(t *testing.T, u interfaces.TodoUsecase)
+		activityGroupID int64
+		total, active   int64
+		created         int64
+		completed       int64
+	}{
+		{name: "empty", seed: func(t *testing.T, u interfaces.TodoUsecase) {}},
+		{
+			name: "soft deleted",
+			seed: func(t *testing.T, u interfaces.TodoUsecase) {
+				for _, todo := range seed(t, u, "a", "b") {
+					if _, err := u.Delete(ctx, domain.TodoDeleteRequest{ID: todo.ID}); err != nil {
+						t.Fatal(err)
+					}
+				}
+			},
+		},
+		{
+			name: "live",
+			seed: func(t *testing.T, u interfaces.TodoUsecase) {
+				todos := seed(t, u, "a", "b", "c")
+
+				done := false
+				if _, err := u.Update(ctx, domain.TodoUpdateRequest{ID: todos[1].ID, IsActive: &done}); err != nil {
+					t.Fatal(err)
+				}
+
+				if _, err := u.Delete(ctx, domain.TodoDeleteRequest{ID: todos[2].ID}); err != nil {
+					t.Fatal(err)
+				}
+
+				if _, err := u.Create(ctx, domain.TodoCreateRequest{Title: "d", ActivityGroupID: 2}); err != nil {
+					t.Fatal(err)
+				}
+			},
+			activityGroupID: 1,
+			total:           2,
+			active:          1,
+			created:         2,
+			completed:       1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := newUsecase()
+			tt.seed(t, u)
+
+			res, err := u.Stats(ctx, domain.TodoStatsRequest{ActivityGroupID: tt.activityGroupID})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if res.Total != tt.total || res.Active != tt.active || res.Completed != tt.total-tt.active {
+				t.Errorf("counts = %d total, %d active, %d completed", res.Total, res.Active, res.Completed)
+			}
+
+			// Every priority, at zero when none has it
+			if len(res.ByPriority) != len(domain.PriorityAllList) || res.ByPriority[domain.PriorityDefault] != tt.total {
+				t.Errorf("by priority = %v", res.ByPriority)
+			}
+
+			if (res.AvgCompletionSeconds != nil) != (tt.completed != 0) {
+				t.Errorf("average completion = %v", res.AvgCompletionSeconds)
+			}
+
+			// The default range ends today, a day for each
+			if len(res.Days) != domain.StatsDaysDefault || res.To != today || res.Days[len(res.Days)-1].Date != today || res.Days[0].Date != res.From {
+				t.Fatalf("days = %s to %s, %d of them", res.From, res.To, len(res.Days))
+			}
+
+			if last := res.Days[len(res.Days)-1]; last.Created != tt.created || last.Completed != tt.completed {
+				t.Errorf("today = %+v, want %d created and %d completed", last, tt.created, tt.completed)
+			}
+		})
+	}
+}
+
+func TestStatsRange(t *testing.T) {
+	ctx := context.Background()
+	u := newUsecase()
+	day := func(d int) *time.Time {
+		at := time.Date(2030, time.January, d, 0, 0, 0, 0, time.UTC)
+		return &at
+	}
+
+	// To is the end of a day, the range has both
+	res, err := u.Stats(ctx, domain.TodoStatsRequest{From: day(1), To: day(3)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res.From != "2030-01-01" || res.To != "2030-01-02" || len(res.Days) != 2 {
+		t.Errorf("days = %s to %s, %d of them", res.From, res.To, len(res.Days))
+	}
+
+	_, err = u.Stats(ctx, domain.TodoStatsRequest{From: day(3), To: day(3)})
+	checkErr(t, "empty", err, failure.KindValidation, message.MustBefore(field.From, field.To))
+
+	long := day(1).AddDate(0, 0, -domain.StatsDaysMax)
+	_, err = u.Stats(ctx, domain.TodoStatsRequest{From: &long, To: day(2)})
+	checkErr(t, "too long", err, failure.KindValidation, message.RangeTooLong(field.From, field.To, domain.StatsDaysMax))
+}
+
+func TestNarrowDue(t *testing.T) {
+	// 2030-01-13 is a Sunday, the last day of the week from Monday 2030-01-07
+	at := func(d, hour int) *time.Time {
+		t := time.Date(2030, time.January, d, hour, 30, 0, 0, time.UTC)
+		return &t
+	}
+	day := func(d int) *time.Time {
+		t := time.Date(2030, time.January, d, 0, 0, 0, 0, time.UTC)
+		return &t
+	}
+	active, done := true, false
+
+	tests := []struct {
+		name     string
+		now      *time.Time
+		req      domain.TodoGetAllRequest
+		from, to *time.Time
+		err      result
+	}{
+		{name: "overdue", now: at(13, 23), req: domain.TodoGetAllRequest{Due: domain.DueOverdue}, to: at(13, 23)},
+		{name: "overdue, active", now: at(13, 23), req: domain.TodoGetAllRequest{Due: domain.DueOverdue, IsActive: &active}, to: at(13, 23)},
+		{name: "overdue, done", now: at(13, 23), req: domain.TodoGetAllRequest{Due: domain.DueOverdue, IsActive: &done}, err: result{failure.KindValidation, message.CannotCombine(field.Due+"="+domain.DueOverdue, field.IsActive+"=false")}},
+		{name: "today", now: at(13, 23), req: domain.TodoGetAllRequest{Due: domain.DueToday}, from: day(13), to: day(14)},
+		{name: "week, on a Sunday", now: at(13, 23), req: domain.TodoGetAllRequest{Due: domain.DueWeek}, from: day(7), to: day(14)},
+		{name: "week, at the start of Monday", now: day(14), req: domain.TodoGetAllRequest{Due: domain.DueWeek}, from: day(14), to: day(21)},
+		{name: "week, on a Wednesday", now: at(16, 9), req: domain.TodoGetAllRequest{Due: domain.DueWeek}, from: day(14), to: day(21)},
+		{name: "week, tighter bounds kept", now: at(16, 9), req: domain.TodoGetAllRequest{Due: domain.DueWeek, DueFrom: day(15), DueTo: day(30)}, from: day(15), to: day(21)},
+		{name: "unknown", now: at(13, 23), req: domain.TodoGetAllRequest{Due: "tomorrow"}, err: result{failure.KindValidation, message.ShoudMatchEnum(field.Due, domain.DueAllList)}},
+	}
+
+	equal := func(got, want *time.Time) bool {
+		return got == nil && want == nil || got != nil && want != nil && got.Equal(*want)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := tt.req
+			err := narrowDue(&req, *tt.now)
+			checkErr(t, tt.name, err, tt.err.kind, tt.err.msg)
+			if err != nil {
+				return
+			}
+
+			if !equal(req.DueFrom, tt.from) || !equal(req.DueTo, tt.to) {
+				t.Errorf("due = %v to %v, want %v to %v", req.DueFrom, req.DueTo, tt.from, tt.to)
+			}
+
+			// Overdue are the active todos only
+			if tt.req.Due == domain.DueOverdue && (req.IsActive == nil || !*req.IsActive) {
+				t.Errorf("isActive = %v, want true", req.IsActive)
+			}
+		})
+	}
+}
+
+func TestGetAllDue(t *testing.T) {
+	ctx := context.Background()
+	u := newUsecase()
+	now := time.Now().UTC()
+	yesterday, later := now.AddDate(0, 0, -1), now.AddDate(0, 0, 30)
+
+	done := false
+	for _, req := range []domain.TodoCreateRequest{
+		{Title: "late", DueAt: &yesterday},
+		{Title: "late, done", DueAt: &yesterday, IsActive: &done},
+		{Title: "undated"},
+		{Title: "later", DueAt: &later},
+	} {
+		req.ActivityGroupID = 1
+		if _, err := u.Create(ctx, req); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	list := func(req domain.TodoGetAllRequest) (titles []string) {
+		t.Helper()
+
+		res, err := u.GetAll(ctx, req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, todo := range res.Todos {
+			titles = append(titles, todo.Title)
+		}
+
+		return
+	}
+
+	if titles := list(domain.TodoGetAllRequest{Due: domain.DueOverdue}); fmt.Sprint(titles) != "[late]" {
+		t.Errorf("overdue = %v, want late", titles)
+	}
+
+	// The undated todos last, either way
+	tests := []struct {
+		order  string
+		titles string
+	}{
+		{order: pagination.OrderAsc, titles: "[late late, done later undated]"},
+		{order: pagination.OrderDesc, titles: "[undated later late, done late]"},
+	}
+
+	for _, tt := range tests {
+		req := domain.TodoGetAllRequest{Request: pagination.Request{Sort: domain.SortDueAt, Order: tt.order}}
+		if titles := list(req); fmt.Sprint(titles) != tt.titles {
+			t.Errorf("by due %s = %v, want %s", tt.order, titles, tt.titles)
+		}
+	}
+}
+
+func TestTags(t *testing.T) {
+	ctx := context.Background()
+	u := newUsecase()
+	todo := seed(t, u, "a")[0]
+
+	work, err := u.CreateTag(ctx, domain.TagCreateRequest{Name: " Work "})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if work.Name != "work" {
+		t.Errorf("name = %q, want work", work.Name)
+	}
+
+	// Names are unique once trimmed and lower cased
+	_, err = u.CreateTag(ctx, domain.TagCreateRequest{Name: "WORK"})
+	checkErr(t, "duplicate", err, failure.KindConflict, message.AlreadyExists(domain.TagModel, "name", "work"))
+
+	_, err = u.CreateTag(ctx, domain.TagCreateRequest{Name: "  "})
+	checkErr(t, "blank", err, failure.KindValidation, message.CanotNull(field.Name))
+
+	home, err := u.CreateTag(ctx, domain.TagCreateRequest{Name: "home"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// getTodo reads the todo back, failing the test otherwise
+	getTodo := func() domain.Todo {
+		t.Helper()
+
+		res, err := u.GetOne(ctx, domain.TodoGetOneRequest{ID: todo.ID})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return res.Todo
+	}
+
+	// A change of the tags alone is a new version of the todo
+	if _, err = u.Update(ctx, domain.TodoUpdateRequest{ID: todo.ID, AttachTags: []string{"Work", "home"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	got := getTodo()
+	if fmt.Sprint(got.Tags) != "[home work]" || got.Version != todo.Version+1 {
+		t.Fatalf("todo = tags %v at version %d", got.Tags, got.Version)
+	}
+
+	_, err = u.Update(ctx, domain.TodoUpdateRequest{ID: todo.ID, AttachTags: []string{"errands"}})
+	checkErr(t, "missing tag", err, failure.KindNotFound, message.NotFound(domain.TagModel, "name", "errands"))
+
+	_, err = u.Update(ctx, domain.TodoUpdateRequest{ID: todo.ID, AttachTags: []string{"home"}, DetachTags: []string{"home"}})
+	checkErr(t, "attach and detach", err, failure.KindValidation, message.CannotCombine(field.AttachTags+"=home", field.DetachTags+"=home"))
+
+	// The tag filter keeps the todos with any of the tags
+	other := seed(t, u, "b")[0]
+	for _, tt := range []struct {
+		tags []string
+		want int
+	}{
+		{tags: []string{"work"}, want: 1},
+		{tags: []string{"errands", "home"}, want: 1},
+		{tags: []string{"errands"}, want: 0},
+	} {
+		res, err := u.GetAll(ctx, domain.TodoGetAllRequest{Tags: tt.tags})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(res.Todos) != tt.want || tt.want == 1 && res.Todos[0].ID != todo.ID {
+			t.Errorf("tagged %v = %d todos, want %d", tt.tags, len(res.Todos), tt.want)
+		}
+	}
+
+	// Renamed, the todos carrying the tag list the new name in a new version, the others are left alone
+	before := getTodo()
+	if _, err = u.UpdateTag(ctx, domain.TagUpdateRequest{ID: work.ID, Name: "Office"}); err != nil {
+		t.Fatal(err)
+	}
+
+	got = getTodo()
+	if fmt.Sprint(got.Tags) != "[home office]" || got.Version != before.Version+1 || !got.UpdatedAt.After(before.UpdatedAt) {
+		t.Errorf("renamed: todo = tags %v at version %d", got.Tags, got.Version)
+	}
+
+	if res, _ := u.GetOne(ctx, domain.TodoGetOneRequest{ID: other.ID}); res.Version != other.Version {
+		t.Errorf("untagged todo at version %d, want %d", res.Version, other.Version)
+	}
+
+	_, err = u.UpdateTag(ctx, domain.TagUpdateRequest{ID: work.ID, Name: "home"})
+	checkErr(t, "rename to a taken name", err, failure.KindConflict, message.AlreadyExists(domain.TagModel, "name", "home"))
+
+	// Deleted, it is detached from its todos in a new version
+	before = getTodo()
+	if _, err = u.DeleteTag(ctx, domain.TagDeleteRequest{ID: home.ID}); err != nil {
+		t.Fatal(err)
+	}
+
+	got = getTodo()
+	if fmt.Sprint(got.Tags) != "[office]" || got.Version != before.Version+1 {
+		t.Errorf("deleted: todo = tags %v at version %d", got.Tags, got.Version)
+	}
+
+	_, err = u.GetOneTag(ctx, domain.TagGetOneRequest{ID: home.ID})
+	checkErr(t, "deleted tag", err, failure.KindNotFound, message.NotFound(domain.TagModel, "ID", fmt.Sprint(home.ID)))
+
+	_, err = u.DeleteTag(ctx, domain.TagDeleteRequest{ID: home.ID})
+	checkErr(t, "deleted twice", err, failure.KindNotFound, message.NotFound(domain.TagModel, "ID", fmt.Sprint(home.ID)))
+
+	// Detached, the todo carries no tag
+	if _, err = u.Update(ctx, domain.TodoUpdateRequest{ID: todo.ID, DetachTags: []string{"office"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if got = getTodo(); len(got.Tags) != 0 {
+		t.Errorf("detached: tags = %v, want none", got.Tags)
+	}
+}
@@ -0,0 +1,138 @@
+package worker
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/fahmiaz411/devcode/modules/todo/domain"
+	"github.com/fahmiaz411/devcode/modules/todo/interfaces"
+)
+
+// ReminderWorker sends the due reminders through Notifier, every replica runs one.
+// A todo is claimed by a single worker for Lease, a reminder that failed or whose worker died is sent once the lease ran out.
+// The lease is renewed before each send, which is given up once the lease runs out, so that no send outlives its lease
+// however long the batch takes. A reminder failing domain.RemindAttemptsMax times is not sent again until its time changes.
+type ReminderWorker struct {
+	Usecase  interfaces.TodoUsecase
+	Notifier interfaces.Notifier
+	Interval time.Duration
+	Lease    time.Duration
+	Batch    int
+
+	// Claim names the worker among the replicas
+	Claim string
+
+	// Totals since start
+	Sent   atomic.Int64
+	Failed atomic.Int64
+}
+
+func NewReminderWorker(usecase interfaces.TodoUsecase, notifier interfaces.Notifier, interval, lease time.Duration, batch int) *ReminderWorker {
+	return &ReminderWorker{
+		Usecase:  usecase,
+		Notifier: notifier,
+		Interval: interval,
+		Lease:    lease,
+		Batch:    batch,
+		Claim:    claim(),
+	}
+}
+
+// claim is the host, the process and a random part, unique across replicas and restarts
+func claim() string {
+	host, _ := os.Hostname()
+
+	b := make([]byte, 4)
+	rand.Read(b)
+
+	return fmt.Sprintf("%.40s-%d-%s", host, os.Getpid(), hex.EncodeToString(b))
+}
+
+// Start runs a dispatch right away then every Interval, until ctx is done
+func (w *ReminderWorker) Start(ctx context.Context) {
+	ticker := time.NewTicker(w.Interval)
+	defer ticker.Stop()
+
+	for {
+		w.Run(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Run claims a batch of due reminders, sends them and logs the outcome when there was any
+func (w *ReminderWorker) Run(ctx context.Context) {
+	res, err := w.Usecase.ClaimReminders(ctx, domain.TodoClaimRemindersRequest{
+		Claim: w.Claim,
+		Lease: w.Lease,
+		Limit: w.Batch,
+	})
+	if err != nil {
+		log.Printf("reminder: claim failed: %v", err)
+		return
+	}
+
+	if len(res.Reminders) == 0 {
+		return
+	}
+
+	var sent, failed int64
+	for _, reminder := range res.Reminders {
+		renewed, err := w.Usecase.RenewReminder(ctx, domain.TodoRenewReminderRequest{
+			ID:    reminder.ID,
+			Claim: w.Claim,
+			Lease: w.Lease,
+		})
+		if err != nil {
+			log.Printf("reminder: todo %d not renewed, it is sent once its lease ran out: %v", reminder.ID, err)
+			failed++
+			continue
+		}
+
+		if !renewed.Renewed {
+			log.Printf("reminder: todo %d taken by another worker once its lease ran out", reminder.ID)
+			continue
+		}
+
+		if err := w.notify(ctx, reminder); err != nil {
+			log.Printf("reminder: %v", err)
+			if reminder.RemindAttempts >= domain.RemindAttemptsMax {
+				log.Printf("reminder: todo %d failed %d times, given up until its remind_at changes", reminder.ID, reminder.RemindAttempts)
+			}
+			failed++
+			continue
+		}
+
+		marked, err := w.Usecase.MarkReminded(ctx, domain.TodoMarkRemindedRequest{
+			ID:    reminder.ID,
+			Claim: w.Claim,
+		})
+		if err != nil {
+			log.Printf("reminder: todo %d sent but not marked, it may be sent again: %v", reminder.ID, err)
+		} else if !marked.Marked {
+			log.Printf("reminder: todo %d sent after its lease ran out, it may be sent again", reminder.ID)
+		}
+		sent++
+	}
+
+	log.Printf("reminder: sent %d and failed %d of %d due reminders (total %d and %d)",
+		sent, failed, len(res.Reminders), w.Sent.Add(sent), w.Failed.Add(failed))
+}
+
+// notify sends a reminder within the lease just renewed for it
+func (w *ReminderWorker) notify(ctx context.Context, reminder domain.Reminder) error {
+	ctx, cancel := context.WithTimeout(ctx, w.Lease)
+	defer cancel()
+
+	return w.Notifier.Notify(ctx, reminder)
+}
@@ -0,0 +1,126 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/fahmiaz411/devcode/config/database"
+	"github.com/fahmiaz411/devcode/modules/todo/domain"
+	"github.com/fahmiaz411/devcode/modules/todo/interfaces"
+	"github.com/fahmiaz411/devcode/modules/todo/repository"
+	"github.com/fahmiaz411/devcode/modules/todo/usecase"
+)
+
+// activities is a lookup of the activity groups by ID to their email
+type activities map[int64]string
+
+func (a activities) Exists(ctx context.Context, id int64) (exists bool, err error) {
+	_, exists = a[id]
+	return
+}
+
+func (a activities) Email(ctx context.Context, id int64) (email string, err error) {
+	return a[id], nil
+}
+
+// notifier records the todos it was given, and runs send on each of them when set
+type notifier struct {
+	sent []int64
+	send func(reminder domain.Reminder) error
+}
+
+func (n *notifier) Notify(ctx context.Context, reminder domain.Reminder) error {
+	n.sent = append(n.sent, reminder.ID)
+	if n.send == nil {
+		return nil
+	}
+
+	return n.send(reminder)
+}
+
+// newUsecase is a todo usecase on the memory driver with a due reminder for each title
+func newUsecase(t *testing.T, titles ...string) interfaces.TodoUsecase {
+	t.Helper()
+
+	db := database.NewDatabase(database.Config{Driver: database.DriverMemory})
+	u := usecase.NewUsecase(repository.NewRepository(db), time.Second, activities{1: "me@example.com"})
+
+	remindAt := time.Now().UTC().Add(-time.Minute)
+	for _, title := range titles {
+		if _, err := u.Create(context.Background(), domain.TodoCreateRequest{Title: title, ActivityGroupID: 1, RemindAt: &remindAt}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return u
+}
+
+func TestRun(t *testing.T) {
+	ctx := context.Background()
+	u := newUsecase(t, "a", "b")
+	n := &notifier{}
+	w := NewReminderWorker(u, n, time.Minute, time.Hour, 10)
+
+	// Sent once, then marked
+	w.Run(ctx)
+	w.Run(ctx)
+
+	if len(n.sent) != 2 || w.Sent.Load() != 2 || w.Failed.Load() != 0 {
+		t.Fatalf("sent %v, %d sent and %d failed", n.sent, w.Sent.Load(), w.Failed.Load())
+	}
+
+	todo, err := u.GetOne(ctx, domain.TodoGetOneRequest{ID: n.sent[0]})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if todo.RemindedAt == nil {
+		t.Errorf("todo %d not marked reminded", todo.ID)
+	}
+}
+
+func TestRunGivesUp(t *testing.T) {
+	ctx := context.Background()
+	n := &notifier{send: func(reminder domain.Reminder) error { return errors.New("unreachable") }}
+	w := NewReminderWorker(newUsecase(t, "a"), n, time.Minute, time.Millisecond, 10)
+
+	for i := 0; i < domain.RemindAttemptsMax+2; i++ {
+		w.Run(ctx)
+		time.Sleep(2 * time.Millisecond)
+	}
+
+	if len(n.sent) != domain.RemindAttemptsMax || w.Failed.Load() != domain.RemindAttemptsMax {
+		t.Errorf("sent %d times and failed %d, want %d", len(n.sent), w.Failed.Load(), domain.RemindAttemptsMax)
+	}
+}
+
+func TestRunLeaseRunOut(t *testing.T) {
+	ctx := context.Background()
+	u := newUsecase(t, "a", "b")
+	lease := 20 * time.Millisecond
+
+	// The first send outlasts the lease of the batch, another worker takes both todos meanwhile
+	var taken []domain.Reminder
+	n := &notifier{}
+	n.send = func(reminder domain.Reminder) (err error) {
+		if len(n.sent) > 1 {
+			return
+		}
+
+		time.Sleep(2 * lease)
+
+		res, err := u.ClaimReminders(ctx, domain.TodoClaimRemindersRequest{Claim: "other", Lease: time.Hour, Limit: 10})
+		taken = res.Reminders
+		return
+	}
+
+	w := NewReminderWorker(u, n, time.Minute, lease, 10)
+	w.Run(ctx)
+
+	// The second todo was left to the other worker, not sent twice
+	if len(taken) != 2 || len(n.sent) != 1 {
+		t.Errorf("taken %d todos, sent %v", len(taken), n.sent)
+	}
+}